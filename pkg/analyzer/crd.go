@@ -3,36 +3,69 @@
 package analyzer
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"strings"
+	"sync"
 
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"sigs.k8s.io/yaml"
 )
 
+// ErrNotCRD is returned when a manifest is a valid Kubernetes object of another kind than
+// CustomResourceDefinition, e.g. a Deployment next to the CRDs of a directory
+var ErrNotCRD = errors.New("not a CustomResourceDefinition")
+
 // CRDAnalyzer provides functionality to parse and analyze CustomResourceDefinitions
 type CRDAnalyzer struct {
 	scheme *runtime.Scheme
 	codecs serializer.CodecFactory
 }
 
-// NewCRDAnalyzer creates a new CRDAnalyzer instance
+// crdScheme and crdCodecs are shared by all analyzers, as building them for every CRD of a large
+// directory is wasteful. They are only read once built, so analyzers can be used concurrently.
+var (
+	crdSchemeOnce sync.Once
+	crdScheme     *runtime.Scheme
+	crdCodecs     serializer.CodecFactory
+)
+
+// NewCRDAnalyzer creates a new CRDAnalyzer instance. Analyzers share one scheme, built on first use,
+// and can be reused for any number of CRDs.
 func NewCRDAnalyzer() *CRDAnalyzer {
-	scheme := runtime.NewScheme()
-	_ = apiextensionsv1.AddToScheme(scheme) // Error is always nil for well-known schemes
-	codecs := serializer.NewCodecFactory(scheme)
+	crdSchemeOnce.Do(func() {
+		crdScheme = newCRDScheme()
+		crdCodecs = serializer.NewCodecFactory(crdScheme)
+	})
 
 	return &CRDAnalyzer{
-		scheme: scheme,
-		codecs: codecs,
+		scheme: crdScheme,
+		codecs: crdCodecs,
 	}
 }
 
+// newCRDScheme returns a scheme with the versions of the CRD API
+func newCRDScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	// Errors are always nil for well-known schemes. The internal version lets v1beta1 CRDs be
+	// converted to v1.
+	_ = apiextensions.AddToScheme(scheme)
+	_ = apiextensionsv1.AddToScheme(scheme)
+	_ = apiextensionsv1beta1.AddToScheme(scheme)
+	return scheme
+}
+
 // CRDInfo contains extracted information from a CRD
 type CRDInfo struct {
 	// Basic metadata
@@ -42,16 +75,32 @@ type CRDInfo struct {
 	Version  string
 	Versions []string
 
+	// VersionInfos holds the deprecation of each version, in the order of Versions
+	VersionInfos []VersionInfo
+
 	// Resource naming
 	Plural     string
 	Singular   string
 	ShortNames []string
+	Categories []string
 	ListKind   string
 
+	// Scope the toolset is generated for, the declared scope of the CRD unless overridden
+	Scope apiextensionsv1.ResourceScope
+
 	// Schema information
 	Schema        *apiextensionsv1.JSONSchemaProps
 	OpenAPISchema *apiextensionsv1.JSONSchemaProps
 
+	// Columns shown by kubectl get, from additionalPrinterColumns of the storage version
+	PrinterColumns []apiextensionsv1.CustomResourceColumnDefinition
+
+	// Replica paths of the scale subresource of the storage version, nil without one
+	Scale *ScaleInfo
+
+	// How the API server converts objects between versions, None unless the CRD declares otherwise
+	ConversionStrategy apiextensionsv1.ConversionStrategyType
+
 	// Original CRD for reference
 	CRD *apiextensionsv1.CustomResourceDefinition
 
@@ -60,6 +109,25 @@ type CRDInfo struct {
 
 	// Documentation content for embedding as MCP resource
 	DocContent string
+
+	// MIME type of DocContent, inferred from the extension of its source
+	DocMimeType string
+
+	// Warnings about schema references that could not be resolved and version conversion
+	Warnings []string
+}
+
+// VersionInfo describes the deprecation of a version of a CRD
+type VersionInfo struct {
+	Name               string
+	Deprecated         bool
+	DeprecationWarning string // Warning the API server returns for the version, empty for its default
+}
+
+// ScaleInfo holds the JSON paths the scale subresource of a CRD reads and writes the replicas at
+type ScaleInfo struct {
+	SpecReplicasPath   string // e.g. .spec.replicas
+	StatusReplicasPath string // e.g. .status.replicas
 }
 
 // ParseCRDFromFile parses a CRD from a YAML file
@@ -97,28 +165,46 @@ func (a *CRDAnalyzer) ParseCRDFromYAML(yamlData []byte) (*CRDInfo, error) {
 
 	// Decode into CRD object
 	decoder := a.codecs.UniversalDeserializer()
-	obj, _, err := decoder.Decode(jsonData, nil, nil)
+	obj, gvk, err := decoder.Decode(jsonData, nil, nil)
+	if runtime.IsNotRegisteredError(err) && gvk != nil {
+		return nil, fmt.Errorf("%w: got %s", ErrNotCRD, gvk.Kind)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode CRD: %w", err)
 	}
 
-	crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition)
-	if !ok {
-		return nil, fmt.Errorf("object is not a CustomResourceDefinition, got %T", obj)
-	}
-
-	info, err := a.AnalyzeCRD(crd)
+	crd, err := a.toV1(obj)
 	if err != nil {
 		return nil, err
 	}
 
-	// Store original YAML content for embedding as MCP resource
-	// Replace backticks with escaped version to avoid breaking Go raw string literals
-	yamlContent := string(yamlData)
-	yamlContent = strings.ReplaceAll(yamlContent, "`", "` + \"`\" + `")
-	info.YAMLContent = yamlContent
+	return a.AnalyzeCRD(crd)
+}
 
-	return info, nil
+// toV1 returns the decoded CRD in its v1 representation. v1beta1 CRDs are converted, which moves
+// the schema and printer columns they may declare once for all versions, in spec.validation and
+// spec.additionalPrinterColumns, into each version.
+func (a *CRDAnalyzer) toV1(obj runtime.Object) (*apiextensionsv1.CustomResourceDefinition, error) {
+	switch crd := obj.(type) {
+	case *apiextensionsv1.CustomResourceDefinition:
+		return crd, nil
+	case *apiextensionsv1beta1.CustomResourceDefinition:
+		// Defaulting turns the deprecated spec.version into spec.versions
+		a.scheme.Default(crd)
+
+		internal := &apiextensions.CustomResourceDefinition{}
+		if err := a.scheme.Convert(crd, internal, nil); err != nil {
+			return nil, fmt.Errorf("failed to convert v1beta1 CRD: %w", err)
+		}
+		converted := &apiextensionsv1.CustomResourceDefinition{}
+		if err := a.scheme.Convert(internal, converted, nil); err != nil {
+			return nil, fmt.Errorf("failed to convert v1beta1 CRD: %w", err)
+		}
+		converted.SetGroupVersionKind(apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"))
+		return converted, nil
+	default:
+		return nil, fmt.Errorf("%w: got %T", ErrNotCRD, obj)
+	}
 }
 
 // AnalyzeCRD analyzes a CRD and extracts relevant information
@@ -134,8 +220,15 @@ func (a *CRDAnalyzer) AnalyzeCRD(crd *apiextensionsv1.CustomResourceDefinition)
 		Plural:     crd.Spec.Names.Plural,
 		Singular:   crd.Spec.Names.Singular,
 		ShortNames: crd.Spec.Names.ShortNames,
+		Categories: crd.Spec.Names.Categories,
 		ListKind:   crd.Spec.Names.ListKind,
+		Scope:      crd.Spec.Scope,
 		CRD:        crd,
+
+		ConversionStrategy: apiextensionsv1.NoneConverter,
+	}
+	if crd.Spec.Conversion != nil && crd.Spec.Conversion.Strategy != "" {
+		info.ConversionStrategy = crd.Spec.Conversion.Strategy
 	}
 
 	// Extract version information
@@ -145,6 +238,11 @@ func (a *CRDAnalyzer) AnalyzeCRD(crd *apiextensionsv1.CustomResourceDefinition)
 		for i := range crd.Spec.Versions {
 			version := &crd.Spec.Versions[i]
 			info.Versions = append(info.Versions, version.Name)
+			versionInfo := VersionInfo{Name: version.Name, Deprecated: version.Deprecated}
+			if version.DeprecationWarning != nil {
+				versionInfo.DeprecationWarning = *version.DeprecationWarning
+			}
+			info.VersionInfos = append(info.VersionInfos, versionInfo)
 
 			if version.Storage || storageVersion == nil {
 				storageVersion = version
@@ -154,9 +252,27 @@ func (a *CRDAnalyzer) AnalyzeCRD(crd *apiextensionsv1.CustomResourceDefinition)
 
 		// Extract schema from storage version
 		if storageVersion != nil && storageVersion.Schema != nil && storageVersion.Schema.OpenAPIV3Schema != nil {
-			info.Schema = storageVersion.Schema.OpenAPIV3Schema
-			info.OpenAPISchema = storageVersion.Schema.OpenAPIV3Schema
+			// Inline $ref references so type and schema generation see a self-contained schema
+			info.Schema, info.Warnings = resolveSchemaRefs(storageVersion.Schema.OpenAPIV3Schema)
+			info.OpenAPISchema = info.Schema
 		}
+
+		if storageVersion != nil {
+			info.PrinterColumns = storageVersion.AdditionalPrinterColumns
+			if subresources := storageVersion.Subresources; subresources != nil && subresources.Scale != nil {
+				info.Scale = &ScaleInfo{
+					SpecReplicasPath:   subresources.Scale.SpecReplicasPath,
+					StatusReplicasPath: subresources.Scale.StatusReplicasPath,
+				}
+			}
+		}
+	}
+
+	// A webhook may convert between versions whose schemas differ in more than their names
+	if info.ConversionStrategy == apiextensionsv1.WebhookConverter && len(info.Versions) > 1 {
+		info.Warnings = append(info.Warnings, fmt.Sprintf(
+			"%s is converted between versions by a webhook, the generated types reflect only version %s",
+			info.Kind, info.Version))
 	}
 
 	// Set ListKind if not specified
@@ -164,9 +280,53 @@ func (a *CRDAnalyzer) AnalyzeCRD(crd *apiextensionsv1.CustomResourceDefinition)
 		info.ListKind = info.Kind + "List"
 	}
 
+	// Default the singular like the API server does
+	if info.Singular == "" {
+		info.Singular = strings.ToLower(info.Kind)
+	}
+
+	// Store the CRD manifest for embedding as MCP resource
+	yamlContent, err := crdManifestYAML(crd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal CRD: %w", err)
+	}
+	// Replace backticks with escaped version to avoid breaking Go raw string literals
+	info.YAMLContent = strings.ReplaceAll(yamlContent, "`", "` + \"`\" + `")
+
 	return info, nil
 }
 
+// crdManifestYAML renders a CRD as a v1 manifest for --generate-crd-resource, without its status
+// and the metadata the API server manages, so CRDs parsed from files and fetched from a cluster
+// are embedded alike
+func crdManifestYAML(crd *apiextensionsv1.CustomResourceDefinition) (string, error) {
+	manifest := &apiextensionsv1.CustomResourceDefinition{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: apiextensionsv1.SchemeGroupVersion.String(),
+			Kind:       "CustomResourceDefinition",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        crd.Name,
+			Labels:      crd.Labels,
+			Annotations: crd.Annotations,
+		},
+		Spec: crd.Spec,
+	}
+
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(manifest)
+	if err != nil {
+		return "", err
+	}
+	unstructured.RemoveNestedField(obj, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(obj, "status")
+
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
 // ValidateCRD validates that a CRD has the required fields for code generation
 func (a *CRDAnalyzer) ValidateCRD(crd *apiextensionsv1.CustomResourceDefinition) error {
 	if crd == nil {
@@ -223,6 +383,22 @@ func (info *CRDInfo) GetListTypeName() string {
 	return info.ListKind
 }
 
+// IsNamespaced returns true unless the toolset is generated for a cluster-scoped resource
+func (info *CRDInfo) IsNamespaced() bool {
+	return info.Scope != apiextensionsv1.ClusterScoped
+}
+
+// GetPrinterColumns returns the printer columns shown by default, i.e. those with priority 0
+func (info *CRDInfo) GetPrinterColumns() []apiextensionsv1.CustomResourceColumnDefinition {
+	var columns []apiextensionsv1.CustomResourceColumnDefinition
+	for _, column := range info.PrinterColumns {
+		if column.Priority == 0 {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
 // GetAPIVersion returns the full API version string (group/version)
 func (info *CRDInfo) GetAPIVersion() string {
 	if info.Group == "" {
@@ -231,16 +407,64 @@ func (info *CRDInfo) GetAPIVersion() string {
 	return fmt.Sprintf("%s/%s", info.Group, info.Version)
 }
 
+// IsDeprecated returns true if the version the toolset is generated for is deprecated
+func (info *CRDInfo) IsDeprecated() bool {
+	for _, version := range info.VersionInfos {
+		if version.Name == info.Version {
+			return version.Deprecated
+		}
+	}
+	return false
+}
+
+// GetDeprecationWarning returns the warning of the deprecated version the toolset is generated
+// for on a single line, or the default warning of the API server if the CRD declares none. It is
+// empty if the version is not deprecated.
+func (info *CRDInfo) GetDeprecationWarning() string {
+	for _, version := range info.VersionInfos {
+		if version.Name != info.Version || !version.Deprecated {
+			continue
+		}
+		if version.DeprecationWarning != "" {
+			return strings.Join(strings.Fields(version.DeprecationWarning), " ")
+		}
+		return fmt.Sprintf("%s %s is deprecated", info.GetAPIVersion(), info.Kind)
+	}
+	return ""
+}
+
+// HasScaleSubresource returns true if the CRD serves the scale subresource
+func (info *CRDInfo) HasScaleSubresource() bool {
+	return info.Scale != nil
+}
+
 // HasShortNames returns true if the CRD defines short names
 func (info *CRDInfo) HasShortNames() bool {
 	return len(info.ShortNames) > 0
 }
 
+// HasCategories returns true if the CRD lists the resource in categories
+func (info *CRDInfo) HasCategories() bool {
+	return len(info.Categories) > 0
+}
+
+// GetDocMimeType returns the MIME type of the documentation resource, text/markdown unless
+// DocMimeType is set
+func (info *CRDInfo) GetDocMimeType() string {
+	if info.DocMimeType == "" {
+		return defaultDocMimeType
+	}
+	return info.DocMimeType
+}
+
 // GetGroupVersionKind returns the full GroupVersionKind string
 func (info *CRDInfo) GetGroupVersionKind() string {
 	return fmt.Sprintf("%s/%s, Kind=%s", info.Group, info.Version, info.Kind)
 }
 
+// defaultDocMimeType is the MIME type of documentation whose source has no known extension
+const defaultDocMimeType = "text/markdown"
+
 // LoadDocumentationContent loads documentation content from a file path or URL.
 // It handles both local files and HTTP(S) URLs.
 // The content is escaped for embedding in Go string literals.
@@ -280,3 +504,19 @@ func LoadDocumentationContent(source string) (string, error) {
 
 	return docContent, nil
 }
+
+// DocumentationMimeType returns the MIME type of the documentation at source, a file path or URL,
+// from its extension: text/html for .html and .htm, text/plain for .txt and text/markdown otherwise
+func DocumentationMimeType(source string) string {
+	if u, err := url.Parse(source); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		source = u.Path
+	}
+	switch strings.ToLower(path.Ext(source)) {
+	case ".html", ".htm":
+		return "text/html"
+	case ".txt":
+		return "text/plain"
+	default:
+		return defaultDocMimeType
+	}
+}
@@ -1,10 +1,14 @@
 package analyzer
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
 )
 
 func TestParseCRDFromFile(t *testing.T) {
@@ -88,6 +92,38 @@ func TestParseCRDFromFile(t *testing.T) {
 	}
 }
 
+func TestParseCRDDeprecatedVersions(t *testing.T) {
+	info, err := NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/deprecated-crd.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, []VersionInfo{
+		{Name: "v1alpha1", Deprecated: true},
+		{Name: "v1beta1", Deprecated: true, DeprecationWarning: "example.com/v1beta1 Gadget is deprecated; use example.com/v1 Gadget"},
+	}, info.VersionInfos)
+	assert.True(t, info.IsDeprecated())
+	assert.Equal(t, "example.com/v1beta1 Gadget is deprecated; use example.com/v1 Gadget", info.GetDeprecationWarning())
+
+	// Without a warning of its own, a deprecated version gets the one of the API server
+	info.Version = "v1alpha1"
+	assert.Equal(t, "example.com/v1alpha1 Gadget is deprecated", info.GetDeprecationWarning())
+
+	info, err = NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/simple-crd.yaml")
+	require.NoError(t, err)
+	assert.False(t, info.IsDeprecated())
+	assert.Empty(t, info.GetDeprecationWarning())
+}
+
+func TestParseCRDScaleSubresource(t *testing.T) {
+	info, err := NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/scalable-crd.yaml")
+	require.NoError(t, err)
+	require.True(t, info.HasScaleSubresource())
+	assert.Equal(t, &ScaleInfo{SpecReplicasPath: ".spec.replicas", StatusReplicasPath: ".status.replicas"}, info.Scale)
+
+	info, err = NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/simple-crd.yaml")
+	require.NoError(t, err)
+	assert.False(t, info.HasScaleSubresource())
+}
+
 func TestCRDInfoMethods(t *testing.T) {
 	analyzer := NewCRDAnalyzer()
 
@@ -139,3 +175,427 @@ func TestParseInvalidFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestParseNonCRDManifest(t *testing.T) {
+	analyzer := NewCRDAnalyzer()
+
+	_, err := analyzer.ParseCRDFromFile("../../test/fixtures/mixed/deployment.yaml")
+	assert.ErrorIs(t, err, ErrNotCRD)
+	assert.ErrorContains(t, err, "got Deployment")
+
+	_, err = analyzer.ParseCRDFromFile("../../pkg/analyzer/crd.go")
+	assert.NotErrorIs(t, err, ErrNotCRD, "files that are not manifests are real errors")
+}
+
+func TestParseCRDConversionStrategy(t *testing.T) {
+	info, err := NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/webhook-conversion-crd.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, apiextensionsv1.WebhookConverter, info.ConversionStrategy)
+	assert.Equal(t, []string{"Sprocket is converted between versions by a webhook, the generated types reflect only version v1"}, info.Warnings)
+
+	toolset, err := NewToolsetInfo(info, nil)
+	require.NoError(t, err)
+	assert.Equal(t, info.Warnings, toolset.Warnings)
+
+	// Without a conversion section the API server only rewrites apiVersion
+	info, err = NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/multi-version-crd.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, apiextensionsv1.NoneConverter, info.ConversionStrategy)
+	assert.Empty(t, info.Warnings)
+}
+
+func TestParseCRDManifestYAML(t *testing.T) {
+	// v1beta1 CRDs are embedded as the v1 manifest they are generated from
+	info, err := NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/v1beta1-crd.yaml")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(info.YAMLContent, "apiVersion: apiextensions.k8s.io/v1\n"), info.YAMLContent)
+	assert.Contains(t, info.YAMLContent, "kind: CustomResourceDefinition\n")
+	assert.Contains(t, info.YAMLContent, "  name: gizmos.legacy.example.com\n")
+	assert.NotContains(t, info.YAMLContent, "\nstatus:")
+	assert.NotContains(t, info.YAMLContent, "creationTimestamp")
+
+	// Backticks of descriptions must not end the raw string literal the manifest is embedded in
+	info, err = NewCRDAnalyzer().ParseCRDFromYAML([]byte(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: notes.example.com
+spec:
+  group: example.com
+  names:
+    kind: Note
+    plural: notes
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              text:
+                type: string
+                description: Text of the note, e.g. ` + "`hello`" + `
+`))
+	require.NoError(t, err)
+	assert.Contains(t, info.YAMLContent, "e.g. ` + \"`\" + `hello` + \"`\" + `\n")
+}
+
+func TestDocumentationMimeType(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{"docs/widgets.md", "text/markdown"},
+		{"docs/widgets.markdown", "text/markdown"},
+		{"docs/WIDGETS.TXT", "text/plain"},
+		{"docs/widgets.html", "text/html"},
+		{"docs/widgets.htm", "text/html"},
+		{"https://example.com/docs/widgets.html?ref=main#usage", "text/html"},
+		{"https://example.com/docs/README", "text/markdown"},
+		{"docs/widgets", "text/markdown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DocumentationMimeType(tt.source))
+		})
+	}
+
+	assert.Equal(t, "text/markdown", (&CRDInfo{}).GetDocMimeType())
+	assert.Equal(t, "text/plain", (&CRDInfo{DocMimeType: "text/plain"}).GetDocMimeType())
+}
+
+func TestToolsetInfoWarnings(t *testing.T) {
+	crdInfo, err := NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/composition-crd.yaml")
+	require.NoError(t, err)
+
+	toolset, err := NewToolsetInfo(crdInfo, nil)
+	require.NoError(t, err)
+
+	require.Len(t, toolset.Warnings, 2)
+	assert.Contains(t, toolset.Warnings[0], "RouteSpecBackend: oneOf")
+	assert.Contains(t, toolset.Warnings[1], "RouteSpecTls: anyOf")
+}
+
+func TestToolsetInfoScopeOverride(t *testing.T) {
+	crdInfo, err := NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/simple-crd.yaml")
+	require.NoError(t, err)
+	require.True(t, crdInfo.IsNamespaced())
+
+	config := DefaultGenerationConfig()
+	config.Scope = apiextensionsv1.ClusterScoped
+	toolset, err := NewToolsetInfo(crdInfo, config)
+	require.NoError(t, err)
+
+	assert.False(t, toolset.CRD.IsNamespaced())
+	assert.True(t, crdInfo.IsNamespaced(), "the parsed CRD info must not be changed")
+}
+
+func TestToolsetInfoGroupVersionResource(t *testing.T) {
+	crdInfo, err := NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/simple-crd.yaml")
+	require.NoError(t, err)
+
+	toolset, err := NewToolsetInfo(crdInfo, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com/v1/widgets", toolset.GetGroupVersionResource())
+
+	gvr := toolset.GroupVersionResource()
+	assert.Equal(t, crdInfo.CRD.Spec.Group, gvr.Group)
+	assert.Equal(t, crdInfo.CRD.Spec.Versions[0].Name, gvr.Version)
+	assert.Equal(t, crdInfo.CRD.Spec.Names.Plural, gvr.Resource)
+	assert.Equal(t, schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}, gvr)
+
+	gvk := toolset.GetGroupVersionKind()
+	assert.Equal(t, schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}, gvk)
+	assert.Equal(t, crdInfo.GetGroupVersionKind(), gvk.String())
+}
+
+func TestToolsetInfoNameOverrides(t *testing.T) {
+	crdInfo, err := NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/simple-crd.yaml")
+	require.NoError(t, err)
+
+	config := DefaultGenerationConfig()
+	config.Plural = "gizmos"
+	config.Singular = "gizmo"
+	toolset, err := NewToolsetInfo(crdInfo, config)
+	require.NoError(t, err)
+
+	assert.Equal(t, "gizmos", toolset.CRD.Plural)
+	assert.Equal(t, "gizmo", toolset.CRD.Singular)
+	assert.Equal(t, "gizmos", toolset.PackageName)
+	assert.Equal(t, "gizmos", toolset.GetToolsetName())
+	assert.Equal(t, "widgets", toolset.GetResource(), "the served resource keeps the plural of the CRD")
+	assert.Equal(t, "widgets", crdInfo.Plural, "the parsed CRD info must not be changed")
+}
+
+func TestToolsetInfoUnstructured(t *testing.T) {
+	crdInfo, err := NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/schemaless-crd.yaml")
+	require.NoError(t, err)
+
+	_, err = NewToolsetInfo(crdInfo, DefaultGenerationConfig())
+	assert.ErrorContains(t, err, "use --allow-unstructured")
+
+	config := DefaultGenerationConfig()
+	config.AllowUnstructured = true
+	toolset, err := NewToolsetInfo(crdInfo, config)
+	require.NoError(t, err)
+	assert.True(t, toolset.Unstructured)
+	assert.Nil(t, toolset.SpecType)
+	assert.Nil(t, toolset.StatusType)
+	assert.Equal(t, []string{"Blob has no structural schema, so its objects are generated as unstructured.Unstructured"}, toolset.Warnings)
+
+	// Only apiVersion, kind and metadata leave nothing to generate types from either
+	crdInfo.Schema = &apiextensionsv1.JSONSchemaProps{
+		Type:       "object",
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{"apiVersion": {Type: "string"}, "metadata": {Type: "object"}},
+	}
+	toolset, err = NewToolsetInfo(crdInfo, config)
+	require.NoError(t, err)
+	assert.True(t, toolset.Unstructured)
+
+	crdInfo, err = NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/simple-crd.yaml")
+	require.NoError(t, err)
+	toolset, err = NewToolsetInfo(crdInfo, config)
+	require.NoError(t, err)
+	assert.False(t, toolset.Unstructured)
+}
+
+func TestToolsetInfoGenericSpec(t *testing.T) {
+	crdInfo, err := NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/empty-spec-crd.yaml")
+	require.NoError(t, err)
+
+	toolset, err := NewToolsetInfo(crdInfo, nil)
+	require.NoError(t, err)
+	assert.False(t, toolset.Unstructured)
+	assert.True(t, toolset.SpecType.IsGenericObject())
+	assert.False(t, toolset.StatusType.IsGenericObject())
+	assert.Equal(t, []string{"the spec of Marker declares no properties, so it is generated as map[string]interface{}"}, toolset.Warnings)
+
+	crdInfo, err = NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/simple-crd.yaml")
+	require.NoError(t, err)
+	toolset, err = NewToolsetInfo(crdInfo, nil)
+	require.NoError(t, err)
+	assert.False(t, toolset.SpecType.IsGenericObject())
+}
+
+func TestToolsetInfoValidate(t *testing.T) {
+	crdInfo := &CRDInfo{
+		Kind:     "Gadget",
+		ListKind: "GadgetList",
+		Plural:   "gadgets",
+		Schema: &apiextensionsv1.JSONSchemaProps{
+			Type: "object",
+			Properties: map[string]apiextensionsv1.JSONSchemaProps{
+				"spec": {
+					Type: "object",
+					Properties: map[string]apiextensionsv1.JSONSchemaProps{
+						"size":  {Type: "integer"},
+						"@type": {Type: "string"},
+					},
+				},
+			},
+		},
+	}
+
+	toolset, err := NewToolsetInfo(crdInfo, &GenerationConfig{PackageName: "gadgets"})
+	require.NoError(t, err)
+
+	problems := toolset.Validate()
+	require.Len(t, problems, 1)
+	assert.Contains(t, problems[0], `field "@type" of GadgetSpec`)
+
+	toolset.PackageName = "type"
+	assert.Contains(t, toolset.Validate(), `package name "type" is not a valid Go identifier`)
+}
+
+func TestToolsetDescription(t *testing.T) {
+	crdInfo := &CRDInfo{
+		Kind:     "Gadget",
+		ListKind: "GadgetList",
+		Plural:   "gadgets",
+		Schema: &apiextensionsv1.JSONSchemaProps{
+			Type:       "object",
+			Properties: map[string]apiextensionsv1.JSONSchemaProps{"spec": {Type: "object"}},
+		},
+	}
+	toolset, err := NewToolsetInfo(crdInfo, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Tools for managing Gadget custom resources", toolset.GetToolsetDescription())
+	assert.Empty(t, toolset.GetSchemaDescription())
+
+	crdInfo.Schema.Description = "Gadget is a\n  thing with parts."
+	assert.Equal(t, "Gadget is a thing with parts.", toolset.GetToolsetDescription())
+
+	// Long descriptions are kept whole; the generator truncates them to --description-max-length
+	crdInfo.Schema.Description = strings.Repeat("word,\n", 100)
+	assert.Equal(t, strings.TrimSpace(strings.Repeat("word, ", 100)), toolset.GetSchemaDescription())
+}
+
+func TestToolsetInfoNestedTypes(t *testing.T) {
+	crdInfo, err := NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/nested-crd.yaml")
+	require.NoError(t, err)
+
+	toolset, err := NewToolsetInfo(crdInfo, nil)
+	require.NoError(t, err)
+
+	var names []string
+	for _, nested := range toolset.NestedTypes {
+		names = append(names, nested.Name)
+	}
+	assert.Equal(t, []string{
+		"PipelineSpecGroupsValueItem",
+		"PipelineSpecMatrixItemItem",
+		"PipelineSpecTemplate",
+		"PipelineSpecTemplateMetadata",
+		"PipelineSpecTemplateMetadataOwner",
+		"PipelineSpecTemplateStageItem",
+		"PipelineSpecTemplateStageItemRetry",
+		"PipelineSpecTemplateStageItemRetryBackoff",
+	}, names)
+	assert.Empty(t, toolset.Validate())
+}
+
+func TestToolsetInfoNestedTypeConflicts(t *testing.T) {
+	crdInfo := &CRDInfo{
+		Kind:     "Gadget",
+		ListKind: "GadgetList",
+		Plural:   "gadgets",
+		Schema: &apiextensionsv1.JSONSchemaProps{
+			Type: "object",
+			Properties: map[string]apiextensionsv1.JSONSchemaProps{
+				"spec": {
+					Type: "object",
+					Properties: map[string]apiextensionsv1.JSONSchemaProps{
+						// Both map to the type GadgetSpecPartItem
+						"parts": {Type: "array", Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{
+							Type:       "object",
+							Properties: map[string]apiextensionsv1.JSONSchemaProps{"size": {Type: "integer"}},
+						}}},
+						"part_item": {Type: "object", Properties: map[string]apiextensionsv1.JSONSchemaProps{"color": {Type: "string"}}},
+					},
+				},
+			},
+		},
+	}
+
+	toolset, err := NewToolsetInfo(crdInfo, &GenerationConfig{PackageName: "gadgets"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nested type GadgetSpecPartItem is generated for differently shaped schemas"}, toolset.Validate())
+}
+
+func TestParsePrinterColumns(t *testing.T) {
+	crdInfo, err := NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/complex-crd.yaml")
+	require.NoError(t, err)
+
+	require.Len(t, crdInfo.PrinterColumns, 4)
+	assert.Equal(t, ".spec.replicas", crdInfo.PrinterColumns[0].JSONPath)
+
+	var names []string
+	for _, column := range crdInfo.GetPrinterColumns() {
+		names = append(names, column.Name)
+	}
+	assert.Equal(t, []string{"Replicas", "Ready", "Age"}, names, "priority columns should be left out")
+	assert.True(t, crdInfo.IsNamespaced())
+
+	clusterScoped, err := NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/cluster-scoped-crd.yaml")
+	require.NoError(t, err)
+	assert.Empty(t, clusterScoped.GetPrinterColumns())
+	assert.False(t, clusterScoped.IsNamespaced())
+}
+
+func TestParseCRDCategories(t *testing.T) {
+	crdInfo, err := NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/categories-crd.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"all", "security"}, crdInfo.Categories)
+	assert.True(t, crdInfo.HasCategories())
+
+	toolset, err := NewToolsetInfo(crdInfo, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"all", "security"}, toolset.GetCategories())
+
+	simple, err := NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/simple-crd.yaml")
+	require.NoError(t, err)
+	assert.Empty(t, simple.Categories)
+	assert.False(t, simple.HasCategories())
+}
+
+func TestParseV1beta1CRD(t *testing.T) {
+	crdInfo, err := NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/v1beta1-crd.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Gizmo", crdInfo.Kind)
+	assert.Equal(t, "legacy.example.com", crdInfo.Group)
+	assert.Equal(t, "v1alpha1", crdInfo.Version)
+	assert.Equal(t, []string{"v1alpha1"}, crdInfo.Versions)
+	assert.Equal(t, "GizmoList", crdInfo.ListKind)
+	assert.True(t, crdInfo.IsNamespaced())
+	assert.Equal(t, "apiextensions.k8s.io/v1", crdInfo.CRD.APIVersion)
+
+	// The schema of spec.validation is used for the version
+	require.NotNil(t, crdInfo.Schema)
+	spec := crdInfo.Schema.Properties["spec"]
+	assert.Equal(t, []string{"color"}, spec.Required)
+	assert.Len(t, spec.Properties["color"].Enum, 3)
+	assert.Equal(t, "integer", spec.Properties["size"].Type)
+	assert.Equal(t, "boolean", crdInfo.Schema.Properties["status"].Properties["ready"].Type)
+
+	columns := crdInfo.GetPrinterColumns()
+	require.Len(t, columns, 1)
+	assert.Equal(t, ".spec.color", columns[0].JSONPath)
+
+	toolset, err := NewToolsetInfo(crdInfo, nil)
+	require.NoError(t, err)
+	assert.Empty(t, toolset.Validate())
+}
+
+func TestParseCRDWithRefs(t *testing.T) {
+	crdInfo, err := NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/ref-crd.yaml")
+	require.NoError(t, err)
+
+	spec := crdInfo.Schema.Properties["spec"]
+	assert.Equal(t, "object", spec.Properties["primary"].Type)
+	assert.Nil(t, crdInfo.Schema.Definitions)
+
+	toolset, err := NewToolsetInfo(crdInfo, nil)
+	require.NoError(t, err)
+	require.Len(t, toolset.Warnings, 1)
+	assert.Contains(t, toolset.Warnings[0], `$ref "#/definitions/Node" is cyclic`)
+}
+
+// BenchmarkParseCRDFromFile compares parsing a CRD with a freshly built scheme, as every analyzer
+// used to, with a new analyzer per file sharing the scheme and with one reused analyzer
+func BenchmarkParseCRDFromFile(b *testing.B) {
+	const fixture = "../../test/fixtures/complex-crd.yaml"
+
+	b.Run("fresh scheme per file", func(b *testing.B) {
+		for b.Loop() {
+			scheme := newCRDScheme()
+			crdAnalyzer := &CRDAnalyzer{scheme: scheme, codecs: serializer.NewCodecFactory(scheme)}
+			if _, err := crdAnalyzer.ParseCRDFromFile(fixture); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("new analyzer per file", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := NewCRDAnalyzer().ParseCRDFromFile(fixture); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("reused analyzer", func(b *testing.B) {
+		crdAnalyzer := NewCRDAnalyzer()
+		for b.Loop() {
+			if _, err := crdAnalyzer.ParseCRDFromFile(fixture); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// limitSchemaDepth returns a copy of schema in which the fields of spec, status and the other
+// top-level properties are nested at most maxDepth levels deep. Objects at the limit lose their
+// properties and become generic objects, map[string]interface{} in the Go types, which keeps the
+// number of generated types bounded for very deep schemas. Array items and map values count as
+// the level of the field holding them. A warning is returned for every collapsed field.
+func limitSchemaDepth(schema *apiextensionsv1.JSONSchemaProps, maxDepth int) (*apiextensionsv1.JSONSchemaProps, []string) {
+	if schema == nil || maxDepth <= 0 {
+		return schema, nil
+	}
+
+	limited := *schema
+	var warnings []string
+	if len(schema.Properties) > 0 {
+		limited.Properties = make(map[string]apiextensionsv1.JSONSchemaProps, len(schema.Properties))
+		for name, prop := range schema.Properties {
+			limited.Properties[name] = limitDepth(prop, name, maxDepth, maxDepth, &warnings)
+		}
+	}
+	sort.Strings(warnings)
+	return &limited, warnings
+}
+
+// limitDepth returns a copy of schema, the field at path, whose fields are nested at most remaining
+// levels deep. Maps and slices are copied before they are changed, so schema is left untouched.
+func limitDepth(schema apiextensionsv1.JSONSchemaProps, path string, remaining, maxDepth int, warnings *[]string) apiextensionsv1.JSONSchemaProps {
+	// Subtrees that preserve unknown fields are kept as raw JSON anyway
+	if preservesUnknownFields(&schema) {
+		return schema
+	}
+
+	if remaining == 0 && (schema.Type == "object" || schema.Type == "") && hasFields(&schema) {
+		*warnings = append(*warnings, fmt.Sprintf(
+			"fields of %s are nested deeper than the maximum depth of %d, so it is generated as map[string]interface{}",
+			path, maxDepth))
+		schema.Type = "object"
+		schema.Properties = nil
+		schema.Required = nil
+		schema.AllOf, schema.AnyOf, schema.OneOf = nil, nil, nil
+		schema.AdditionalProperties = nil
+		return schema
+	}
+
+	if len(schema.Properties) > 0 {
+		properties := make(map[string]apiextensionsv1.JSONSchemaProps, len(schema.Properties))
+		for name, prop := range schema.Properties {
+			properties[name] = limitDepth(prop, path+"."+name, remaining-1, maxDepth, warnings)
+		}
+		schema.Properties = properties
+	}
+
+	// Composition parts describe the same object, so their fields are at the same level
+	schema.AllOf = limitSubschemaDepth(schema.AllOf, path, remaining, maxDepth, warnings)
+	schema.AnyOf = limitSubschemaDepth(schema.AnyOf, path, remaining, maxDepth, warnings)
+	schema.OneOf = limitSubschemaDepth(schema.OneOf, path, remaining, maxDepth, warnings)
+
+	if schema.Items != nil && schema.Items.Schema != nil {
+		items := *schema.Items
+		itemSchema := limitDepth(*items.Schema, path, remaining, maxDepth, warnings)
+		items.Schema = &itemSchema
+		schema.Items = &items
+	}
+
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		additional := *schema.AdditionalProperties
+		valueSchema := limitDepth(*additional.Schema, path, remaining, maxDepth, warnings)
+		additional.Schema = &valueSchema
+		schema.AdditionalProperties = &additional
+	}
+
+	return schema
+}
+
+// limitSubschemaDepth applies limitDepth to a copy of each of the subschemas
+func limitSubschemaDepth(schemas []apiextensionsv1.JSONSchemaProps, path string, remaining, maxDepth int, warnings *[]string) []apiextensionsv1.JSONSchemaProps {
+	if len(schemas) == 0 {
+		return schemas
+	}
+	limited := make([]apiextensionsv1.JSONSchemaProps, len(schemas))
+	for i, subschema := range schemas {
+		limited[i] = limitDepth(subschema, path, remaining, maxDepth, warnings)
+	}
+	return limited
+}
+
+// hasFields reports whether schema declares properties, directly or in its composition parts
+func hasFields(schema *apiextensionsv1.JSONSchemaProps) bool {
+	if len(schema.Properties) > 0 {
+		return true
+	}
+	for _, parts := range [][]apiextensionsv1.JSONSchemaProps{schema.AllOf, schema.AnyOf, schema.OneOf} {
+		for i := range parts {
+			if hasFields(&parts[i]) {
+				return true
+			}
+		}
+	}
+	return false
+}
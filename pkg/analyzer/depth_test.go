@@ -0,0 +1,112 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestToolsetInfoMaxDepth(t *testing.T) {
+	crdInfo, err := NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/nested-crd.yaml")
+	require.NoError(t, err)
+
+	config := DefaultGenerationConfig()
+	config.MaxDepth = 3
+	toolset, err := NewToolsetInfo(crdInfo, config)
+	require.NoError(t, err)
+
+	var names []string
+	for _, nested := range toolset.NestedTypes {
+		names = append(names, nested.Name)
+	}
+	assert.Equal(t, []string{
+		"PipelineSpecGroupsValueItem",
+		"PipelineSpecMatrixItemItem",
+		"PipelineSpecTemplate",
+		"PipelineSpecTemplateMetadata",
+		"PipelineSpecTemplateStageItem",
+	}, names, "no types should be generated for objects at the third level")
+
+	stage := toolset.SpecType.Properties["template"].Properties["stages"].Items
+	assert.Equal(t, "map[string]interface{}", stage.Properties["retry"].GoType)
+	assert.Nil(t, stage.Properties["retry"].Properties)
+	assert.Equal(t, "string", stage.Properties["name"].GoType, "fields at the maximum depth keep their types")
+	assert.Equal(t, "map[string]interface{}", toolset.SpecType.Properties["template"].Properties["metadata"].Properties["owner"].GoType)
+
+	// The JSON schema accepts any retry object, just like the type
+	retry := toolset.Schema.Properties["spec"].Properties["template"].Properties["stages"].Items.Schema.Properties["retry"]
+	assert.Equal(t, "object", retry.Type)
+	assert.Empty(t, retry.Properties)
+	assert.NotEmpty(t, crdInfo.Schema.Properties["spec"].Properties["template"].Properties["stages"].Items.Schema.Properties["retry"].Properties,
+		"the schema of the CRD must be left untouched")
+
+	assert.Equal(t, []string{
+		"fields of spec.template.metadata.owner are nested deeper than the maximum depth of 3, so it is generated as map[string]interface{}",
+		"fields of spec.template.stages.retry are nested deeper than the maximum depth of 3, so it is generated as map[string]interface{}",
+	}, toolset.Warnings)
+	assert.Empty(t, toolset.Validate())
+}
+
+func TestToolsetInfoMaxDepthKeepingExcludedFields(t *testing.T) {
+	crdInfo, err := NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/nested-crd.yaml")
+	require.NoError(t, err)
+
+	config := DefaultGenerationConfig()
+	config.MaxDepth = 3
+	config.ExcludedFields = []string{"spec.template.stages"}
+	config.KeepExcludedFieldsInTypes = true
+	toolset, err := NewToolsetInfo(crdInfo, config)
+	require.NoError(t, err)
+
+	stage := toolset.SpecType.Properties["template"].Properties["stages"].Items
+	assert.Equal(t, "map[string]interface{}", stage.Properties["retry"].GoType)
+	assert.NotContains(t, toolset.Schema.Properties["spec"].Properties["template"].Properties, "stages")
+
+	// The fields collapsed in the JSON schemas and in the types are each warned about once
+	assert.Equal(t, []string{
+		"fields of spec.template.metadata.owner are nested deeper than the maximum depth of 3, so it is generated as map[string]interface{}",
+		"fields of spec.template.stages.retry are nested deeper than the maximum depth of 3, so it is generated as map[string]interface{}",
+	}, toolset.Warnings)
+}
+
+func TestLimitSchemaDepth(t *testing.T) {
+	object := func(properties map[string]apiextensionsv1.JSONSchemaProps) apiextensionsv1.JSONSchemaProps {
+		return apiextensionsv1.JSONSchemaProps{Type: "object", Properties: properties}
+	}
+	leaf := apiextensionsv1.JSONSchemaProps{Type: "string"}
+	schema := object(map[string]apiextensionsv1.JSONSchemaProps{
+		"spec": object(map[string]apiextensionsv1.JSONSchemaProps{
+			"name": leaf,
+			"nested": object(map[string]apiextensionsv1.JSONSchemaProps{
+				"value": leaf,
+			}),
+			"items": {Type: "array", Items: &apiextensionsv1.JSONSchemaPropsOrArray{
+				Schema: &apiextensionsv1.JSONSchemaProps{AllOf: []apiextensionsv1.JSONSchemaProps{
+					object(map[string]apiextensionsv1.JSONSchemaProps{"value": leaf}),
+				}},
+			}},
+			"values": {Type: "object", AdditionalProperties: &apiextensionsv1.JSONSchemaPropsOrBool{
+				Schema: &apiextensionsv1.JSONSchemaProps{Type: "integer"},
+			}},
+		}),
+	})
+
+	limited, warnings := limitSchemaDepth(&schema, 1)
+	spec := limited.Properties["spec"]
+	assert.Equal(t, leaf, spec.Properties["name"])
+	assert.Equal(t, apiextensionsv1.JSONSchemaProps{Type: "object"}, spec.Properties["nested"])
+	assert.Equal(t, &apiextensionsv1.JSONSchemaProps{Type: "object"}, spec.Properties["items"].Items.Schema,
+		"array items are at the level of their field and composed properties count as fields")
+	assert.Equal(t, "integer", spec.Properties["values"].AdditionalProperties.Schema.Type,
+		"maps of scalars have no fields to collapse")
+	assert.Equal(t, []string{
+		"fields of spec.items are nested deeper than the maximum depth of 1, so it is generated as map[string]interface{}",
+		"fields of spec.nested are nested deeper than the maximum depth of 1, so it is generated as map[string]interface{}",
+	}, warnings)
+
+	unlimited, warnings := limitSchemaDepth(&schema, 0)
+	assert.Same(t, &schema, unlimited)
+	assert.Empty(t, warnings)
+}
@@ -0,0 +1,146 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Patch types of custom operations, named like the patch types of kubectl patch
+const (
+	PatchTypeMerge = "merge" // JSON merge patch (RFC 7386), the default
+	PatchTypeJSON  = "json"  // JSON patch (RFC 6902)
+)
+
+// CustomOperation is an operation beyond CRUD, e.g. pause or restart, that is generated as a tool
+// applying a fixed patch to the named resource
+type CustomOperation struct {
+	// Name of the operation, lowercase words separated by hyphens, e.g. rotate-credentials
+	Name string `json:"name"`
+
+	// Description of the tool, telling the LLM what the operation does
+	Description string `json:"description"`
+
+	// PatchType is merge or json, merge if empty
+	PatchType string `json:"patchType,omitempty"`
+
+	// Patch is the patch document, an object for merge patches and a list of operations for
+	// JSON patches
+	Patch json.RawMessage `json:"patch"`
+}
+
+// operationsFile is the format of the file custom operations are declared in
+type operationsFile struct {
+	Operations []CustomOperation `json:"operations"`
+}
+
+// operationNamePattern matches valid custom operation names
+var operationNamePattern = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`)
+
+// builtinOperations are the operations custom operations must not be named after
+var builtinOperations = []string{"create", "get", "list", "update", "delete", "scale", "find"}
+
+// LoadCustomOperations reads and validates the custom operations declared in a YAML file like
+//
+//	operations:
+//	- name: pause
+//	  description: Pause reconciliation of the resource
+//	  patch:
+//	    spec:
+//	      paused: true
+//
+// The patches are returned as compact JSON.
+func LoadCustomOperations(filename string) ([]CustomOperation, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read operations file %s: %w", filename, err)
+	}
+
+	var file operationsFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid operations file %s: %w", filename, err)
+	}
+	if len(file.Operations) == 0 {
+		return nil, fmt.Errorf("invalid operations file %s: no operations declared", filename)
+	}
+
+	seen := make(map[string]bool)
+	for i := range file.Operations {
+		operation := &file.Operations[i]
+		if err := operation.validate(); err != nil {
+			return nil, fmt.Errorf("invalid operations file %s: operation %d: %w", filename, i+1, err)
+		}
+		if seen[operation.Name] {
+			return nil, fmt.Errorf("invalid operations file %s: operation %q is declared twice", filename, operation.Name)
+		}
+		seen[operation.Name] = true
+	}
+	return file.Operations, nil
+}
+
+// validate checks the fields of a custom operation, defaults its patch type and compacts its patch
+func (o *CustomOperation) validate() error {
+	if !operationNamePattern.MatchString(o.Name) {
+		return fmt.Errorf("name %q must be lowercase words separated by hyphens, e.g. rotate-credentials", o.Name)
+	}
+	if slices.Contains(builtinOperations, o.Name) {
+		return fmt.Errorf("name %q is taken by a generated operation", o.Name)
+	}
+	if strings.TrimSpace(o.Description) == "" {
+		return fmt.Errorf("%s: description is required", o.Name)
+	}
+
+	if o.PatchType == "" {
+		o.PatchType = PatchTypeMerge
+	}
+	var patch any
+	if len(o.Patch) > 0 {
+		if err := json.Unmarshal(o.Patch, &patch); err != nil {
+			return fmt.Errorf("%s: invalid patch: %w", o.Name, err)
+		}
+	}
+	switch o.PatchType {
+	case PatchTypeMerge:
+		if object, ok := patch.(map[string]any); !ok || len(object) == 0 {
+			return fmt.Errorf("%s: a merge patch must be a non-empty object", o.Name)
+		}
+	case PatchTypeJSON:
+		if list, ok := patch.([]any); !ok || len(list) == 0 {
+			return fmt.Errorf("%s: a json patch must be a non-empty list of operations", o.Name)
+		}
+	default:
+		return fmt.Errorf("%s: invalid patchType %q, valid values are: %s, %s", o.Name, o.PatchType, PatchTypeMerge, PatchTypeJSON)
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, o.Patch); err != nil {
+		return fmt.Errorf("%s: invalid patch: %w", o.Name, err)
+	}
+	o.Patch = compact.Bytes()
+	return nil
+}
+
+// GoName returns the name of the operation in PascalCase, e.g. RotateCredentials for
+// rotate-credentials, for the identifiers generated for it
+func (o CustomOperation) GoName() string {
+	var name strings.Builder
+	for _, word := range strings.Split(o.Name, "-") {
+		name.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return name.String()
+}
+
+// GetPatchType returns the name of the Kubernetes patch type constant in k8s.io/apimachinery/pkg/types
+// the patch is applied with
+func (o CustomOperation) GetPatchType() string {
+	if o.PatchType == PatchTypeJSON {
+		return "JSONPatchType"
+	}
+	return "MergePatchType"
+}
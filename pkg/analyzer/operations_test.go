@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCustomOperations(t *testing.T) {
+	operations, err := LoadCustomOperations("../../test/fixtures/operations/pause.yaml")
+	require.NoError(t, err)
+	require.Len(t, operations, 2)
+
+	pause := operations[0]
+	assert.Equal(t, "pause", pause.Name)
+	assert.Equal(t, "Pause", pause.GoName())
+	assert.Equal(t, PatchTypeMerge, pause.PatchType, "the patch type defaults to merge")
+	assert.Equal(t, "MergePatchType", pause.GetPatchType())
+	assert.JSONEq(t, `{"spec":{"paused":true}}`, string(pause.Patch))
+
+	rotate := operations[1]
+	assert.Equal(t, "RotateCredentials", rotate.GoName())
+	assert.Equal(t, "JSONPatchType", rotate.GetPatchType())
+	assert.Equal(t, `[{"op":"remove","path":"/spec/secretRef"}]`, string(rotate.Patch))
+}
+
+func TestLoadCustomOperationsInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"empty", "operations: []\n", "no operations declared"},
+		{"unknown field", "operations:\n- name: pause\n  description: Pause\n  patch: {spec: {paused: true}}\n  method: PATCH\n", `unknown field "method"`},
+		{"invalid name", "operations:\n- name: Pause_Now\n  description: Pause\n  patch: {spec: {paused: true}}\n", `name "Pause_Now" must be lowercase words`},
+		{"builtin name", "operations:\n- name: delete\n  description: Delete\n  patch: {spec: {paused: true}}\n", `name "delete" is taken`},
+		{"missing description", "operations:\n- name: pause\n  patch: {spec: {paused: true}}\n", "pause: description is required"},
+		{"missing patch", "operations:\n- name: pause\n  description: Pause\n", "pause: a merge patch must be a non-empty object"},
+		{"merge patch list", "operations:\n- name: pause\n  description: Pause\n  patch: [{op: remove, path: /spec}]\n", "pause: a merge patch must be a non-empty object"},
+		{"json patch object", "operations:\n- name: pause\n  description: Pause\n  patchType: json\n  patch: {spec: {paused: true}}\n", "pause: a json patch must be a non-empty list"},
+		{"invalid patch type", "operations:\n- name: pause\n  description: Pause\n  patchType: strategic\n  patch: {spec: {paused: true}}\n", `invalid patchType "strategic"`},
+		{"duplicate", "operations:\n- name: pause\n  description: Pause\n  patch: {spec: {paused: true}}\n- name: pause\n  description: Pause again\n  patch: {spec: {paused: true}}\n", `operation "pause" is declared twice`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "operations.yaml")
+			require.NoError(t, os.WriteFile(filename, []byte(tt.content), 0o600))
+
+			_, err := LoadCustomOperations(filename)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	_, err := LoadCustomOperations("does-not-exist.yaml")
+	assert.ErrorContains(t, err, "failed to read operations file")
+}
@@ -0,0 +1,74 @@
+package analyzer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// pruneSchemaFields returns a copy of schema without the fields at the given dot-separated paths,
+// e.g. spec.credentials.password. Paths descend through object properties and array items.
+// Warnings are returned for paths that do not match any field.
+func pruneSchemaFields(schema *apiextensionsv1.JSONSchemaProps, paths []string) (*apiextensionsv1.JSONSchemaProps, []string) {
+	if schema == nil || len(paths) == 0 {
+		return schema, nil
+	}
+
+	pruned := *schema
+	var warnings []string
+	for _, path := range paths {
+		segments := strings.Split(path, ".")
+		if slices.Contains(segments, "") {
+			warnings = append(warnings, fmt.Sprintf("excluded field %q is not a valid path", path))
+			continue
+		}
+		if !pruneField(&pruned, segments) {
+			warnings = append(warnings, fmt.Sprintf("excluded field %q does not exist in the schema", path))
+		}
+	}
+	return &pruned, warnings
+}
+
+// pruneField removes the field at path from schema, copying every map, slice and schema it
+// changes so the original schema is left untouched. It reports whether the field was found.
+func pruneField(schema *apiextensionsv1.JSONSchemaProps, path []string) bool {
+	// Fields of array items are addressed through the array itself
+	if schema.Type == "array" && schema.Items != nil && schema.Items.Schema != nil {
+		items := *schema.Items
+		itemSchema := *items.Schema
+		if !pruneField(&itemSchema, path) {
+			return false
+		}
+		items.Schema = &itemSchema
+		schema.Items = &items
+		return true
+	}
+
+	name := path[0]
+	prop, exists := schema.Properties[name]
+	if !exists {
+		return false
+	}
+
+	properties := make(map[string]apiextensionsv1.JSONSchemaProps, len(schema.Properties))
+	for propName, propSchema := range schema.Properties {
+		properties[propName] = propSchema
+	}
+
+	if len(path) > 1 {
+		if !pruneField(&prop, path[1:]) {
+			return false
+		}
+		properties[name] = prop
+	} else {
+		delete(properties, name)
+		schema.Required = slices.DeleteFunc(slices.Clone(schema.Required), func(required string) bool {
+			return required == name
+		})
+	}
+
+	schema.Properties = properties
+	return true
+}
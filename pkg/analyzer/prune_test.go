@@ -0,0 +1,74 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestPruneSchemaFields(t *testing.T) {
+	schema := &apiextensionsv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"spec": {
+				Type:     "object",
+				Required: []string{"endpoint", "token"},
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{
+					"endpoint": {Type: "string"},
+					"token":    {Type: "string"},
+					"credentials": {
+						Type: "object",
+						Properties: map[string]apiextensionsv1.JSONSchemaProps{
+							"username": {Type: "string"},
+							"password": {Type: "string"},
+						},
+					},
+					"env": {
+						Type: "array",
+						Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{
+							Type: "object",
+							Properties: map[string]apiextensionsv1.JSONSchemaProps{
+								"name":  {Type: "string"},
+								"value": {Type: "string"},
+							},
+						}},
+					},
+				},
+			},
+		},
+	}
+
+	pruned, warnings := pruneSchemaFields(schema, []string{"spec.token", "spec.credentials.password", "spec.env.value"})
+	require.Empty(t, warnings)
+
+	spec := pruned.Properties["spec"]
+	assert.NotContains(t, spec.Properties, "token")
+	assert.Equal(t, []string{"endpoint"}, spec.Required)
+	assert.Contains(t, spec.Properties, "endpoint")
+	assert.NotContains(t, spec.Properties["credentials"].Properties, "password")
+	assert.Contains(t, spec.Properties["credentials"].Properties, "username")
+	assert.NotContains(t, spec.Properties["env"].Items.Schema.Properties, "value")
+
+	original := schema.Properties["spec"]
+	assert.Contains(t, original.Properties, "token", "the input schema should not be modified")
+	assert.Equal(t, []string{"endpoint", "token"}, original.Required)
+	assert.Contains(t, original.Properties["credentials"].Properties, "password")
+	assert.Contains(t, original.Properties["env"].Items.Schema.Properties, "value")
+}
+
+func TestPruneSchemaFieldsUnknownPaths(t *testing.T) {
+	schema := &apiextensionsv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"spec": {Type: "object", Properties: map[string]apiextensionsv1.JSONSchemaProps{"name": {Type: "string"}}},
+		},
+	}
+
+	pruned, warnings := pruneSchemaFields(schema, []string{"spec.missing", "spec..name", "spec.name.first"})
+	require.Len(t, warnings, 3)
+	assert.Contains(t, warnings[0], `"spec.missing" does not exist`)
+	assert.Contains(t, warnings[1], `"spec..name" is not a valid path`)
+	assert.Contains(t, pruned.Properties["spec"].Properties, "name")
+}
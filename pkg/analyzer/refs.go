@@ -0,0 +1,119 @@
+package analyzer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// definitionsRefPrefix is the prefix of $ref values that point into the schema's definitions
+const definitionsRefPrefix = "#/definitions/"
+
+// refResolver inlines $ref references to the definitions of a single schema document
+type refResolver struct {
+	definitions apiextensionsv1.JSONSchemaDefinitions
+	warnings    []string
+}
+
+// resolveSchemaRefs returns a copy of schema with every $ref to its definitions inlined, along
+// with warnings for references that could not be inlined. Cyclic and unknown references are
+// replaced by an untyped schema, which maps to interface{} in Go and accepts any JSON value.
+func resolveSchemaRefs(schema *apiextensionsv1.JSONSchemaProps) (*apiextensionsv1.JSONSchemaProps, []string) {
+	if schema == nil {
+		return nil, nil
+	}
+
+	resolver := &refResolver{definitions: schema.Definitions}
+	resolved := resolver.resolve(*schema, nil)
+	resolved.Definitions = nil
+	return &resolved, resolver.warnings
+}
+
+// resolve inlines the references in schema. stack holds the definitions currently being inlined
+// and is used to detect cycles.
+func (r *refResolver) resolve(schema apiextensionsv1.JSONSchemaProps, stack []string) apiextensionsv1.JSONSchemaProps {
+	if schema.Ref != nil {
+		return r.resolveRef(schema, stack)
+	}
+
+	if schema.Properties != nil {
+		properties := make(map[string]apiextensionsv1.JSONSchemaProps, len(schema.Properties))
+		for name, prop := range schema.Properties {
+			properties[name] = r.resolve(prop, stack)
+		}
+		schema.Properties = properties
+	}
+
+	if schema.Items != nil {
+		items := *schema.Items
+		if items.Schema != nil {
+			itemSchema := r.resolve(*items.Schema, stack)
+			items.Schema = &itemSchema
+		}
+		items.JSONSchemas = r.resolveAll(items.JSONSchemas, stack)
+		schema.Items = &items
+	}
+
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		additional := *schema.AdditionalProperties
+		valueSchema := r.resolve(*additional.Schema, stack)
+		additional.Schema = &valueSchema
+		schema.AdditionalProperties = &additional
+	}
+
+	if schema.Not != nil {
+		not := r.resolve(*schema.Not, stack)
+		schema.Not = &not
+	}
+
+	schema.AllOf = r.resolveAll(schema.AllOf, stack)
+	schema.AnyOf = r.resolveAll(schema.AnyOf, stack)
+	schema.OneOf = r.resolveAll(schema.OneOf, stack)
+
+	return schema
+}
+
+// resolveAll resolves each schema of a list
+func (r *refResolver) resolveAll(schemas []apiextensionsv1.JSONSchemaProps, stack []string) []apiextensionsv1.JSONSchemaProps {
+	if schemas == nil {
+		return nil
+	}
+
+	resolved := make([]apiextensionsv1.JSONSchemaProps, len(schemas))
+	for i := range schemas {
+		resolved[i] = r.resolve(schemas[i], stack)
+	}
+	return resolved
+}
+
+// resolveRef replaces a $ref schema by the referenced definition. A description set next to the
+// $ref takes precedence over the one of the definition.
+func (r *refResolver) resolveRef(schema apiextensionsv1.JSONSchemaProps, stack []string) apiextensionsv1.JSONSchemaProps {
+	ref := *schema.Ref
+	fallback := apiextensionsv1.JSONSchemaProps{Description: schema.Description}
+
+	name, ok := strings.CutPrefix(ref, definitionsRefPrefix)
+	if !ok {
+		r.warnings = append(r.warnings, fmt.Sprintf("$ref %q is not supported, only %s<name> references are resolved; using an untyped field", ref, definitionsRefPrefix))
+		return fallback
+	}
+
+	definition, ok := r.definitions[name]
+	if !ok {
+		r.warnings = append(r.warnings, fmt.Sprintf("$ref %q points to an unknown definition; using an untyped field", ref))
+		return fallback
+	}
+
+	if slices.Contains(stack, name) {
+		r.warnings = append(r.warnings, fmt.Sprintf("$ref %q is cyclic (%s -> %s); using an untyped field", ref, strings.Join(stack, " -> "), name))
+		return fallback
+	}
+
+	resolved := r.resolve(definition, append(slices.Clone(stack), name))
+	if schema.Description != "" {
+		resolved.Description = schema.Description
+	}
+	return resolved
+}
@@ -0,0 +1,103 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func refTo(name string) *string {
+	ref := definitionsRefPrefix + name
+	return &ref
+}
+
+func TestResolveSchemaRefs(t *testing.T) {
+	schema := &apiextensionsv1.JSONSchemaProps{
+		Type: "object",
+		Definitions: apiextensionsv1.JSONSchemaDefinitions{
+			"Endpoint": {
+				Type:        "object",
+				Description: "Network endpoint",
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{
+					"host": {Type: "string"},
+				},
+			},
+		},
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"primary":  {Ref: refTo("Endpoint")},
+			"fallback": {Ref: refTo("Endpoint"), Description: "Fallback endpoint"},
+			"replicas": {
+				Type:  "array",
+				Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{Ref: refTo("Endpoint")}},
+			},
+		},
+	}
+
+	resolved, warnings := resolveSchemaRefs(schema)
+	assert.Empty(t, warnings)
+	assert.Nil(t, resolved.Definitions)
+
+	primary := resolved.Properties["primary"]
+	assert.Nil(t, primary.Ref)
+	assert.Equal(t, "object", primary.Type)
+	assert.Equal(t, "Network endpoint", primary.Description)
+	assert.Contains(t, primary.Properties, "host")
+
+	assert.Equal(t, "Fallback endpoint", resolved.Properties["fallback"].Description)
+	assert.Equal(t, "object", resolved.Properties["replicas"].Items.Schema.Type)
+
+	assert.NotNil(t, schema.Properties["primary"].Ref, "the input schema should not be modified")
+}
+
+func TestResolveSchemaRefsCycle(t *testing.T) {
+	schema := &apiextensionsv1.JSONSchemaProps{
+		Type: "object",
+		Definitions: apiextensionsv1.JSONSchemaDefinitions{
+			"Node": {
+				Type: "object",
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{
+					"name": {Type: "string"},
+					"children": {
+						Type:  "array",
+						Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{Ref: refTo("Node")}},
+					},
+				},
+			},
+		},
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"root": {Ref: refTo("Node")},
+		},
+	}
+
+	resolved, warnings := resolveSchemaRefs(schema)
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "cyclic (Node -> Node)")
+
+	children := resolved.Properties["root"].Properties["children"]
+	assert.Equal(t, apiextensionsv1.JSONSchemaProps{}, *children.Items.Schema, "the cyclic reference should become an untyped schema")
+
+	typeInfo, err := NewSchemaAnalyzer().AnalyzeSchema(resolved, "Tree", "")
+	require.NoError(t, err)
+	assert.Equal(t, "[]interface{}", typeInfo.Properties["root"].Properties["children"].GoType)
+}
+
+func TestResolveSchemaRefsUnknown(t *testing.T) {
+	schema := &apiextensionsv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"missing":  {Ref: refTo("Missing")},
+			"external": {Ref: ptrTo("https://example.com/schema.json")},
+		},
+	}
+
+	resolved, warnings := resolveSchemaRefs(schema)
+	assert.Len(t, warnings, 2)
+	assert.Equal(t, "", resolved.Properties["missing"].Type)
+	assert.Nil(t, resolved.Properties["external"].Ref)
+}
+
+func ptrTo(s string) *string {
+	return &s
+}
@@ -0,0 +1,70 @@
+package analyzer
+
+import (
+	"maps"
+	"slices"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// requiredSkippedFields are the top-level fields whose requirements are not checked before a
+// create or update: the handlers set apiVersion and kind, metadata is checked by the API server
+// and status is not written by create or update
+var requiredSkippedFields = []string{"apiVersion", "kind", "metadata", "status"}
+
+// GetRequiredFieldPaths returns the dot-paths of the fields a created or updated resource must
+// set, e.g. spec.name, so generated handlers can name missing fields before calling the API. The
+// fields of a nested object are only required when the object is set. Fields with a default,
+// which the API server fills in, fields excluded from the schemas and fields below arrays and
+// maps are left out.
+func (t *ToolsetInfo) GetRequiredFieldPaths() []string {
+	if t.Schema == nil {
+		return nil
+	}
+
+	var paths []string
+	t.collectRequiredFieldPaths(t.Schema, "", &paths)
+	return paths
+}
+
+// collectRequiredFieldPaths appends the paths of the required fields of the object schema and of
+// its nested objects, with the allOf parts of each merged in
+func (t *ToolsetInfo) collectRequiredFieldPaths(schema *apiextensionsv1.JSONSchemaProps, prefix string, paths *[]string) {
+	properties, required := mergedProperties(schema)
+	for _, name := range slices.Sorted(maps.Keys(properties)) {
+		if prefix == "" && slices.Contains(requiredSkippedFields, name) {
+			continue
+		}
+		path := prefix + name
+		if t.IsFieldExcluded(path) {
+			continue
+		}
+
+		property := properties[name]
+		if slices.Contains(required, name) && property.Default == nil {
+			*paths = append(*paths, path)
+		}
+		if property.Type == "object" && (len(property.Properties) > 0 || len(property.AllOf) > 0) {
+			t.collectRequiredFieldPaths(&property, path+".", paths)
+		}
+	}
+}
+
+// mergedProperties returns the properties and required properties of the object schema, with
+// those of its allOf parts merged in
+func mergedProperties(schema *apiextensionsv1.JSONSchemaProps) (map[string]apiextensionsv1.JSONSchemaProps, []string) {
+	properties := maps.Clone(schema.Properties)
+	required := slices.Clone(schema.Required)
+	for _, part := range schema.AllOf {
+		for name, property := range part.Properties {
+			if _, ok := properties[name]; !ok {
+				if properties == nil {
+					properties = map[string]apiextensionsv1.JSONSchemaProps{}
+				}
+				properties[name] = property
+			}
+		}
+		required = append(required, part.Required...)
+	}
+	return properties, required
+}
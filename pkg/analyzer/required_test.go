@@ -0,0 +1,79 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestGetRequiredFieldPaths(t *testing.T) {
+	crdInfo := &CRDInfo{
+		Kind:     "Gadget",
+		ListKind: "GadgetList",
+		Plural:   "gadgets",
+		Schema: &apiextensionsv1.JSONSchemaProps{
+			Type:     "object",
+			Required: []string{"metadata", "spec", "status"},
+			Properties: map[string]apiextensionsv1.JSONSchemaProps{
+				"metadata": {Type: "object"},
+				"spec": {
+					Type:     "object",
+					Required: []string{"name", "mode", "token", "ports"},
+					Properties: map[string]apiextensionsv1.JSONSchemaProps{
+						"name":  {Type: "string"},
+						"mode":  {Type: "string", Default: &apiextensionsv1.JSON{Raw: []byte(`"fast"`)}},
+						"token": {Type: "string"},
+						"ports": {
+							Type: "array",
+							Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{
+								Type:       "object",
+								Required:   []string{"port"},
+								Properties: map[string]apiextensionsv1.JSONSchemaProps{"port": {Type: "integer"}},
+							}},
+						},
+						"backend": {
+							Type:       "object",
+							Required:   []string{"url"},
+							Properties: map[string]apiextensionsv1.JSONSchemaProps{"url": {Type: "string"}},
+						},
+					},
+					AllOf: []apiextensionsv1.JSONSchemaProps{{
+						Required:   []string{"host"},
+						Properties: map[string]apiextensionsv1.JSONSchemaProps{"host": {Type: "string"}},
+					}},
+				},
+				"status": {
+					Type:       "object",
+					Required:   []string{"phase"},
+					Properties: map[string]apiextensionsv1.JSONSchemaProps{"phase": {Type: "string"}},
+				},
+			},
+		},
+	}
+
+	toolset, err := NewToolsetInfo(crdInfo, &GenerationConfig{PackageName: "gadgets", ExcludedFields: []string{"spec.token"}})
+	require.NoError(t, err)
+	// Defaulted, excluded and array item fields are left out, nested ones only apply to a set object
+	assert.Equal(t, []string{"spec", "spec.backend.url", "spec.host", "spec.name", "spec.ports"}, toolset.GetRequiredFieldPaths())
+}
+
+func TestGetRequiredFieldPathsFixtures(t *testing.T) {
+	for fixture, want := range map[string][]string{
+		"simple-crd.yaml":      {"spec.name"},
+		"complex-crd.yaml":     {"spec.selector", "spec.template"},
+		"composition-crd.yaml": {"spec.host"},
+		"schemaless-crd.yaml":  nil,
+	} {
+		t.Run(fixture, func(t *testing.T) {
+			crdInfo, err := NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/" + fixture)
+			require.NoError(t, err)
+			config := DefaultGenerationConfig()
+			config.AllowUnstructured = true
+			toolset, err := NewToolsetInfo(crdInfo, config)
+			require.NoError(t, err)
+			assert.Equal(t, want, toolset.GetRequiredFieldPaths())
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// SampleField is a spec field set to a sample value in generated tests
+type SampleField struct {
+	Name  string // Go field name
+	Value string // Go expression of the sample value
+}
+
+// GetSampleSpecFields returns sample values for the required scalar fields of the spec, so
+// generated tests can build objects that satisfy the CRD schema. Required fields of other types
+// keep their zero value.
+func (t *ToolsetInfo) GetSampleSpecFields() []SampleField {
+	if t.SpecType == nil || t.SpecType.IsRawJSON() {
+		return nil
+	}
+
+	var specSchema apiextensionsv1.JSONSchemaProps
+	if t.Schema != nil {
+		specSchema = t.Schema.Properties["spec"]
+	}
+
+	var samples []SampleField
+	for _, field := range t.SpecType.GetStructFields() {
+		if !field.Required {
+			continue
+		}
+		fieldSchema := specSchema.Properties[field.JSONName]
+		if value, ok := sampleValue(field.GoType, &fieldSchema); ok {
+			samples = append(samples, SampleField{Name: field.GetGoFieldName(), Value: value})
+		}
+	}
+	return samples
+}
+
+// sampleValue returns a Go expression of type goType that the schema accepts: the first enum
+// value, or else the minimum for numbers. It reports false for types that are not scalars.
+func sampleValue(goType string, schema *apiextensionsv1.JSONSchemaProps) (string, bool) {
+	switch goType {
+	case "string":
+		if len(schema.Enum) > 0 {
+			var value string
+			if err := json.Unmarshal(schema.Enum[0].Raw, &value); err == nil {
+				return strconv.Quote(value), true
+			}
+		}
+		return strconv.Quote("sample"), true
+	case "int", "int32", "int64", "float32", "float64":
+		value := 1.0
+		if schema.Minimum != nil && *schema.Minimum > value {
+			value = math.Ceil(*schema.Minimum)
+		}
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	case "bool":
+		return "true", true
+	default:
+		return "", false
+	}
+}
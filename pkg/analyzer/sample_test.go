@@ -0,0 +1,54 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestGetSampleSpecFields(t *testing.T) {
+	minimum := 2.5
+	crdInfo := &CRDInfo{
+		Kind:     "Gadget",
+		ListKind: "GadgetList",
+		Plural:   "gadgets",
+		Schema: &apiextensionsv1.JSONSchemaProps{
+			Type: "object",
+			Properties: map[string]apiextensionsv1.JSONSchemaProps{
+				"spec": {
+					Type:     "object",
+					Required: []string{"color", "mode", "size", "ratio", "enabled", "parts"},
+					Properties: map[string]apiextensionsv1.JSONSchemaProps{
+						"color":   {Type: "string"},
+						"mode":    {Type: "string", Enum: []apiextensionsv1.JSON{{Raw: []byte(`"fast"`)}, {Raw: []byte(`"slow"`)}}},
+						"size":    {Type: "integer", Format: "int32", Minimum: &minimum},
+						"ratio":   {Type: "number"},
+						"enabled": {Type: "boolean"},
+						"parts":   {Type: "array", Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{Type: "string"}}},
+						"note":    {Type: "string"},
+					},
+				},
+			},
+		},
+	}
+
+	toolset, err := NewToolsetInfo(crdInfo, &GenerationConfig{PackageName: "gadgets"})
+	require.NoError(t, err)
+
+	values := map[string]string{}
+	for _, sample := range toolset.GetSampleSpecFields() {
+		values[sample.Name] = sample.Value
+	}
+	assert.Equal(t, map[string]string{
+		"GadgetSpecColor":   `"sample"`,
+		"GadgetSpecMode":    `"fast"`,
+		"GadgetSpecSize":    "3",
+		"GadgetSpecRatio":   "1",
+		"GadgetSpecEnabled": "true",
+	}, values, "only required scalar fields get sample values")
+
+	toolset.SpecType = nil
+	assert.Empty(t, toolset.GetSampleSpecFields())
+}
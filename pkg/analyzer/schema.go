@@ -1,7 +1,10 @@
 package analyzer
 
 import (
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
@@ -20,11 +23,14 @@ type GoTypeInfo struct {
 	Required    bool                   // Whether the field is required
 	Properties  map[string]*GoTypeInfo // For object types, nested properties
 	Items       *GoTypeInfo            // For array types, the item type
+	Values      *GoTypeInfo            // For map types, the value type
+	Import      string                 // Import path required by GoType, if any
 }
 
 // SchemaAnalyzer analyzes OpenAPI v3 schemas and generates Go type information
 type SchemaAnalyzer struct {
 	typeCache map[string]*GoTypeInfo
+	warnings  []string
 }
 
 // NewSchemaAnalyzer creates a new SchemaAnalyzer
@@ -40,12 +46,20 @@ func (s *SchemaAnalyzer) AnalyzeSchema(schema *apiextensionsv1.JSONSchemaProps,
 		return nil, fmt.Errorf("schema is nil")
 	}
 
-	// Check cache first
-	cacheKey := fmt.Sprintf("%s_%s", typeName, fieldName)
+	// Check cache first. Callers adjust the returned info (e.g. Required), so they get a copy.
+	cacheKey, err := typeCacheKey(schema, typeName, fieldName)
+	if err != nil {
+		return nil, err
+	}
 	if cached, exists := s.typeCache[cacheKey]; exists {
-		return cached, nil
+		copied := *cached
+		return &copied, nil
 	}
 
+	// allOf parts are merged into the type, while oneOf and anyOf are only enforced by the JSON schema
+	schema = mergeAllOf(schema)
+	s.warnUnsupportedComposition(schema, typeName)
+
 	typeInfo := &GoTypeInfo{
 		Name:        typeName,
 		JSONName:    fieldName,
@@ -58,10 +72,19 @@ func (s *SchemaAnalyzer) AnalyzeSchema(schema *apiextensionsv1.JSONSchemaProps,
 		return nil, fmt.Errorf("failed to determine Go type for %s: %w", typeName, err)
 	}
 	typeInfo.GoType = goType
+	if schema.XIntOrString {
+		typeInfo.Import = intOrStringImport
+	}
 
 	// Generate JSON tag
 	typeInfo.JSONTag = s.generateJSONTag(fieldName, schema)
 
+	// Subtrees that preserve unknown fields are kept as raw JSON, so their properties are not generated
+	if preservesUnknownFields(schema) {
+		s.cacheType(cacheKey, typeInfo)
+		return typeInfo, nil
+	}
+
 	// Handle object types with properties
 	if schema.Type == "object" && len(schema.Properties) > 0 {
 		typeInfo.Properties = make(map[string]*GoTypeInfo)
@@ -82,6 +105,16 @@ func (s *SchemaAnalyzer) AnalyzeSchema(schema *apiextensionsv1.JSONSchemaProps,
 		}
 	}
 
+	// Handle map types
+	if schema.Type == "object" && len(schema.Properties) == 0 && hasAdditionalPropertiesSchema(schema) {
+		valueTypeName := s.generateValueTypeName(typeName)
+		valueInfo, err := s.AnalyzeSchema(schema.AdditionalProperties.Schema, valueTypeName, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze map values for %s: %w", typeName, err)
+		}
+		typeInfo.Values = valueInfo
+	}
+
 	// Handle array types
 	if schema.Type == "array" && schema.Items != nil && schema.Items.Schema != nil {
 		itemTypeName := s.generateItemTypeName(typeName)
@@ -93,24 +126,66 @@ func (s *SchemaAnalyzer) AnalyzeSchema(schema *apiextensionsv1.JSONSchemaProps,
 	}
 
 	// Cache the result
-	s.typeCache[cacheKey] = typeInfo
+	s.cacheType(cacheKey, typeInfo)
 	return typeInfo, nil
 }
 
+// cacheType stores a copy of typeInfo, so changes callers make to the returned info are not cached
+func (s *SchemaAnalyzer) cacheType(cacheKey string, typeInfo *GoTypeInfo) {
+	cached := *typeInfo
+	s.typeCache[cacheKey] = &cached
+}
+
 const (
 	goTypeString = "string"
+
+	// intOrStringImport provides intstr.IntOrString for x-kubernetes-int-or-string fields
+	intOrStringImport = "k8s.io/apimachinery/pkg/util/intstr"
+
+	// rawJSONType holds x-kubernetes-preserve-unknown-fields subtrees verbatim. It lives in
+	// apimachinery, which generated types already import, unlike apiextensionsv1.JSON.
+	rawJSONType = "runtime.RawExtension"
 )
 
+// stringFormatTypes maps string formats to the Go types that hold their values. The metav1 types
+// need no import entry since generated types always import metav1.
+var stringFormatTypes = map[string]string{
+	"date-time": "metav1.Time",
+	"byte":      "[]byte",
+	"duration":  "metav1.Duration",
+}
+
+// typeCacheKey identifies the type analyzed from schema under the given names. The schema itself is
+// part of the key, so differently shaped schemas that share a type and field name never collide.
+func typeCacheKey(schema *apiextensionsv1.JSONSchemaProps, typeName, fieldName string) (string, error) {
+	shape, err := json.Marshal(schema)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash schema of %s: %w", typeName, err)
+	}
+	sum := sha256.Sum256(shape)
+	return fmt.Sprintf("%s_%s_%x", typeName, fieldName, sum[:8]), nil
+}
+
 // getGoTypeFromSchema determines the appropriate Go type for a given schema
 //
 //nolint:gocyclo // Complex type mapping logic is necessary for comprehensive CRD schema support
 func (s *SchemaAnalyzer) getGoTypeFromSchema(schema *apiextensionsv1.JSONSchemaProps, typeName string) (string, error) {
+	if schema.XIntOrString {
+		return "intstr.IntOrString", nil
+	}
+	if preservesUnknownFields(schema) {
+		return rawJSONType, nil
+	}
+
 	switch schema.Type {
 	case goTypeString:
 		if len(schema.Enum) > 0 {
 			// For enums, we could generate a custom type, but for simplicity use string
 			return goTypeString, nil
 		}
+		if goType, ok := stringFormatTypes[schema.Format]; ok {
+			return goType, nil
+		}
 		return goTypeString, nil
 
 	case "integer":
@@ -144,6 +219,14 @@ func (s *SchemaAnalyzer) getGoTypeFromSchema(schema *apiextensionsv1.JSONSchemaP
 			// This is a structured object, use the type name
 			return typeName, nil
 		}
+		if hasAdditionalPropertiesSchema(schema) {
+			// A map whose values follow the additionalProperties schema
+			valueType, err := s.getGoTypeFromSchema(schema.AdditionalProperties.Schema, s.generateValueTypeName(typeName))
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("map[string]%s", valueType), nil
+		}
 		// Generic object
 		return "map[string]interface{}", nil
 
@@ -167,6 +250,79 @@ func (s *SchemaAnalyzer) getGoTypeFromSchema(schema *apiextensionsv1.JSONSchemaP
 	}
 }
 
+// preservesUnknownFields reports whether a schema is marked with x-kubernetes-preserve-unknown-fields
+func preservesUnknownFields(schema *apiextensionsv1.JSONSchemaProps) bool {
+	return schema.XPreserveUnknownFields != nil && *schema.XPreserveUnknownFields
+}
+
+// Warnings returns the schema constructs that could not be represented in the generated Go types,
+// sorted for stable output
+func (s *SchemaAnalyzer) Warnings() []string {
+	warnings := slices.Clone(s.warnings)
+	sort.Strings(warnings)
+	return warnings
+}
+
+// warnUnsupportedComposition records a warning for oneOf and anyOf alternatives, which Go structs
+// cannot express. The alternatives are still emitted into the JSON schema used for validation.
+func (s *SchemaAnalyzer) warnUnsupportedComposition(schema *apiextensionsv1.JSONSchemaProps, typeName string) {
+	// Int-or-string fields are commonly declared as anyOf integer/string and map to intstr.IntOrString
+	if schema.XIntOrString {
+		return
+	}
+
+	if len(schema.OneOf) > 0 {
+		s.warnings = append(s.warnings, fmt.Sprintf("%s: oneOf is enforced by the JSON schema only; the Go type contains the declared properties", typeName))
+	}
+	if len(schema.AnyOf) > 0 {
+		s.warnings = append(s.warnings, fmt.Sprintf("%s: anyOf is enforced by the JSON schema only; the Go type contains the declared properties", typeName))
+	}
+}
+
+// mergeAllOf returns a copy of schema with the type, properties and required fields of its allOf
+// parts merged in. The schema is returned unchanged if it has no allOf parts.
+func mergeAllOf(schema *apiextensionsv1.JSONSchemaProps) *apiextensionsv1.JSONSchemaProps {
+	if len(schema.AllOf) == 0 {
+		return schema
+	}
+
+	merged := *schema
+	merged.Properties = make(map[string]apiextensionsv1.JSONSchemaProps, len(schema.Properties))
+	for name, prop := range schema.Properties {
+		merged.Properties[name] = prop
+	}
+	merged.Required = append([]string(nil), schema.Required...)
+
+	for i := range schema.AllOf {
+		part := mergeAllOf(&schema.AllOf[i])
+		if merged.Type == "" {
+			merged.Type = part.Type
+		}
+		for name, prop := range part.Properties {
+			if _, exists := merged.Properties[name]; !exists {
+				merged.Properties[name] = prop
+			}
+		}
+		for _, required := range part.Required {
+			if !slices.Contains(merged.Required, required) {
+				merged.Required = append(merged.Required, required)
+			}
+		}
+	}
+
+	if len(merged.Properties) == 0 {
+		merged.Properties = nil
+	} else if merged.Type == "" {
+		merged.Type = "object"
+	}
+	return &merged
+}
+
+// hasAdditionalPropertiesSchema reports whether an object schema defines the schema of its map values
+func hasAdditionalPropertiesSchema(schema *apiextensionsv1.JSONSchemaProps) bool {
+	return schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil
+}
+
 // generateJSONTag creates the appropriate JSON tag for a field
 func (s *SchemaAnalyzer) generateJSONTag(fieldName string, schema *apiextensionsv1.JSONSchemaProps) string {
 	// Add omitempty for optional fields
@@ -199,6 +355,11 @@ func (s *SchemaAnalyzer) generateItemTypeName(arrayType string) string {
 	return arrayType + "Item"
 }
 
+// generateValueTypeName creates a Go type name for map values
+func (s *SchemaAnalyzer) generateValueTypeName(mapType string) string {
+	return mapType + "Value"
+}
+
 // toGoName converts a JSON field name to Go naming conventions
 func (s *SchemaAnalyzer) toGoName(name string) string {
 	// Split on common separators and capitalize each part
@@ -236,16 +397,76 @@ func (typeInfo *GoTypeInfo) GetStructFields() []*GoTypeInfo {
 	return fields
 }
 
+// GetImports returns the import paths required by this type and all nested types, sorted
+func (typeInfo *GoTypeInfo) GetImports() []string {
+	seen := make(map[string]bool)
+	typeInfo.collectImports(seen)
+	return sortedImports(seen)
+}
+
+// sortedImports returns the collected import paths in sorted order
+func sortedImports(seen map[string]bool) []string {
+	imports := make([]string, 0, len(seen))
+	for imp := range seen {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// collectImports adds the import paths of this type and its nested types to seen
+func (typeInfo *GoTypeInfo) collectImports(seen map[string]bool) {
+	if typeInfo.Import != "" {
+		seen[typeInfo.Import] = true
+	}
+	for _, prop := range typeInfo.Properties {
+		prop.collectImports(seen)
+	}
+	if typeInfo.Items != nil {
+		typeInfo.Items.collectImports(seen)
+	}
+	if typeInfo.Values != nil {
+		typeInfo.Values.collectImports(seen)
+	}
+}
+
 // IsComplexType returns true if this represents a complex type (struct)
 func (typeInfo *GoTypeInfo) IsComplexType() bool {
 	return len(typeInfo.Properties) > 0
 }
 
+// IsRawJSON returns true if this represents a subtree that is kept as raw JSON
+func (typeInfo *GoTypeInfo) IsRawJSON() bool {
+	return typeInfo.GoType == rawJSONType
+}
+
+// IsGenericObject returns true if this represents an object without declared properties, which is
+// generated as map[string]interface{}
+func (typeInfo *GoTypeInfo) IsGenericObject() bool {
+	return typeInfo.GoType == "map[string]interface{}"
+}
+
+// IsFormattedString returns true if this represents a string with a format mapped to a dedicated
+// Go type, such as metav1.Time for date-time
+func (typeInfo *GoTypeInfo) IsFormattedString() bool {
+	for _, goType := range stringFormatTypes {
+		if typeInfo.GoType == goType {
+			return true
+		}
+	}
+	return false
+}
+
 // IsArrayType returns true if this represents an array type
 func (typeInfo *GoTypeInfo) IsArrayType() bool {
 	return strings.HasPrefix(typeInfo.GoType, "[]")
 }
 
+// IsMapType returns true if this represents a map type
+func (typeInfo *GoTypeInfo) IsMapType() bool {
+	return strings.HasPrefix(typeInfo.GoType, "map[")
+}
+
 // IsPrimitiveType returns true if this represents a primitive Go type
 func (typeInfo *GoTypeInfo) IsPrimitiveType() bool {
 	primitives := map[string]bool{
@@ -13,9 +13,8 @@ func TestSchemaAnalyzerBasic(t *testing.T) {
 	require.NotNil(t, analyzer)
 }
 
-// TODO: This test is currently failing due to type caching issues - needs investigation
 func TestAnalyzeSchema(t *testing.T) {
-	t.Skip("Skipping due to type caching issues - needs fix")
+	// A single analyzer is shared so differently shaped schemas with the same names hit the cache
 	analyzer := NewSchemaAnalyzer()
 
 	tests := []struct {
@@ -82,7 +81,7 @@ func TestAnalyzeSchema(t *testing.T) {
 			},
 			typeName:   "TestType",
 			fieldName:  "TestField",
-			wantGoType: "TestTypeTestField",
+			wantGoType: "TestType",
 			wantError:  false,
 		},
 	}
@@ -103,6 +102,69 @@ func TestAnalyzeSchema(t *testing.T) {
 	}
 }
 
+func TestAnalyzeSchemaStringFormats(t *testing.T) {
+	tests := []struct {
+		format     string
+		wantGoType string
+	}{
+		{"date-time", "metav1.Time"},
+		{"duration", "metav1.Duration"},
+		{"byte", "[]byte"},
+		{"uri", "string"},
+		{"", "string"},
+	}
+
+	analyzer := NewSchemaAnalyzer()
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			result, err := analyzer.AnalyzeSchema(&apiextensionsv1.JSONSchemaProps{Type: "string", Format: tt.format}, "Schedule", "field")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantGoType, result.GoType)
+			assert.Equal(t, tt.wantGoType != "string", result.IsFormattedString())
+			assert.Empty(t, result.Import, "metav1 is always imported by the generated types")
+		})
+	}
+}
+
+func TestAnalyzeSchemaSharedNames(t *testing.T) {
+	crdInfo, err := NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/shared-names-crd.yaml")
+	require.NoError(t, err)
+
+	toolset, err := NewToolsetInfo(crdInfo, nil)
+	require.NoError(t, err)
+
+	frontend := toolset.SpecType.Properties["frontend"].Properties["config"]
+	backend := toolset.SpecType.Properties["backend"].Properties["config"]
+	require.NotNil(t, frontend)
+	require.NotNil(t, backend)
+
+	assert.NotEqual(t, frontend.GoType, backend.GoType)
+	assert.ElementsMatch(t, []string{"replicas", "theme"}, mapKeys(frontend.Properties))
+	assert.ElementsMatch(t, []string{"image", "debug"}, mapKeys(backend.Properties))
+	assert.True(t, frontend.Properties["replicas"].Required)
+	assert.True(t, backend.Properties["image"].Required)
+	assert.False(t, backend.Properties["debug"].Required)
+
+	// Analyzing the same schema again returns a copy, so callers cannot change cached types
+	analyzer := NewSchemaAnalyzer()
+	schema := &apiextensionsv1.JSONSchemaProps{Type: "string"}
+	first, err := analyzer.AnalyzeSchema(schema, "Type", "field")
+	require.NoError(t, err)
+	first.Required = true
+	second, err := analyzer.AnalyzeSchema(schema, "Type", "field")
+	require.NoError(t, err)
+	assert.False(t, second.Required)
+}
+
+// mapKeys returns the keys of m in no particular order
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
 // TODO: This test is currently failing - needs investigation
 func TestGetGoTypeFromSchema(t *testing.T) {
 	t.Skip("Skipping due to test expectations mismatch - needs fix")
@@ -285,3 +347,199 @@ func TestGoTypeInfoMethods(t *testing.T) {
 	assert.Len(t, fields, 1)
 	assert.Equal(t, "Field1", fields[0].Name)
 }
+
+func TestAnalyzeSchemaIntOrString(t *testing.T) {
+	analyzer := NewSchemaAnalyzer()
+
+	schema := &apiextensionsv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"port": {XIntOrString: true},
+			"ports": {
+				Type:  "array",
+				Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{XIntOrString: true}},
+			},
+		},
+	}
+
+	typeInfo, err := analyzer.AnalyzeSchema(schema, "ServiceSpec", "spec")
+	require.NoError(t, err)
+
+	assert.Equal(t, "intstr.IntOrString", typeInfo.Properties["port"].GoType)
+	assert.Equal(t, "[]intstr.IntOrString", typeInfo.Properties["ports"].GoType)
+	assert.Equal(t, []string{"k8s.io/apimachinery/pkg/util/intstr"}, typeInfo.GetImports())
+}
+
+func TestAnalyzeSchemaPreserveUnknownFields(t *testing.T) {
+	analyzer := NewSchemaAnalyzer()
+	preserve := true
+
+	schema := &apiextensionsv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"config": {
+				Type:                   "object",
+				XPreserveUnknownFields: &preserve,
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{
+					"known": {Type: "string"},
+				},
+			},
+		},
+	}
+
+	typeInfo, err := analyzer.AnalyzeSchema(schema, "PluginSpec", "spec")
+	require.NoError(t, err)
+
+	config := typeInfo.Properties["config"]
+	assert.Equal(t, "runtime.RawExtension", config.GoType)
+	assert.True(t, config.IsRawJSON())
+	assert.False(t, config.IsComplexType(), "properties of a preserved subtree should not be generated")
+}
+
+func TestAnalyzeSchemaAdditionalProperties(t *testing.T) {
+	analyzer := NewSchemaAnalyzer()
+
+	schema := &apiextensionsv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"labels": {
+				Type: "object",
+				AdditionalProperties: &apiextensionsv1.JSONSchemaPropsOrBool{
+					Allows: true,
+					Schema: &apiextensionsv1.JSONSchemaProps{Type: "string"},
+				},
+			},
+			"weights": {
+				Type: "object",
+				AdditionalProperties: &apiextensionsv1.JSONSchemaPropsOrBool{
+					Allows: true,
+					Schema: &apiextensionsv1.JSONSchemaProps{Type: "integer", Format: "int32"},
+				},
+			},
+			"groups": {
+				Type: "object",
+				AdditionalProperties: &apiextensionsv1.JSONSchemaPropsOrBool{
+					Allows: true,
+					Schema: &apiextensionsv1.JSONSchemaProps{
+						Type: "object",
+						AdditionalProperties: &apiextensionsv1.JSONSchemaPropsOrBool{
+							Allows: true,
+							Schema: &apiextensionsv1.JSONSchemaProps{Type: "boolean"},
+						},
+					},
+				},
+			},
+			"backends": {
+				Type: "object",
+				AdditionalProperties: &apiextensionsv1.JSONSchemaPropsOrBool{
+					Allows: true,
+					Schema: &apiextensionsv1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]apiextensionsv1.JSONSchemaProps{
+							"host": {Type: "string"},
+						},
+					},
+				},
+			},
+			"anything": {
+				Type:                 "object",
+				AdditionalProperties: &apiextensionsv1.JSONSchemaPropsOrBool{Allows: true},
+			},
+		},
+	}
+
+	typeInfo, err := analyzer.AnalyzeSchema(schema, "RouteSpec", "spec")
+	require.NoError(t, err)
+
+	assert.Equal(t, "map[string]string", typeInfo.Properties["labels"].GoType)
+	assert.Equal(t, "map[string]int32", typeInfo.Properties["weights"].GoType)
+	assert.Equal(t, "map[string]map[string]bool", typeInfo.Properties["groups"].GoType)
+	assert.Equal(t, "map[string]interface{}", typeInfo.Properties["anything"].GoType)
+
+	backends := typeInfo.Properties["backends"]
+	assert.Equal(t, "map[string]RouteSpecBackendsValue", backends.GoType)
+	assert.True(t, backends.IsMapType())
+	require.NotNil(t, backends.Values)
+	assert.True(t, backends.Values.IsComplexType())
+	assert.Equal(t, "RouteSpecBackendsValue", backends.Values.Name)
+}
+
+func TestAnalyzeSchemaComposition(t *testing.T) {
+	analyzer := NewSchemaAnalyzer()
+
+	schema := &apiextensionsv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"name": {Type: "string"},
+		},
+		AllOf: []apiextensionsv1.JSONSchemaProps{
+			{
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{
+					"host": {Type: "string"},
+				},
+				Required: []string{"host"},
+			},
+			{
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{
+					"port": {Type: "integer", Format: "int32"},
+				},
+			},
+		},
+		OneOf: []apiextensionsv1.JSONSchemaProps{
+			{Required: []string{"name"}},
+			{Required: []string{"host"}},
+		},
+	}
+
+	typeInfo, err := analyzer.AnalyzeSchema(schema, "RouteSpec", "spec")
+	require.NoError(t, err)
+
+	require.Len(t, typeInfo.Properties, 3, "allOf properties should be merged into the parent type")
+	assert.Equal(t, "string", typeInfo.Properties["host"].GoType)
+	assert.True(t, typeInfo.Properties["host"].Required)
+	assert.Equal(t, "int32", typeInfo.Properties["port"].GoType)
+	assert.Len(t, schema.Properties, 1, "the input schema should not be modified")
+
+	require.Len(t, analyzer.Warnings(), 1)
+	assert.Contains(t, analyzer.Warnings()[0], "RouteSpec: oneOf")
+}
+
+func TestAnalyzeSchemaAllOfOnly(t *testing.T) {
+	analyzer := NewSchemaAnalyzer()
+
+	schema := &apiextensionsv1.JSONSchemaProps{
+		AllOf: []apiextensionsv1.JSONSchemaProps{
+			{
+				Type: "object",
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{
+					"enabled": {Type: "boolean"},
+				},
+			},
+		},
+	}
+
+	typeInfo, err := analyzer.AnalyzeSchema(schema, "FeatureSpec", "features")
+	require.NoError(t, err)
+
+	assert.Equal(t, "FeatureSpec", typeInfo.GoType)
+	assert.True(t, typeInfo.IsComplexType())
+	assert.Empty(t, analyzer.Warnings())
+}
+
+func TestAnalyzeSchemaIntOrStringAnyOfHasNoWarning(t *testing.T) {
+	analyzer := NewSchemaAnalyzer()
+
+	schema := &apiextensionsv1.JSONSchemaProps{
+		XIntOrString: true,
+		AnyOf: []apiextensionsv1.JSONSchemaProps{
+			{Type: "integer"},
+			{Type: "string"},
+		},
+	}
+
+	typeInfo, err := analyzer.AnalyzeSchema(schema, "Port", "port")
+	require.NoError(t, err)
+
+	assert.Equal(t, "intstr.IntOrString", typeInfo.GoType)
+	assert.Empty(t, analyzer.Warnings())
+}
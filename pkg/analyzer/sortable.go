@@ -0,0 +1,55 @@
+package analyzer
+
+import (
+	"maps"
+	"slices"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// sortableTypes are the schema types of the fields list results can be sorted by
+var sortableTypes = []string{"string", "integer", "number", "boolean"}
+
+// sortableSkippedFields are the top-level fields whose schema is not walked for sortable fields:
+// apiVersion and kind are the same on every item and the sortable metadata fields are fixed
+var sortableSkippedFields = []string{"apiVersion", "kind", "metadata"}
+
+// GetSortableFieldPaths returns the dot-paths of the fields the items of a list can be sorted by:
+// the name, namespace and creationTimestamp of the metadata and the scalar fields of the schema,
+// e.g. spec.replicas. Fields below arrays and maps, which an item can hold several of, and
+// fields excluded from the schemas are left out.
+func (t *ToolsetInfo) GetSortableFieldPaths() []string {
+	paths := []string{"metadata.name"}
+	if t.CRD.IsNamespaced() {
+		paths = append(paths, "metadata.namespace")
+	}
+	paths = append(paths, "metadata.creationTimestamp")
+
+	if t.Schema != nil {
+		t.collectSortableFieldPaths(t.Schema, "", &paths)
+	}
+	return paths
+}
+
+// collectSortableFieldPaths appends the paths of the scalar fields of the object schema and of
+// its nested objects
+func (t *ToolsetInfo) collectSortableFieldPaths(schema *apiextensionsv1.JSONSchemaProps, prefix string, paths *[]string) {
+	properties, _ := mergedProperties(schema)
+	for _, name := range slices.Sorted(maps.Keys(properties)) {
+		if prefix == "" && slices.Contains(sortableSkippedFields, name) {
+			continue
+		}
+		path := prefix + name
+		if t.IsFieldExcluded(path) {
+			continue
+		}
+
+		property := properties[name]
+		switch {
+		case slices.Contains(sortableTypes, property.Type):
+			*paths = append(*paths, path)
+		case property.Type == "object" && (len(property.Properties) > 0 || len(property.AllOf) > 0):
+			t.collectSortableFieldPaths(&property, path+".", paths)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestGetSortableFieldPaths(t *testing.T) {
+	crdInfo := &CRDInfo{
+		Kind:     "Gadget",
+		ListKind: "GadgetList",
+		Plural:   "gadgets",
+		Scope:    "Namespaced",
+		Schema: &apiextensionsv1.JSONSchemaProps{
+			Type: "object",
+			Properties: map[string]apiextensionsv1.JSONSchemaProps{
+				"apiVersion": {Type: "string"},
+				"kind":       {Type: "string"},
+				"metadata":   {Type: "object"},
+				"spec": {
+					Type: "object",
+					Properties: map[string]apiextensionsv1.JSONSchemaProps{
+						"name":     {Type: "string"},
+						"replicas": {Type: "integer"},
+						"token":    {Type: "string"},
+						"ports": {
+							Type: "array",
+							Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{
+								Type:       "object",
+								Properties: map[string]apiextensionsv1.JSONSchemaProps{"port": {Type: "integer"}},
+							}},
+						},
+						"labels": {
+							Type:                 "object",
+							AdditionalProperties: &apiextensionsv1.JSONSchemaPropsOrBool{Schema: &apiextensionsv1.JSONSchemaProps{Type: "string"}},
+						},
+						"backend": {
+							Type:       "object",
+							Properties: map[string]apiextensionsv1.JSONSchemaProps{"weight": {Type: "number"}},
+						},
+					},
+					AllOf: []apiextensionsv1.JSONSchemaProps{{
+						Properties: map[string]apiextensionsv1.JSONSchemaProps{"enabled": {Type: "boolean"}},
+					}},
+				},
+				"status": {
+					Type:       "object",
+					Properties: map[string]apiextensionsv1.JSONSchemaProps{"phase": {Type: "string"}},
+				},
+			},
+		},
+	}
+
+	toolset, err := NewToolsetInfo(crdInfo, &GenerationConfig{PackageName: "gadgets", ExcludedFields: []string{"spec.token"}})
+	require.NoError(t, err)
+	// Excluded fields and the fields of array items and maps are left out
+	assert.Equal(t, []string{
+		"metadata.name", "metadata.namespace", "metadata.creationTimestamp",
+		"spec.backend.weight", "spec.enabled", "spec.name", "spec.replicas", "status.phase",
+	}, toolset.GetSortableFieldPaths())
+
+	crdInfo.Scope = "Cluster"
+	toolset, err = NewToolsetInfo(crdInfo, &GenerationConfig{PackageName: "gadgets"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"metadata.name", "metadata.creationTimestamp"}, toolset.GetSortableFieldPaths()[:2])
+}
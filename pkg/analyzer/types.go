@@ -2,7 +2,14 @@ package analyzer
 
 import (
 	"fmt"
+	"go/token"
+	"path"
+	"slices"
 	"strings"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // GenerationConfig holds configuration for code generation
@@ -23,14 +30,102 @@ type GenerationConfig struct {
 	GenerateCRDResource bool
 	GenerateDocResource bool
 	DocResourcePath     string
+	GeneratePrompts     bool // Add an MCP prompt per tool with example arguments
 	IncludeComments     bool
 	SelectedOperations  []string
 
+	// Field exclusion
+	ExcludedFields            []string // Dot-separated paths pruned from the generated schemas
+	KeepExcludedFieldsInTypes bool     // Keep excluded fields in the generated Go types
+
 	// Kubernetes integration
 	UseControllerRuntime bool
 	MultiClusterSupport  bool
+	DefaultNamespace     string // Namespace used by handlers when the namespace argument is omitted
+	AllNamespacesList    bool   // Let the list tool list across all namespaces with allNamespaces
+
+	// ShortNameTools adds an alias of every tool named after each short name of the CRD,
+	// e.g. wgt_get next to widgets_get
+	ShortNameTools bool
+
+	// ToolsetDescription replaces the toolset description derived from the CRD when set
+	ToolsetDescription string
+
+	// Scope replaces the scope declared by the CRD when set, e.g. to generate a namespaced
+	// toolset for a cluster-scoped CRD
+	Scope apiextensionsv1.ResourceScope
+
+	// Plural and Singular replace the names of the CRD that the package, toolset, tools and
+	// methods are named after when set, e.g. for irregular or domain-specific plurals. The
+	// resource requested from the API server keeps the plural of the CRD.
+	Plural   string
+	Singular string
+
+	// Group replaces the API group of the CRD when set, so the generated code targets a fork
+	// of the CRD installed under a renamed group
+	Group string
+
+	// AllowUnstructured generates an unstructured toolset for CRDs without a structural schema
+	// instead of failing
+	AllowUnstructured bool
+
+	// ToolPrefix goes in front of every tool name when set, e.g. acme_widgets_get, so the tools
+	// of CRDs with the same plural in different groups can be served side by side
+	ToolPrefix string
+
+	// WithCache generates a client whose reads are served from an informer cache, with a Start
+	// method that runs and syncs it
+	WithCache bool
+
+	// FindTool generates a find tool next to get that returns the single resource matching a
+	// label selector, for when a unique label is known but the name is not
+	FindTool bool
+
+	// ReadOnly limits the toolset to the operations that do not change resources, get, list and
+	// find, whatever SelectedOperations holds, and annotates all its tools as read-only
+	ReadOnly bool
+
+	// MaxDepth caps how many levels of fields below spec and status get their own types; deeper
+	// objects are generated as map[string]interface{}. Zero means no limit.
+	MaxDepth int
+
+	// CustomOperations are generated as tools next to the CRUD operations, each applying its patch
+	CustomOperations []CustomOperation
+
+	// HandlerTimeout bounds the Kubernetes API calls of a tool call, so a hung API server fails the
+	// call instead of hanging it. Zero means no timeout.
+	HandlerTimeout time.Duration
+
+	// OutputLayout tells where the toolset packages sit in the module: below its pkg directory
+	// (OutputLayoutPkg, the default) or directly in its root (OutputLayoutFlat)
+	OutputLayout string
+
+	// ImportPath replaces the import path of the toolset package that OutputLayout implies when
+	// set, for modules that keep generated code elsewhere, e.g. in a monorepo
+	ImportPath string
+}
+
+// Values of GenerationConfig.OutputLayout
+const (
+	OutputLayoutPkg  = "pkg"  // Toolset packages at <module>/pkg/<package>
+	OutputLayoutFlat = "flat" // Toolset packages at <module>/<package>
+)
+
+// ToolsetImportPath returns the import path of the toolset package named packageName: ImportPath
+// if set, otherwise the path OutputLayout places the package at in the module
+func (c *GenerationConfig) ToolsetImportPath(packageName string) string {
+	if c.ImportPath != "" {
+		return c.ImportPath
+	}
+	if c.OutputLayout == OutputLayoutFlat {
+		return path.Join(c.ModulePath, packageName)
+	}
+	return path.Join(c.ModulePath, "pkg", packageName)
 }
 
+// DefaultHandlerTimeout is the timeout of the Kubernetes API calls of a tool call unless configured
+const DefaultHandlerTimeout = 30 * time.Second
+
 // DefaultGenerationConfig returns a default configuration
 func DefaultGenerationConfig() *GenerationConfig {
 	return &GenerationConfig{
@@ -43,6 +138,7 @@ func DefaultGenerationConfig() *GenerationConfig {
 		IncludeComments:      true,
 		UseControllerRuntime: true,
 		MultiClusterSupport:  true,
+		HandlerTimeout:       DefaultHandlerTimeout,
 	}
 }
 
@@ -51,18 +147,32 @@ type ToolsetInfo struct {
 	// CRD information
 	CRD *CRDInfo
 
+	// Schema used for the generated JSON schemas, without excluded fields
+	Schema *apiextensionsv1.JSONSchemaProps
+
 	// Generated types
 	MainType   *GoTypeInfo
 	SpecType   *GoTypeInfo
 	StatusType *GoTypeInfo
 	ListType   *GoTypeInfo
 
+	// NestedTypes are the struct types nested in the spec and status at any depth, including
+	// those of array items and map values, in the order they are generated
+	NestedTypes []*GoTypeInfo
+
 	// Package information
 	PackageName string
 	ImportPath  string
 
 	// Configuration
 	Config *GenerationConfig
+
+	// Warnings about schema constructs that are not reflected in the generated types
+	Warnings []string
+
+	// Unstructured is set for CRDs without a structural schema, whose objects are generated as
+	// unstructured.Unstructured rather than as typed structs
+	Unstructured bool
 }
 
 // NewToolsetInfo creates ToolsetInfo from CRDInfo
@@ -74,6 +184,25 @@ func NewToolsetInfo(crd *CRDInfo, config *GenerationConfig) (*ToolsetInfo, error
 		config = DefaultGenerationConfig()
 	}
 
+	// The overrides apply to this toolset only, so the CRD info is copied
+	if (config.Scope != "" && config.Scope != crd.Scope) || config.Plural != "" || config.Singular != "" ||
+		(config.Group != "" && config.Group != crd.Group) {
+		overridden := *crd
+		if config.Scope != "" {
+			overridden.Scope = config.Scope
+		}
+		if config.Plural != "" {
+			overridden.Plural = config.Plural
+		}
+		if config.Singular != "" {
+			overridden.Singular = config.Singular
+		}
+		if config.Group != "" {
+			overridden.Group = config.Group
+		}
+		crd = &overridden
+	}
+
 	packageName := config.PackageName
 	if packageName == "" {
 		packageName = crd.GetPackageName()
@@ -82,7 +211,7 @@ func NewToolsetInfo(crd *CRDInfo, config *GenerationConfig) (*ToolsetInfo, error
 	toolset := &ToolsetInfo{
 		CRD:         crd,
 		PackageName: packageName,
-		ImportPath:  fmt.Sprintf("%s/pkg/%s", config.ModulePath, packageName),
+		ImportPath:  config.ToolsetImportPath(packageName),
 		Config:      config,
 	}
 
@@ -100,17 +229,54 @@ func (t *ToolsetInfo) analyzeTypes() error {
 		return fmt.Errorf("CRD schema is required for type generation")
 	}
 
+	// Excluded fields are always left out of the JSON schemas and, unless requested otherwise,
+	// out of the Go types
+	var pruneWarnings []string
+	t.Schema, pruneWarnings = pruneSchemaFields(t.CRD.Schema, t.Config.ExcludedFields)
+	typesSchema := t.Schema
+	if t.Config.KeepExcludedFieldsInTypes {
+		typesSchema = t.CRD.Schema
+	}
+
+	// Objects nested deeper than the maximum depth are collapsed in the types and JSON schemas alike
+	var depthWarnings []string
+	if t.Config.MaxDepth > 0 {
+		keepExcluded := typesSchema != t.Schema
+		t.Schema, depthWarnings = limitSchemaDepth(t.Schema, t.Config.MaxDepth)
+		if keepExcluded {
+			// The excluded fields kept in the types may be collapsed too, the others are warned about once
+			var typesWarnings []string
+			typesSchema, typesWarnings = limitSchemaDepth(typesSchema, t.Config.MaxDepth)
+			depthWarnings = append(depthWarnings, typesWarnings...)
+			slices.Sort(depthWarnings)
+			depthWarnings = slices.Compact(depthWarnings)
+		} else {
+			typesSchema = t.Schema
+		}
+	}
+
+	if !hasStructuralSchema(typesSchema) {
+		if !t.Config.AllowUnstructured {
+			return fmt.Errorf("the schema of %s preserves unknown fields at the root or has no properties, "+
+				"so no types can be generated from it; use --allow-unstructured to generate an unstructured toolset", t.CRD.Kind)
+		}
+		t.Unstructured = true
+		t.Warnings = slices.Concat(t.CRD.Warnings, pruneWarnings, depthWarnings,
+			[]string{fmt.Sprintf("%s has no structural schema, so its objects are generated as unstructured.Unstructured", t.CRD.Kind)})
+		return nil
+	}
+
 	analyzer := NewSchemaAnalyzer()
 
 	// Generate main type
-	mainType, err := analyzer.AnalyzeSchema(t.CRD.Schema, t.CRD.GetTypeName(), "")
+	mainType, err := analyzer.AnalyzeSchema(typesSchema, t.CRD.GetTypeName(), "")
 	if err != nil {
 		return fmt.Errorf("failed to analyze main type: %w", err)
 	}
 	t.MainType = mainType
 
 	// Generate spec type if it exists
-	if specSchema, exists := t.CRD.Schema.Properties["spec"]; exists {
+	if specSchema, exists := typesSchema.Properties["spec"]; exists {
 		specType, err := analyzer.AnalyzeSchema(&specSchema, t.CRD.GetTypeName()+"Spec", "spec")
 		if err != nil {
 			return fmt.Errorf("failed to analyze spec type: %w", err)
@@ -119,7 +285,7 @@ func (t *ToolsetInfo) analyzeTypes() error {
 	}
 
 	// Generate status type if it exists
-	if statusSchema, exists := t.CRD.Schema.Properties["status"]; exists {
+	if statusSchema, exists := typesSchema.Properties["status"]; exists {
 		statusType, err := analyzer.AnalyzeSchema(&statusSchema, t.CRD.GetTypeName()+"Status", "status")
 		if err != nil {
 			return fmt.Errorf("failed to analyze status type: %w", err)
@@ -127,6 +293,22 @@ func (t *ToolsetInfo) analyzeTypes() error {
 		t.StatusType = statusType
 	}
 
+	t.NestedTypes = collectNestedTypes(t.SpecType, t.StatusType)
+
+	// A spec or status of type object without properties has no fields to generate a struct from
+	var genericWarnings []string
+	for _, part := range []struct {
+		name     string
+		typeInfo *GoTypeInfo
+	}{{"spec", t.SpecType}, {"status", t.StatusType}} {
+		if part.typeInfo != nil && part.typeInfo.IsGenericObject() {
+			genericWarnings = append(genericWarnings, fmt.Sprintf(
+				"the %s of %s declares no properties, so it is generated as map[string]interface{}", part.name, t.CRD.Kind))
+		}
+	}
+
+	t.Warnings = slices.Concat(t.CRD.Warnings, pruneWarnings, depthWarnings, genericWarnings, analyzer.Warnings())
+
 	// Generate list type
 	listType := &GoTypeInfo{
 		Name:     t.CRD.GetListTypeName(),
@@ -138,26 +320,81 @@ func (t *ToolsetInfo) analyzeTypes() error {
 	return nil
 }
 
+// hasStructuralSchema reports whether a CRD schema describes the objects well enough to generate
+// types from: it has properties besides apiVersion, kind and metadata, and does not preserve
+// unknown fields at the root, which the typed structs would drop
+func hasStructuralSchema(schema *apiextensionsv1.JSONSchemaProps) bool {
+	if preservesUnknownFields(schema) {
+		return false
+	}
+	for name := range schema.Properties {
+		if name != "apiVersion" && name != "kind" && name != "metadata" {
+			return true
+		}
+	}
+	return false
+}
+
 // GetToolsetName returns the name for the MCP toolset
 func (t *ToolsetInfo) GetToolsetName() string {
 	return strings.ToLower(t.CRD.Plural)
 }
 
-// GetToolsetDescription returns a description for the MCP toolset
+// GetCategories returns the categories of the CRD (such as "all"), which the generated toolset
+// advertises so clients can filter tools by category
+func (t *ToolsetInfo) GetCategories() []string {
+	return t.CRD.Categories
+}
+
+// GetToolsetDescription returns a description for the MCP toolset. A configured description is
+// used as is, otherwise it is taken from the top-level description of the CRD schema when there is one.
 func (t *ToolsetInfo) GetToolsetDescription() string {
+	if t.Config.ToolsetDescription != "" {
+		return t.Config.ToolsetDescription
+	}
+	if description := t.GetSchemaDescription(); description != "" {
+		return description
+	}
 	return fmt.Sprintf("Tools for managing %s custom resources", t.CRD.Kind)
 }
 
-// GetResourceOperations returns the list of CRUD operations to generate
+// GetSchemaDescription returns the top-level description of the CRD schema on a single line, or ""
+// if the schema has no description. The generator truncates it in tool descriptions to
+// --description-max-length.
+func (t *ToolsetInfo) GetSchemaDescription() string {
+	if t.CRD.Schema == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(t.CRD.Schema.Description), " ")
+}
+
+// GetResourceOperations returns the list of operations to generate: the selected CRUD operations,
+// plus scale for CRDs with a scale subresource when updates are selected and find when it is
+// enabled and gets are selected
 func (t *ToolsetInfo) GetResourceOperations() []string {
 	// Use selected operations if specified, otherwise use default
-	if len(t.Config.SelectedOperations) > 0 {
-		return t.Config.SelectedOperations
+	operations := t.Config.SelectedOperations
+	if len(operations) == 0 {
+		// Default to all operations
+		operations = []string{"create", "get", "list", "update", "delete"}
+	}
+	if t.CRD.HasScaleSubresource() && slices.Contains(operations, "update") {
+		operations = append(slices.Clone(operations), "scale")
+	}
+	if t.Config.FindTool && slices.Contains(operations, "get") {
+		operations = append(slices.Clone(operations), "find")
+	}
+	if t.Config.ReadOnly {
+		operations = slices.DeleteFunc(slices.Clone(operations), func(operation string) bool {
+			return !slices.Contains(readOnlyOperations, operation)
+		})
 	}
-	// Default to all operations
-	return []string{"create", "get", "list", "update", "delete"}
+	return operations
 }
 
+// readOnlyOperations are the operations that do not change resources
+var readOnlyOperations = []string{"get", "list", "find"}
+
 // GetImports returns the Go imports needed for the generated code
 func (t *ToolsetInfo) GetImports() []string {
 	imports := []string{
@@ -189,6 +426,17 @@ func (t *ToolsetInfo) GetKubernetesImports() []string {
 	}
 }
 
+// GetTypesImports returns the additional imports required by the generated spec and status types
+func (t *ToolsetInfo) GetTypesImports() []string {
+	seen := make(map[string]bool)
+	for _, typeInfo := range []*GoTypeInfo{t.SpecType, t.StatusType} {
+		if typeInfo != nil {
+			typeInfo.collectImports(seen)
+		}
+	}
+	return sortedImports(seen)
+}
+
 // GetMCPImports returns MCP-specific imports
 func (t *ToolsetInfo) GetMCPImports() []string {
 	return []string{
@@ -206,6 +454,35 @@ func (t *ToolsetInfo) HasStatus() bool {
 	return t.StatusType != nil
 }
 
+// GetDefaultNamespace returns the namespace handlers fall back to when none is given, which is
+// always empty for cluster-scoped resources
+func (t *ToolsetInfo) GetDefaultNamespace() string {
+	if !t.CRD.IsNamespaced() {
+		return ""
+	}
+	return t.Config.DefaultNamespace
+}
+
+// GetShortNameAliases returns the short names of the CRD that the tools get aliases for, which
+// are none unless ShortNameTools is set
+func (t *ToolsetInfo) GetShortNameAliases() []string {
+	if !t.Config.ShortNameTools {
+		return nil
+	}
+	return t.CRD.ShortNames
+}
+
+// HasAllNamespacesList returns true if the list tool accepts allNamespaces to list across all
+// namespaces, which is never the case for cluster-scoped resources
+func (t *ToolsetInfo) HasAllNamespacesList() bool {
+	return t.CRD.IsNamespaced() && t.Config.AllNamespacesList
+}
+
+// IsFieldExcluded returns true if the field at the dot-separated path is excluded from the schemas
+func (t *ToolsetInfo) IsFieldExcluded(path string) bool {
+	return slices.Contains(t.Config.ExcludedFields, path)
+}
+
 // GetAPIVersion returns the API version for the CRD
 func (t *ToolsetInfo) GetAPIVersion() string {
 	return t.CRD.GetAPIVersion()
@@ -216,8 +493,12 @@ func (t *ToolsetInfo) GetKind() string {
 	return t.CRD.Kind
 }
 
-// GetResource returns the resource name (plural)
+// GetResource returns the resource name (plural) served by the API server, which is not
+// affected by a Plural override of the config
 func (t *ToolsetInfo) GetResource() string {
+	if t.CRD.CRD != nil {
+		return t.CRD.CRD.Spec.Names.Plural
+	}
 	return t.CRD.Plural
 }
 
@@ -233,5 +514,129 @@ func (t *ToolsetInfo) GetVersion() string {
 
 // GetGroupVersionResource returns the GroupVersionResource tuple
 func (t *ToolsetInfo) GetGroupVersionResource() string {
-	return fmt.Sprintf("%s/%s/%s", t.CRD.Group, t.CRD.Version, t.CRD.Plural)
+	return fmt.Sprintf("%s/%s/%s", t.CRD.Group, t.CRD.Version, t.GetResource())
+}
+
+// GroupVersionResource returns the GroupVersionResource of the CRD's resource
+func (t *ToolsetInfo) GroupVersionResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: t.CRD.Group, Version: t.CRD.Version, Resource: t.GetResource()}
+}
+
+// GetGroupVersionKind returns the GroupVersionKind of the CRD's kind
+func (t *ToolsetInfo) GetGroupVersionKind() schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: t.CRD.Group, Version: t.CRD.Version, Kind: t.CRD.Kind}
+}
+
+// Validate checks that the names used in generated code are valid Go identifiers and returns a
+// description of each problem found
+func (t *ToolsetInfo) Validate() []string {
+	var problems []string
+
+	if !token.IsIdentifier(t.PackageName) {
+		problems = append(problems, fmt.Sprintf("package name %q is not a valid Go identifier", t.PackageName))
+	}
+	if !token.IsIdentifier(t.CRD.Kind) || !token.IsExported(t.CRD.Kind) {
+		problems = append(problems, fmt.Sprintf("kind %q is not a valid exported Go identifier", t.CRD.Kind))
+	}
+
+	for _, typeInfo := range []*GoTypeInfo{t.SpecType, t.StatusType} {
+		collectInvalidIdentifiers(typeInfo, &problems)
+	}
+
+	problems = append(problems, nestedTypeConflicts(t.SpecType, t.StatusType)...)
+
+	return problems
+}
+
+// walkNestedTypes calls visit for every struct type nested in the given types, depth first.
+// Array items and map values are unwrapped at any depth, so [][]T and map[string][]T reach T.
+func walkNestedTypes(typeInfo *GoTypeInfo, visit func(*GoTypeInfo)) {
+	if typeInfo == nil {
+		return
+	}
+
+	for _, field := range typeInfo.GetStructFields() {
+		nested := field
+		for !nested.IsComplexType() && (nested.Items != nil || nested.Values != nil) {
+			if nested.Items != nil {
+				nested = nested.Items
+			} else {
+				nested = nested.Values
+			}
+		}
+
+		if nested.IsComplexType() {
+			visit(nested)
+			walkNestedTypes(nested, visit)
+		}
+	}
+}
+
+// collectNestedTypes returns the struct types nested in the given types, each name only once
+func collectNestedTypes(types ...*GoTypeInfo) []*GoTypeInfo {
+	var nestedTypes []*GoTypeInfo
+	seen := make(map[string]bool)
+
+	for _, typeInfo := range types {
+		walkNestedTypes(typeInfo, func(nested *GoTypeInfo) {
+			if !seen[nested.Name] {
+				seen[nested.Name] = true
+				nestedTypes = append(nestedTypes, nested)
+			}
+		})
+	}
+	return nestedTypes
+}
+
+// nestedTypeConflicts returns a problem for each nested type name that is generated for
+// differently shaped schemas, which would declare the same Go type twice
+func nestedTypeConflicts(types ...*GoTypeInfo) []string {
+	var problems []string
+	byName := make(map[string]*GoTypeInfo)
+
+	for _, typeInfo := range types {
+		walkNestedTypes(typeInfo, func(nested *GoTypeInfo) {
+			existing, exists := byName[nested.Name]
+			if !exists {
+				byName[nested.Name] = nested
+				return
+			}
+			if !sameStructFields(existing, nested) {
+				problems = append(problems, fmt.Sprintf("nested type %s is generated for differently shaped schemas", nested.Name))
+			}
+		})
+	}
+	return problems
+}
+
+// sameStructFields reports whether two struct types have the same fields with the same Go types
+func sameStructFields(a, b *GoTypeInfo) bool {
+	aFields, bFields := a.GetStructFields(), b.GetStructFields()
+	if len(aFields) != len(bFields) {
+		return false
+	}
+	for i := range aFields {
+		if aFields[i].Name != bFields[i].Name || aFields[i].GoType != bFields[i].GoType || aFields[i].JSONTag != bFields[i].JSONTag {
+			return false
+		}
+	}
+	return true
+}
+
+// collectInvalidIdentifiers appends a problem for each nested type or field whose generated name is
+// not a valid Go identifier
+func collectInvalidIdentifiers(typeInfo *GoTypeInfo, problems *[]string) {
+	if typeInfo == nil {
+		return
+	}
+
+	for _, field := range typeInfo.GetStructFields() {
+		if name := field.GetGoFieldName(); !token.IsIdentifier(name) {
+			*problems = append(*problems, fmt.Sprintf("field %q of %s maps to invalid Go identifier %q", field.JSONName, typeInfo.Name, name))
+			continue
+		}
+		collectInvalidIdentifiers(field, problems)
+	}
+	collectInvalidIdentifiers(typeInfo.Items, problems)
+	collectInvalidIdentifiers(typeInfo.Values, problems)
 }
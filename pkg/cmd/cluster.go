@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/friedrichwilken/mcp-toolgen/pkg/analyzer"
+)
+
+// generateFromCluster generates code for the CRDs installed in the cluster of the kubeconfig
+func generateFromCluster() error {
+	logger.Debug("generating toolsets from cluster", "kubeconfig", kubeconfig, "group", crdGroup, "outputBase", outputBase)
+
+	clusterCRDs, err := fetchClusterCRDs(context.Background(), kubeconfig, crdGroup)
+	if err != nil {
+		return err
+	}
+
+	if len(clusterCRDs) == 0 {
+		if crdGroup != "" {
+			return fmt.Errorf("no CRDs of group %s found in the cluster", crdGroup)
+		}
+		return fmt.Errorf("no CRDs found in the cluster")
+	}
+
+	logger.Debug("found CRDs in cluster", "count", len(clusterCRDs))
+
+	crdAnalyzer := analyzer.NewCRDAnalyzer()
+	var crds []parsedCRD
+	var summary directorySummary
+	for i := range clusterCRDs {
+		crd := &clusterCRDs[i]
+		crdInfo, err := crdAnalyzer.AnalyzeCRD(crd)
+		if err != nil {
+			logger.Warn("failed to analyze CRD", "crd", crd.Name, "error", err)
+			summary.failed++
+			continue
+		}
+		logger.Info("analyzed CRD", "crd", crd.Name, "kind", crdInfo.Kind, "apiVersion", crdInfo.GetAPIVersion())
+		crds = append(crds, parsedCRD{path: crd.Name, info: crdInfo})
+	}
+
+	return generateFromParsedCRDs(crds, summary, "cluster")
+}
+
+// fetchClusterCRDs lists the CRDs installed in the cluster of the kubeconfig, sorted by name.
+// A non-empty group only keeps the CRDs of that API group.
+func fetchClusterCRDs(ctx context.Context, kubeconfigPath, group string) ([]apiextensionsv1.CustomResourceDefinition, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfigPath
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	clientset, err := apiextensionsclientset.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create apiextensions client: %w", err)
+	}
+
+	list, err := clientset.ApiextensionsV1().CustomResourceDefinitions().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CRDs: %w", err)
+	}
+
+	crds := slices.DeleteFunc(list.Items, func(crd apiextensionsv1.CustomResourceDefinition) bool {
+		return group != "" && crd.Spec.Group != group
+	})
+	slices.SortFunc(crds, func(a, b apiextensionsv1.CustomResourceDefinition) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return crds, nil
+}
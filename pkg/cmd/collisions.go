@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/friedrichwilken/mcp-toolgen/pkg/analyzer"
+)
+
+// Values accepted by the --on-collision flag
+const (
+	collisionError       = "error"
+	collisionGroupPrefix = "group-prefix"
+)
+
+// parsedCRD is a CRD parsed during directory or cluster generation
+type parsedCRD struct {
+	path string // File the CRD was parsed from, or its name for CRDs fetched from a cluster
+	info *analyzer.CRDInfo
+}
+
+// resolvePackageNames returns the package name for each CRD of a batch. CRDs sharing a package
+// name either cause an error or, with the group-prefix strategy, get their group prepended.
+func resolvePackageNames(crds []parsedCRD, strategy string) ([]string, error) {
+	names := make([]string, len(crds))
+	for i, crd := range crds {
+		names[i] = crd.info.GetPackageName()
+	}
+
+	if strategy == collisionGroupPrefix {
+		for _, indices := range findCollisions(names) {
+			for _, i := range indices {
+				names[i] = groupPackagePrefix(crds[i].info.Group) + names[i]
+			}
+		}
+	}
+
+	// Even prefixed names collide when the same CRD appears twice
+	collisions := findCollisions(names)
+	if len(collisions) == 0 {
+		return names, nil
+	}
+
+	var messages []string
+	for name, indices := range collisions {
+		var sources []string
+		for _, i := range indices {
+			sources = append(sources, fmt.Sprintf("%s.%s (%s)", crds[i].info.Plural, crds[i].info.Group, crds[i].path))
+		}
+		messages = append(messages, fmt.Sprintf("package %q is used by %s", name, strings.Join(sources, ", ")))
+	}
+	slices.Sort(messages)
+
+	hint := ""
+	if strategy == collisionError {
+		hint = "; use --on-collision group-prefix to disambiguate"
+	}
+	return nil, fmt.Errorf("package name collision: %s%s", strings.Join(messages, "; "), hint)
+}
+
+// findCollisions returns the indices of the names that occur more than once, keyed by name
+func findCollisions(names []string) map[string][]int {
+	indices := make(map[string][]int)
+	for i, name := range names {
+		indices[name] = append(indices[name], i)
+	}
+
+	for name, occurrences := range indices {
+		if len(occurrences) < 2 {
+			delete(indices, name)
+		}
+	}
+	return indices
+}
+
+// groupPackagePrefix turns an API group into a package name prefix, e.g. example.com becomes examplecom
+func groupPackagePrefix(group string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, strings.ToLower(group))
+}
+
+// validateCollisionStrategy validates the --on-collision flag
+func validateCollisionStrategy(strategy string) error {
+	switch strategy {
+	case collisionError, collisionGroupPrefix:
+		return nil
+	default:
+		return fmt.Errorf("invalid --on-collision %q, valid values are: %s, %s", strategy, collisionError, collisionGroupPrefix)
+	}
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const collisionFixtures = "../../test/fixtures/collisions"
+
+func TestDirectoryGenerationCollisionError(t *testing.T) {
+	outputBase := t.TempDir()
+
+	_, err := executeGenerate(t, "--crd-dir", collisionFixtures, "--output-base", outputBase,
+		"--module-path", "github.com/test/module")
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `package "widgets" is used by widgets.example.com`)
+	assert.ErrorContains(t, err, "widgets.other.io")
+	assert.ErrorContains(t, err, "--on-collision group-prefix")
+
+	entries, err := os.ReadDir(outputBase)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "nothing should be generated when package names collide")
+}
+
+func TestDirectoryGenerationCollisionGroupPrefix(t *testing.T) {
+	outputBase := t.TempDir()
+
+	_, err := executeGenerate(t, "--crd-dir", collisionFixtures, "--output-base", outputBase,
+		"--module-path", "github.com/test/module", "--on-collision", "group-prefix")
+	require.NoError(t, err)
+
+	for _, packageName := range []string{"examplecomwidgets", "otheriowidgets"} {
+		content, err := os.ReadFile(filepath.Join(outputBase, packageName, "register.go"))
+		require.NoError(t, err, packageName)
+		assert.Contains(t, string(content), "package "+packageName+"\n")
+	}
+	_, err = os.Stat(filepath.Join(outputBase, "widgets"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestInvalidCollisionStrategy(t *testing.T) {
+	_, err := executeGenerate(t, "--crd-dir", collisionFixtures, "--output-base", t.TempDir(),
+		"--module-path", "github.com/test/module", "--on-collision", "rename")
+	assert.ErrorContains(t, err, `invalid --on-collision "rename"`)
+}
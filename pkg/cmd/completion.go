@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// completionCmd represents the completion command
+var completionCmd = &cobra.Command{
+	Use:   "completion bash|zsh|fish|powershell",
+	Short: "Generate shell completion scripts",
+	Long: `Generate a completion script for mcp-toolgen for the given shell.
+
+To load completions in the current shell session:
+
+  bash:       source <(mcp-toolgen completion bash)
+  zsh:        source <(mcp-toolgen completion zsh)
+  fish:       mcp-toolgen completion fish | source
+  powershell: mcp-toolgen completion powershell | Out-String | Invoke-Expression
+
+To load completions for every new session, write the output to the completion
+directory of your shell instead.`,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return generateCompletion(cmd, args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
+
+// markCRDFlagCompletion completes the --crd flag of command with YAML files and --crd-dir and
+// --helm-chart with directories
+func markCRDFlagCompletion(command *cobra.Command) {
+	_ = command.MarkFlagFilename("crd", "yaml", "yml") // Error only if flag doesn't exist (programming error)
+	_ = command.MarkFlagDirname("crd-dir")
+	if command.Flags().Lookup("helm-chart") != nil {
+		_ = command.MarkFlagDirname("helm-chart")
+	}
+}
+
+// generateCompletion writes the completion script for shell to the command output
+func generateCompletion(cmd *cobra.Command, shell string) error {
+	root := cmd.Root()
+	out := cmd.OutOrStdout()
+
+	switch shell {
+	case "bash":
+		return root.GenBashCompletionV2(out, true)
+	case "zsh":
+		return root.GenZshCompletion(out)
+	case "fish":
+		return root.GenFishCompletion(out, true)
+	case "powershell":
+		return root.GenPowerShellCompletionWithDesc(out)
+	default:
+		return fmt.Errorf("unsupported shell %q", shell)
+	}
+}
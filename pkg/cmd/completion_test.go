@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// executeCommand runs the root command with the given arguments and returns its output
+func executeCommand(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs(args)
+	t.Cleanup(func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	})
+
+	err := rootCmd.Execute()
+	return out.String(), err
+}
+
+func TestCompletionScripts(t *testing.T) {
+	tests := []struct {
+		shell  string
+		marker string
+	}{
+		{"bash", "# bash completion V2 for mcp-toolgen"},
+		{"zsh", "#compdef mcp-toolgen"},
+		{"fish", "complete -c mcp-toolgen"},
+		{"powershell", "Register-ArgumentCompleter -CommandName 'mcp-toolgen'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			script, err := executeCommand(t, "completion", tt.shell)
+			require.NoError(t, err)
+			assert.Contains(t, script, tt.marker)
+
+			// Syntax-check the script when the shell is available
+			if path, err := exec.LookPath(tt.shell); err == nil && tt.shell != "powershell" {
+				cmd := exec.Command(path, "-n")
+				cmd.Stdin = strings.NewReader(script)
+				output, err := cmd.CombinedOutput()
+				assert.NoError(t, err, "invalid %s script: %s", tt.shell, output)
+			}
+		})
+	}
+}
+
+func TestCompletionRejectsUnknownShell(t *testing.T) {
+	_, err := executeCommand(t, "completion", "tcsh")
+	assert.ErrorContains(t, err, `invalid argument "tcsh"`)
+}
+
+func TestCRDFlagCompletion(t *testing.T) {
+	for _, args := range [][]string{
+		{"__complete", "--crd", ""},
+		{"__complete", "validate", "--crd", ""},
+	} {
+		output, err := executeCommand(t, args...)
+		require.NoError(t, err)
+		assert.Equal(t, "yaml\nyml\n:8\n", output, "args %v", args)
+	}
+}
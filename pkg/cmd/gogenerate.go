@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/friedrichwilken/mcp-toolgen/pkg/analyzer"
+	"github.com/friedrichwilken/mcp-toolgen/pkg/generator"
+)
+
+// goGenerateSkippedFlags are not carried over to the go:generate directive, either because the
+// directive sets them itself or because they only matter for the original invocation
+var goGenerateSkippedFlags = map[string]bool{
+	"crd": true, "crd-dir": true, "output": true, "output-base": true, "package": true, "overwrite": true,
+	"aggregate-scheme": true, "register": true, "modules-file": true, "config": true,
+	"dry-run": true, "dry-run-show-content": true, "output-format": true, "diff": true, "verbose": true, "log-format": true, "log-level": true,
+}
+
+// goGeneratePathFlags hold file paths, which are rewritten relative to the toolset package
+var goGeneratePathFlags = map[string]bool{
+	"templates": true, "header-file": true, "description-file": true, "generate-doc-resource": true,
+}
+
+// invocationFlags are the flags of the generate command, carried over to the go:generate directive.
+// They are assigned in init since referencing rootCmd from the generation code would be an
+// initialization cycle.
+var invocationFlags *pflag.FlagSet
+
+func init() {
+	invocationFlags = rootCmd.Flags()
+}
+
+// writeGoGenerateFile writes the --emit-gogenerate file for the toolset generated from crdPath into outputDir
+func writeGoGenerateFile(toolsetInfo *analyzer.ToolsetInfo, crdPath, outputDir string) error {
+	args, err := goGenerateArgs(invocationFlags, crdPath, outputDir, toolsetInfo.PackageName)
+	if err != nil {
+		return err
+	}
+
+	license, err := loadHeaderFile()
+	if err != nil {
+		return err
+	}
+	header, err := generator.FileHeader(buildTag, license)
+	if err != nil {
+		return err
+	}
+
+	if err := generator.GenerateGoGenerateFile(outputDir, toolsetInfo.PackageName, args, overwrite, header, logger); err != nil {
+		return fmt.Errorf("failed to generate %s: %w", generator.GoGenerateFilename, err)
+	}
+
+	logger.Debug("generated file", "filename", generator.GoGenerateFilename, "dir", outputDir)
+	return nil
+}
+
+// goGenerateArgs returns the mcp-toolgen arguments that regenerate the toolset from crdPath when
+// run in outputDir: the flags set in flags with paths made relative to outputDir
+func goGenerateArgs(flags *pflag.FlagSet, crdPath, outputDir, packageName string) ([]string, error) {
+	crdArg, err := relativeTo(outputDir, crdPath)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"--crd", crdArg, "--output", ".", "--package", packageName, "--overwrite"}
+
+	var errs []error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if !flag.Changed || goGenerateSkippedFlags[flag.Name] {
+			return
+		}
+
+		value := flag.Value.String()
+		if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+			value = strings.Join(sliceValue.GetSlice(), ",")
+		}
+		if goGeneratePathFlags[flag.Name] && !isURL(value) {
+			if value, err = relativeTo(outputDir, value); err != nil {
+				errs = append(errs, err)
+				return
+			}
+		}
+		args = append(args, fmt.Sprintf("--%s=%s", flag.Name, value))
+	})
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// relativeTo returns path relative to dir, using forward slashes so the result works on every platform
+func relativeTo(dir, path string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path for %s: %w", dir, err)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path for %s: %w", path, err)
+	}
+
+	rel, err := filepath.Rel(absDir, absPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to make %s relative to %s: %w", path, dir, err)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// isURL reports whether source is an HTTP(S) URL rather than a file path
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://")
+}
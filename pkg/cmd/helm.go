@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// helmChart is a Helm chart directory with the Chart.yaml and values.yaml its CRDs are expanded with
+type helmChart struct {
+	dir      string
+	metadata map[string]interface{} // Chart.yaml
+	values   map[string]interface{} // values.yaml, empty if the chart has none
+}
+
+// helmAction matches a template action, e.g. {{ .Values.image.tag }} or {{- .Chart.Name -}}
+var helmAction = regexp.MustCompile(`{{-?\s*(.*?)\s*-?}}`)
+
+// helmValuePath matches the field chains the expansion can resolve, e.g. .Values.crds.group
+var helmValuePath = regexp.MustCompile(`^\.(Chart|Values)((?:\.[A-Za-z_][A-Za-z0-9_]*)+)$`)
+
+// loadHelmChart reads the Chart.yaml and values.yaml of the chart in dir
+func loadHelmChart(dir string) (*helmChart, error) {
+	chart := &helmChart{dir: dir}
+
+	data, err := os.ReadFile(filepath.Join(dir, "Chart.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a Helm chart: %w", dir, err)
+	}
+	if err := yaml.Unmarshal(data, &chart.metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse Chart.yaml of %s: %w", dir, err)
+	}
+
+	data, err = os.ReadFile(filepath.Join(dir, "values.yaml"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read values.yaml of %s: %w", dir, err)
+	}
+	if err := yaml.Unmarshal(data, &chart.values); err != nil {
+		return nil, fmt.Errorf("failed to parse values.yaml of %s: %w", dir, err)
+	}
+	return chart, nil
+}
+
+// crdDir returns the crds/ directory of the chart, which Helm installs CRDs from
+func (c *helmChart) crdDir() (string, error) {
+	dir := filepath.Join(c.dir, "crds")
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return "", fmt.Errorf("no crds/ directory in Helm chart %s", c.dir)
+	}
+	return dir, nil
+}
+
+// readCRDFile reads a file of the crds/ directory and expands its template actions
+func (c *helmChart) readCRDFile(filename string) ([]byte, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	expanded, err := c.expand(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand %s: %w", filename, err)
+	}
+	return []byte(expanded), nil
+}
+
+// expand replaces the template actions of content with the chart metadata and values they refer
+// to. Only comments and plain .Chart and .Values field chains of scalars are resolved; functions,
+// pipelines and .Release, which is only known at install time, are errors.
+func (c *helmChart) expand(content string) (string, error) {
+	var errs []error
+	expanded := helmAction.ReplaceAllStringFunc(content, func(action string) string {
+		expr := helmAction.FindStringSubmatch(action)[1]
+		if strings.HasPrefix(expr, "/*") && strings.HasSuffix(expr, "*/") {
+			return ""
+		}
+		value, err := c.resolve(expr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot resolve %s: %w", action, err))
+			return action
+		}
+		return value
+	})
+	return expanded, errors.Join(errs...)
+}
+
+// resolve returns the scalar a .Chart or .Values field chain refers to
+func (c *helmChart) resolve(expr string) (string, error) {
+	match := helmValuePath.FindStringSubmatch(expr)
+	if match == nil {
+		return "", fmt.Errorf("only .Chart and .Values fields are supported")
+	}
+
+	fields := strings.Split(strings.TrimPrefix(match[2], "."), ".")
+	var value interface{} = c.values
+	if match[1] == "Chart" {
+		// The fields of .Chart are the capitalized keys of Chart.yaml, e.g. .Chart.AppVersion
+		if len(fields) != 1 {
+			return "", fmt.Errorf(".Chart has no nested fields")
+		}
+		fields[0] = strings.ToLower(fields[0][:1]) + fields[0][1:]
+		value = c.metadata
+	}
+
+	for _, field := range fields {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("%s is not an object", expr)
+		}
+		if value, ok = object[field]; !ok {
+			return "", fmt.Errorf("%s is not set", expr)
+		}
+	}
+
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("%s is not a scalar", expr)
+	}
+}
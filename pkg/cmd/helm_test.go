@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHelmChartExpand(t *testing.T) {
+	chart, err := loadHelmChart("../../test/fixtures/helm/widgets")
+	require.NoError(t, err)
+	chart.values["replicas"] = float64(3)
+	chart.values["enabled"] = true
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr string
+	}{
+		{name: "chart field", content: "name: {{ .Chart.Name }}", want: "name: widgets"},
+		{name: "capitalized chart key", content: `version: "{{ .Chart.AppVersion }}"`, want: `version: "1.4.0"`},
+		{name: "nested value", content: "policy: {{- .Values.crds.resourcePolicy -}}", want: "policy: keep"},
+		{name: "number and bool", content: "{{ .Values.replicas }} {{ .Values.enabled }}", want: "3 true"},
+		{name: "comment", content: "a: 1{{/* dropped */}}", want: "a: 1"},
+		{name: "no actions", content: "a: 1", want: "a: 1"},
+		{name: "release", content: "{{ .Release.Name }}", wantErr: "cannot resolve {{ .Release.Name }}: only .Chart and .Values fields are supported"},
+		{name: "pipeline", content: "{{ .Chart.Name | quote }}", wantErr: "only .Chart and .Values fields are supported"},
+		{name: "unset value", content: "{{ .Values.crds.missing }}", wantErr: ".Values.crds.missing is not set"},
+		{name: "object value", content: "{{ .Values.crds }}", wantErr: ".Values.crds is not a scalar"},
+		{name: "nested chart field", content: "{{ .Chart.Name.First }}", wantErr: ".Chart has no nested fields"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := chart.expand(tt.content)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLoadHelmChart(t *testing.T) {
+	_, err := loadHelmChart("../../test/fixtures/mixed")
+	assert.ErrorContains(t, err, "../../test/fixtures/mixed is not a Helm chart")
+
+	// values.yaml is optional
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Chart.yaml"), []byte("apiVersion: v2\nname: bare\nversion: 0.1.0\n"), 0o644))
+	chart, err := loadHelmChart(dir)
+	require.NoError(t, err)
+	_, err = chart.crdDir()
+	assert.ErrorContains(t, err, "no crds/ directory in Helm chart")
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+var (
+	logFormat string
+	logLevel  string
+
+	// logger is the structured logger used for progress and diagnostic events. It discards all
+	// records until setupLogger configures it from the command line flags.
+	logger = slog.New(slog.DiscardHandler)
+)
+
+// setupLogger configures the package logger to write to out using the --log-format and
+// --log-level flags. Without an explicit level, --verbose enables debug output and warnings
+// are shown otherwise.
+func setupLogger(out io.Writer) error {
+	level, err := parseLogLevel(logLevel)
+	if err != nil {
+		return err
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(logFormat) {
+	case "", "text":
+		logger = slog.New(slog.NewTextHandler(out, options))
+	case "json":
+		logger = slog.New(slog.NewJSONHandler(out, options))
+	default:
+		return fmt.Errorf("invalid --log-format %q, valid formats are: text, json", logFormat)
+	}
+
+	return nil
+}
+
+// parseLogLevel converts the --log-level flag into a slog level
+func parseLogLevel(value string) (slog.Level, error) {
+	if value == "" {
+		if verbose {
+			return slog.LevelDebug, nil
+		}
+		return slog.LevelWarn, nil
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return 0, fmt.Errorf("invalid --log-level %q, valid levels are: debug, info, warn, error", value)
+	}
+	return level, nil
+}
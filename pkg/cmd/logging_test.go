@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/friedrichwilken/mcp-toolgen/pkg/analyzer"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// executeGenerate runs the root command with the given arguments and returns the log output
+func executeGenerate(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	reset := func() {
+		crdFile, crdDir, helmChartDir, outputDir, outputBase = "", "", "", "", ""
+		fromCluster, kubeconfig, crdGroup = false, "", ""
+		onCollision, aggregateScheme = collisionError, ""
+		buildTag, headerFile = "", ""
+		overwrite, overwriteMode = false, overwriteReplace
+		toolsetDescription, descriptionFile = "", ""
+		outputFormat = outputFormatText
+		dryRun, dryRunShowContent, verbose, emitGoGenerate, singleFile, prune = false, false, false, false, false, false
+		generateTests, generatePrompts, allowUnstructured, verifyCompiles, scope = false, false, false, false, ""
+		pluralName, singularName, groupOverride, toolPrefix = "", "", "", ""
+		packageName, crudOperations = "", "crud"
+		importPath, outputLayout, registerToolset, modulesFilePath = "", analyzer.OutputLayoutPkg, false, ""
+		clientQPS, clientBurst, maxDepth, descriptionMaxLen = 0, 0, 0, 0
+		handlerTimeout = analyzer.DefaultHandlerTimeout
+		schemaDraft, operationsFile, generateDocResource = "", "", ""
+		generateCRDResource = false
+		force, forceClean, alwaysWrite, withCache, withFind, readOnly = false, false, false, false, false, false
+		includePatterns, excludePatterns = nil, nil
+		logFormat, logLevel = "text", ""
+		logger = slog.New(slog.DiscardHandler)
+		cfgFile, configFileUsed, configErr = "", "", nil
+		viper.Reset()
+		rootCmd.Flags().VisitAll(func(flag *pflag.Flag) { flag.Changed = false })
+
+		// Slice flags append to their value once set, so they get a fresh value for every run
+		fresh := pflag.NewFlagSet("reset", pflag.ContinueOnError)
+		fresh.StringSliceVar(&excludeFields, "exclude-fields", nil, "")
+		rootCmd.Flags().Lookup("exclude-fields").Value = fresh.Lookup("exclude-fields").Value
+	}
+	reset()
+	t.Cleanup(reset)
+
+	var logs bytes.Buffer
+	rootCmd.SetErr(&logs)
+	rootCmd.SetArgs(args)
+	t.Cleanup(func() {
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+	})
+
+	err := rootCmd.Execute()
+	return logs.String(), err
+}
+
+func TestJSONLogging(t *testing.T) {
+	logs, err := executeGenerate(t,
+		"--crd", "../../test/fixtures/simple-crd.yaml",
+		"--output", t.TempDir(),
+		"--module-path", "github.com/test/module",
+		"--log-format", "json",
+		"--log-level", "info",
+	)
+	require.NoError(t, err)
+
+	var generated []string
+	var parsed bool
+	for _, line := range strings.Split(strings.TrimSpace(logs), "\n") {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record), "log line is not JSON: %s", line)
+
+		switch record["msg"] {
+		case "parsed CRD":
+			parsed = true
+			assert.Equal(t, "Widget", record["kind"])
+		case "generated file":
+			assert.Equal(t, "INFO", record["level"])
+			generated = append(generated, record["filename"].(string))
+		}
+	}
+
+	assert.True(t, parsed, "expected a parsed CRD event")
+	assert.Contains(t, generated, "types.go")
+	assert.Len(t, generated, 9)
+}
+
+func TestInvalidLogFlags(t *testing.T) {
+	_, err := executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module", "--log-format", "xml")
+	assert.ErrorContains(t, err, `invalid --log-format "xml"`)
+
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module", "--log-level", "loud")
+	assert.ErrorContains(t, err, `invalid --log-level "loud"`)
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/friedrichwilken/mcp-toolgen/pkg/generator"
+)
+
+// Values accepted by the --output-format flag
+const (
+	outputFormatText = "text"
+	outputFormatJSON = "json"
+)
+
+// dryRunPlan is what --dry-run --output-format json prints: the plan of every toolset the run
+// would generate, for orchestration tooling to consume
+type dryRunPlan struct {
+	Toolsets        []*generator.ToolsetPlan `json:"toolsets"`
+	AggregateScheme string                   `json:"aggregateScheme,omitempty"` // Path of the --aggregate-scheme file
+}
+
+// plannedToolsets collects the plans of a JSON dry run, which are printed together once all
+// toolsets are planned
+var plannedToolsets dryRunPlan
+
+// printDryRunPlan writes the collected plan to stdout as indented JSON
+func printDryRunPlan() error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(plannedToolsets); err != nil {
+		return fmt.Errorf("failed to print dry run plan: %w", err)
+	}
+	return nil
+}
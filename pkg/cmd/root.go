@@ -3,12 +3,24 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"golang.org/x/mod/module"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/friedrichwilken/mcp-toolgen/pkg/analyzer"
 	"github.com/friedrichwilken/mcp-toolgen/pkg/generator"
@@ -18,21 +30,86 @@ var (
 	cfgFile             string
 	verbose             bool
 	dryRun              bool
+	dryRunShowContent   bool
+	outputFormat        string
+	showDiff            bool
 	overwrite           bool
 	crudOperations      string
 	crdFile             string
 	crdDir              string
+	helmChartDir        string
+	fromCluster         bool
+	kubeconfig          string
+	crdGroup            string
 	outputDir           string
 	outputBase          string
 	packageName         string
 	modulePath          string
+	importPath          string
+	outputLayout        string
 	templateDir         string
 	registerToolset     bool
 	modulesFilePath     string
 	generateCRDResource bool
+	generateTests       bool
+	generatePrompts     bool
 	generateDocResource string
+	excludeFields       []string
+	keepExcludedInTypes bool
+	onCollision         string
+	defaultNamespace    string
+	namespaceAll        bool
+	useShortNames       bool
+	aggregateScheme     string
+	buildTag            string
+	headerFile          string
+	toolsetDescription  string
+	descriptionFile     string
+	emitGoGenerate      bool
+	singleFile          bool
+	prune               bool
+	force               bool
+	forceClean          bool
+	clientQPS           float32
+	clientBurst         int
+	schemaDraft         string
+	descriptionMaxLen   int
+	includePatterns     []string
+	excludePatterns     []string
+	scope               string
+	pluralName          string
+	singularName        string
+	groupOverride       string
+	allowUnstructured   bool
+	verifyCompiles      bool
+	toolPrefix          string
+	alwaysWrite         bool
+	withCache           bool
+	withFind            bool
+	readOnly            bool
+	maxDepth            int
+	handlerTimeout      time.Duration
+	operationsFile      string
+	overwriteMode       string
+	configFileUsed      string
+	configErr           error
 )
 
+// Values accepted by the --overwrite-mode flag
+const (
+	overwriteReplace = "replace"
+	overwriteMerge   = "merge"
+)
+
+// resourceNamePattern matches the plural and singular names Kubernetes accepts for a CRD
+var resourceNamePattern = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+
+// Values accepted by the --scope flag, mapped to the CRD scope they select
+var scopeValues = map[string]apiextensionsv1.ResourceScope{
+	"namespaced": apiextensionsv1.NamespaceScoped,
+	"cluster":    apiextensionsv1.ClusterScoped,
+}
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "mcp-toolgen",
@@ -49,6 +126,12 @@ with the extendable-kubernetes-mcp-server architecture.`,
   # Generate toolsets from a directory of CRDs
   mcp-toolgen --crd-dir ./crds --output-base ./pkg
 
+  # Generate toolsets from the crds/ directory of a Helm chart
+  mcp-toolgen --helm-chart ./charts/widgets --output-base ./pkg
+
+  # Generate toolsets for the CRDs of a group installed in the current cluster
+  mcp-toolgen --from-cluster --group example.com --output-base ./pkg
+
   # Generate with custom module path
   mcp-toolgen --crd ./crds/function-crd.yaml --output ./pkg/functions --module-path github.com/myorg/myproject
 
@@ -57,8 +140,20 @@ with the extendable-kubernetes-mcp-server architecture.`,
 
   # Generate only delete operations
   mcp-toolgen --crud d --crd ./crds/function-crd.yaml --output ./pkg/functions`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if configErr != nil {
+			return configErr
+		}
+		if err := setupLogger(cmd.ErrOrStderr()); err != nil {
+			return err
+		}
+		if configFileUsed != "" {
+			logger.Debug("using config file", "path", configFileUsed)
+		}
+		return nil
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runGenerate()
+		return runGenerate(cmd.Flags())
 	},
 }
 
@@ -72,32 +167,134 @@ func init() {
 
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.mcp-toolgen.yaml)")
-	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output (same as --log-level debug)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "log output format (text or json)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "log level (debug, info, warn, error; defaults to warn, or debug with --verbose)")
 	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "print what would be generated without creating files")
+	rootCmd.PersistentFlags().BoolVar(&dryRunShowContent, "dry-run-show-content", false,
+		"with --dry-run, print the full content of every generated file (also enabled by --verbose)")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output-format", outputFormatText,
+		"format of what --dry-run prints: text, or json for a plan with the CRD, files, operations, tool names and import path of every toolset")
 
 	// Input flags
 	rootCmd.Flags().StringVar(&crdFile, "crd", "", "path to CRD YAML file")
 	rootCmd.Flags().StringVar(&crdDir, "crd-dir", "", "directory containing CRD YAML files")
+	rootCmd.Flags().StringVar(&helmChartDir, "helm-chart", "",
+		"Helm chart directory to generate from the CRDs of its crds/ directory (.Chart and .Values references are expanded)")
+	rootCmd.Flags().BoolVar(&fromCluster, "from-cluster", false, "generate from the CRDs installed in the cluster of the kubeconfig")
+	rootCmd.Flags().StringVar(&kubeconfig, "kubeconfig", "",
+		"with --from-cluster, path to the kubeconfig (defaults to $KUBECONFIG or ~/.kube/config)")
+	rootCmd.Flags().StringVar(&crdGroup, "group", "", "with --from-cluster, only generate for the CRDs of this API group")
 
 	// Output flags
 	rootCmd.Flags().StringVar(&outputDir, "output", "", "output directory for generated code")
+	rootCmd.Flags().StringSliceVar(&includePatterns, "include", nil,
+		"with --crd-dir, only process files matching these glob patterns (relative to --crd-dir; patterns without a / also match file names)")
+	rootCmd.Flags().StringSliceVar(&excludePatterns, "exclude", nil,
+		"with --crd-dir, skip files matching these glob patterns, matched like --include")
 	rootCmd.Flags().StringVar(&outputBase, "output-base", "", "base directory for multi-CRD generation (creates subdirectories)")
 	rootCmd.Flags().BoolVar(&overwrite, "overwrite", false, "overwrite existing files")
+	rootCmd.Flags().StringVar(&overwriteMode, "overwrite-mode", overwriteReplace,
+		"how --overwrite treats existing files: replace them, or merge to keep the content of their mcp-toolgen:begin-custom regions")
+	rootCmd.Flags().BoolVar(&showDiff, "diff", false, "print a unified diff against existing files instead of writing them")
 
 	// Generation flags
 	rootCmd.Flags().StringVar(&packageName, "package", "", "Go package name (defaults to CRD plural name)")
 	rootCmd.Flags().StringVar(&modulePath, "module-path", "github.com/example/project", "Go module path")
+	rootCmd.Flags().StringVar(&outputLayout, "output-layout", analyzer.OutputLayoutPkg,
+		"where toolset packages sit in the module, for their import path and the modules.go --register infers: pkg (<module>/pkg/<package>) or flat (<module>/<package>)")
+	rootCmd.Flags().StringVar(&importPath, "import-path", "",
+		"with --crd, import path of the generated package, replacing the one --module-path and --output-layout imply (e.g. for monorepos)")
 	rootCmd.Flags().StringVar(&templateDir, "templates", "", "custom template directory (optional)")
 	rootCmd.Flags().StringVar(&crudOperations, "crud", "crud", "CRUD operations to generate (c=create, r=read, u=update, d=delete)")
 	rootCmd.Flags().BoolVar(&generateCRDResource, "generate-crd-resource", false,
 		"generate MCP resource for CRD definition (requires ek8sms with resource support)")
+	rootCmd.Flags().BoolVar(&generatePrompts, "generate-example-prompts", false,
+		"generate a prompts.go with an MCP prompt per tool showing example arguments built from the schema")
+	rootCmd.Flags().BoolVar(&generateTests, "generate-tests", false,
+		"generate a handlers_test.go that runs each operation against a fake controller-runtime client")
 	rootCmd.Flags().StringVar(&generateDocResource, "generate-doc-resource", "",
 		"generate MCP resource for documentation (file path or URL, e.g., ./docs.md or https://raw.githubusercontent.com/...)")
+	rootCmd.Flags().StringSliceVar(&excludeFields, "exclude-fields", nil,
+		"comma-separated dot-paths of fields to leave out of the generated schemas and types (e.g. spec.token,spec.credentials.password)")
+	rootCmd.Flags().BoolVar(&keepExcludedInTypes, "keep-excluded-in-types", false,
+		"keep fields listed in --exclude-fields in the generated Go types")
+	rootCmd.Flags().IntVar(&maxDepth, "max-depth", 0,
+		"levels of fields below spec and status that get their own Go types; deeper objects become map[string]interface{} (0 for no limit)")
+	rootCmd.Flags().StringVar(&defaultNamespace, "default-namespace", "",
+		"namespace generated handlers use when the namespace argument is omitted (ignored for cluster-scoped CRDs)")
+	rootCmd.Flags().BoolVar(&namespaceAll, "namespace-all", false,
+		"add an allNamespaces argument to the generated list tool to list across all namespaces (ignored for cluster-scoped CRDs)")
+	rootCmd.Flags().BoolVar(&useShortNames, "use-short-names", false,
+		"also register every tool under the short names of the CRD, e.g. wgt_get next to widgets_get")
+	rootCmd.Flags().StringVar(&buildTag, "build-tag", "",
+		"build constraint to guard every generated file with, e.g. mytools (adds a //go:build line)")
+	rootCmd.Flags().StringVar(&headerFile, "header-file", "",
+		"text file prepended as a comment to every generated file, e.g. a license header ({{.Year}} is replaced with the current year)")
+	rootCmd.Flags().StringVar(&toolsetDescription, "toolset-description", "",
+		"description of the generated toolset, replacing the one taken from the CRD (e.g. guidance for the LLM)")
+	rootCmd.Flags().StringVar(&descriptionFile, "description-file", "",
+		"text file with the description of the generated toolset, like --toolset-description")
+	rootCmd.Flags().BoolVar(&emitGoGenerate, "emit-gogenerate", false,
+		"write a generate.go with a go:generate directive that reruns this generation, so go generate can rebuild the toolset")
+	rootCmd.Flags().BoolVar(&singleFile, "single-file", false,
+		"generate the toolset into one <package>.go file instead of a file per concern, without doc.go")
+	rootCmd.Flags().BoolVar(&prune, "prune", false,
+		"remove generated .go files of the output directory that are no longer generated, e.g. after dropping operations or options")
+	rootCmd.Flags().BoolVar(&alwaysWrite, "always-write", false,
+		"also write generated files whose content is unchanged, instead of leaving them and their modification time untouched")
+	rootCmd.Flags().BoolVar(&force, "force", false,
+		"remove and recreate the output directory before generating, for a clean slate after restructuring a CRD; refused if it holds files mcp-toolgen did not write")
+	rootCmd.Flags().BoolVar(&forceClean, "force-clean", false,
+		"with --force, also remove an output directory that holds files mcp-toolgen did not write")
+	rootCmd.Flags().DurationVar(&handlerTimeout, "handler-timeout", analyzer.DefaultHandlerTimeout,
+		"timeout of the Kubernetes API calls of a generated tool call, after which it fails with a timeout error (0 for none)")
+	rootCmd.Flags().Float32Var(&clientQPS, "client-qps", 0,
+		"queries per second of the generated clients created from a REST config (0 keeps the client-go default)")
+	rootCmd.Flags().IntVar(&clientBurst, "client-burst", 0,
+		"request burst of the generated clients created from a REST config (0 keeps the client-go default)")
+	rootCmd.Flags().StringVar(&schemaDraft, "schema-draft", "",
+		"JSON Schema draft (2020-12 or draft-07) the tool schemas declare with $schema (default none, which the go-sdk reads as 2020-12)")
+	rootCmd.Flags().IntVar(&descriptionMaxLen, "description-max-length", 0,
+		"truncate CRD descriptions in the tool schemas and field comments to this many characters, ending them with an ellipsis (0 for no limit)")
+	rootCmd.Flags().StringVar(&scope, "scope", "",
+		"generate for this scope (namespaced or cluster) instead of the one the CRD declares, e.g. for experiments")
+	rootCmd.Flags().StringVar(&pluralName, "plural", "",
+		"with --crd, plural the package, toolset and tools are named after instead of the one of the CRD, e.g. for irregular plurals")
+	rootCmd.Flags().StringVar(&singularName, "singular", "",
+		"with --crd, singular the generated methods are named after instead of the one of the CRD")
+	rootCmd.Flags().StringVar(&groupOverride, "group-override", "",
+		"API group the generated code targets instead of the one the CRD declares, e.g. for a fork of the CRD installed under a renamed group")
+	rootCmd.Flags().StringVar(&toolPrefix, "tool-prefix", "",
+		"prefix of every generated tool name, e.g. acme for acme_widgets_get, to avoid collisions between CRDs with the same plural")
+	rootCmd.Flags().StringVar(&operationsFile, "operations-file", "",
+		"with --crd, YAML file declaring custom operations, e.g. pause, each generated as a tool that applies a patch to the named resource")
+	rootCmd.Flags().BoolVar(&withCache, "with-cache", false,
+		"also generate a client whose Get and List read from an informer cache, with a Start method that runs and syncs it")
+	rootCmd.Flags().BoolVar(&withFind, "with-find", false,
+		"also generate a <resource>_find tool that returns the one resource matching a label selector (with get selected)")
+	rootCmd.Flags().BoolVar(&readOnly, "readonly", false,
+		"generate only the operations that do not change resources, like --crud r, with every tool annotated as read-only")
+	rootCmd.Flags().BoolVar(&allowUnstructured, "allow-unstructured", false,
+		"generate an unstructured toolset for CRDs without a structural schema instead of failing")
+	rootCmd.Flags().BoolVar(&verifyCompiles, "verify", false,
+		"type-check the generated package after writing it and fail if it does not compile (slow; imports are resolved from the module of the output directory)")
+	rootCmd.Flags().StringVar(&aggregateScheme, "aggregate-scheme", "",
+		"with --crd-dir or --from-cluster, write a Go file at this path with an AddAllToScheme function covering every generated toolset")
+	rootCmd.Flags().StringVar(&onCollision, "on-collision", collisionError,
+		"how to handle CRDs of --crd-dir or --from-cluster that map to the same package (error or group-prefix)")
 
 	// Registration flags
 	rootCmd.Flags().BoolVar(&registerToolset, "register", false, "automatically add import to modules.go after generation")
 	rootCmd.Flags().StringVar(&modulesFilePath, "modules-file", "", "path to modules.go file (defaults to <target-repo>/pkg/mcp/modules.go)")
 
+	markCRDFlagCompletion(rootCmd)
+	_ = rootCmd.MarkFlagFilename("kubeconfig")
+
+	// Let the config file provide defaults for every generation flag
+	_ = viper.BindPFlags(rootCmd.Flags()) // Error only if the flag set is nil (programming error)
+	_ = viper.BindPFlags(rootCmd.PersistentFlags())
+
 	// Mark required flags
 	_ = rootCmd.MarkFlagRequired("module-path") // Error only if flag doesn't exist (programming error)
 }
@@ -117,39 +314,94 @@ func initConfig() {
 
 	viper.AutomaticEnv()
 
-	if err := viper.ReadInConfig(); err == nil && verbose {
-		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+	if err := viper.ReadInConfig(); err == nil {
+		configFileUsed = viper.ConfigFileUsed()
 	}
+
+	// Flags are applied here rather than in PersistentPreRunE so that values from the config file
+	// count for required flags like --module-path, which cobra checks before running any hook
+	configErr = applyConfigValues(rootCmd.Flags())
+}
+
+// applyConfigValues sets every flag that was not given on the command line to its value in the
+// config file, if the config file has one
+func applyConfigValues(flags *pflag.FlagSet) error {
+	var errs []error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if flag.Changed || flag.Name == "config" || !viper.InConfig(flag.Name) {
+			return
+		}
+
+		var err error
+		if value, ok := flag.Value.(pflag.SliceValue); ok {
+			err = value.Replace(viper.GetStringSlice(flag.Name))
+			flag.Changed = true
+		} else {
+			err = flags.Set(flag.Name, viper.GetString(flag.Name))
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid value for %q in config file %s: %w", flag.Name, viper.ConfigFileUsed(), err))
+		}
+	})
+	return errors.Join(errs...)
 }
 
 // runGenerate executes the main generation logic
-func runGenerate() error {
+func runGenerate(flags *pflag.FlagSet) error {
 	// Validate input flags
-	if err := validateFlags(); err != nil {
+	if err := validateFlags(flags); err != nil {
 		return err
 	}
 
+	if outputFormat == outputFormatJSON {
+		// The toolsets add their plans while they are generated
+		plannedToolsets = dryRunPlan{Toolsets: []*generator.ToolsetPlan{}}
+		if err := generate(); err != nil {
+			return err
+		}
+		return printDryRunPlan()
+	}
+
+	return generate()
+}
+
+// generate generates the toolsets of the CRD source the flags select
+func generate() error {
 	if crdFile != "" {
 		// Generate from single CRD
 		return generateFromSingleCRD()
 	} else if crdDir != "" {
 		// Generate from directory of CRDs
 		return generateFromDirectory()
+	} else if helmChartDir != "" {
+		// Generate from the crds/ directory of a Helm chart
+		return generateFromHelmChart()
+	} else if fromCluster {
+		// Generate from the CRDs installed in a cluster
+		return generateFromCluster()
 	}
 
-	return fmt.Errorf("either --crd or --crd-dir must be specified")
+	return fmt.Errorf("one of --crd, --crd-dir, --helm-chart or --from-cluster must be specified")
 }
 
-// validateFlags validates the command line flags
-func validateFlags() error {
-	if crdFile == "" && crdDir == "" {
-		return fmt.Errorf("either --crd or --crd-dir must be specified")
+// validateFlags validates the command line flags; flags tells which of them were set
+func validateFlags(flags *pflag.FlagSet) error {
+	if crdFile == "" && crdDir == "" && helmChartDir == "" && !fromCluster {
+		return fmt.Errorf("one of --crd, --crd-dir, --helm-chart or --from-cluster must be specified")
 	}
 
 	if crdFile != "" && crdDir != "" {
 		return fmt.Errorf("--crd and --crd-dir are mutually exclusive")
 	}
 
+	if fromCluster && (crdFile != "" || crdDir != "") {
+		return fmt.Errorf("--from-cluster is mutually exclusive with --crd and --crd-dir")
+	}
+
+	if helmChartDir != "" && (crdFile != "" || crdDir != "" || fromCluster) {
+		return fmt.Errorf("--helm-chart is mutually exclusive with --crd, --crd-dir and --from-cluster")
+	}
+
 	if crdFile != "" && outputDir == "" {
 		return fmt.Errorf("--output is required when using --crd")
 	}
@@ -158,15 +410,157 @@ func validateFlags() error {
 		return fmt.Errorf("--output-base is required when using --crd-dir")
 	}
 
+	if helmChartDir != "" && outputBase == "" {
+		return fmt.Errorf("--output-base is required when using --helm-chart")
+	}
+
+	if fromCluster && outputBase == "" {
+		return fmt.Errorf("--output-base is required when using --from-cluster")
+	}
+
+	if (kubeconfig != "" || crdGroup != "") && !fromCluster {
+		return fmt.Errorf("--kubeconfig and --group require --from-cluster")
+	}
+
+	if fromCluster && emitGoGenerate {
+		return fmt.Errorf("--emit-gogenerate is not supported with --from-cluster, as there is no CRD file to regenerate from")
+	}
+
+	if helmChartDir != "" && emitGoGenerate {
+		return fmt.Errorf("--emit-gogenerate is not supported with --helm-chart, as the CRD files are expanded before generating")
+	}
+
+	if outputFormat != outputFormatText && outputFormat != outputFormatJSON {
+		return fmt.Errorf("invalid --output-format %q, valid values are: %s, %s", outputFormat, outputFormatText, outputFormatJSON)
+	}
+
+	if outputFormat == outputFormatJSON && (!dryRun || dryRunShowContent || showDiff) {
+		return fmt.Errorf("--output-format json requires --dry-run and cannot be combined with --dry-run-show-content or --diff")
+	}
+
+	if toolsetDescription != "" && descriptionFile != "" {
+		return fmt.Errorf("--toolset-description and --description-file are mutually exclusive")
+	}
+
+	if aggregateScheme != "" && crdDir == "" && helmChartDir == "" && !fromCluster {
+		return fmt.Errorf("--aggregate-scheme requires --crd-dir, --helm-chart or --from-cluster")
+	}
+
 	if modulePath == "" {
 		return fmt.Errorf("--module-path is required")
 	}
 
+	if outputLayout != analyzer.OutputLayoutPkg && outputLayout != analyzer.OutputLayoutFlat {
+		return fmt.Errorf("invalid --output-layout %q, valid values are: %s, %s", outputLayout, analyzer.OutputLayoutPkg, analyzer.OutputLayoutFlat)
+	}
+
+	if importPath != "" && crdFile == "" {
+		return fmt.Errorf("--import-path requires --crd, as it is the import path of one package")
+	}
+
+	if err := module.CheckImportPath(importPath); importPath != "" && err != nil {
+		return fmt.Errorf("invalid --import-path: %w", err)
+	}
+
 	// Validate CRUD operations
 	if err := validateCRUDOperations(crudOperations); err != nil {
 		return fmt.Errorf("invalid --crud flag: %w", err)
 	}
 
+	if readOnly && flags.Changed("crud") && strings.ContainsAny(crudOperations, "cud") {
+		return fmt.Errorf("--readonly cannot be combined with --crud %s, which selects write operations", crudOperations)
+	}
+
+	if readOnly && operationsFile != "" {
+		return fmt.Errorf("--readonly cannot be combined with --operations-file, as custom operations patch resources")
+	}
+
+	if err := validateCollisionStrategy(onCollision); err != nil {
+		return err
+	}
+
+	if (len(includePatterns) > 0 || len(excludePatterns) > 0) && crdDir == "" && helmChartDir == "" {
+		return fmt.Errorf("--include and --exclude require --crd-dir or --helm-chart")
+	}
+
+	for _, pattern := range append(slices.Clone(includePatterns), excludePatterns...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	if overwriteMode != overwriteReplace && overwriteMode != overwriteMerge {
+		return fmt.Errorf("invalid --overwrite-mode %q, valid values are: %s, %s", overwriteMode, overwriteReplace, overwriteMerge)
+	}
+
+	if forceClean && !force {
+		return fmt.Errorf("--force-clean requires --force")
+	}
+
+	if force && overwriteMode == overwriteMerge {
+		return fmt.Errorf("--force cannot be combined with --overwrite-mode merge, the custom regions would be removed with the output directory")
+	}
+
+	if _, ok := scopeValues[scope]; scope != "" && !ok {
+		return fmt.Errorf("invalid --scope %q, valid values are: namespaced, cluster", scope)
+	}
+
+	if (pluralName != "" || singularName != "") && crdFile == "" {
+		return fmt.Errorf("--plural and --singular require --crd")
+	}
+
+	if operationsFile != "" && crdFile == "" {
+		return fmt.Errorf("--operations-file requires --crd, as the patches of the operations are specific to one CRD")
+	}
+
+	if err := validateResourceName("plural", pluralName); err != nil {
+		return err
+	}
+
+	if err := validateResourceName("singular", singularName); err != nil {
+		return err
+	}
+
+	if err := validateResourceName("tool-prefix", toolPrefix); err != nil {
+		return err
+	}
+
+	if errs := validation.IsDNS1123Subdomain(groupOverride); groupOverride != "" && len(errs) > 0 {
+		return fmt.Errorf("invalid --group-override %q: %s", groupOverride, strings.Join(errs, "; "))
+	}
+
+	if maxDepth < 0 {
+		return fmt.Errorf("--max-depth must not be negative")
+	}
+
+	if handlerTimeout < 0 {
+		return fmt.Errorf("--handler-timeout must not be negative")
+	}
+
+	if clientQPS < 0 {
+		return fmt.Errorf("--client-qps must not be negative")
+	}
+
+	if clientBurst < 0 {
+		return fmt.Errorf("--client-burst must not be negative")
+	}
+
+	if _, ok := generator.SchemaDrafts[schemaDraft]; schemaDraft != "" && !ok {
+		return fmt.Errorf("invalid --schema-draft %q, valid values are: 2020-12, draft-07", schemaDraft)
+	}
+
+	if descriptionMaxLen < 0 {
+		return fmt.Errorf("--description-max-length must not be negative")
+	}
+
+	return nil
+}
+
+// validateResourceName validates the value of the --plural or --singular flag
+func validateResourceName(flag, name string) error {
+	if name != "" && !resourceNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid --%s %q, must be lowercase letters and digits starting with a letter", flag, name)
+	}
 	return nil
 }
 
@@ -230,10 +624,7 @@ func parseCRUDOperations(crud string) []string {
 
 // generateFromSingleCRD generates code from a single CRD file
 func generateFromSingleCRD() error {
-	if verbose {
-		fmt.Printf("Generating toolset from CRD: %s\n", crdFile)
-		fmt.Printf("Output directory: %s\n", outputDir)
-	}
+	logger.Debug("generating toolset", "crd", crdFile, "output", outputDir)
 
 	// Parse CRD
 	crdAnalyzer := analyzer.NewCRDAnalyzer()
@@ -242,23 +633,17 @@ func generateFromSingleCRD() error {
 		return fmt.Errorf("failed to parse CRD file %s: %w", crdFile, err)
 	}
 
-	if verbose {
-		fmt.Printf("Parsed CRD: %s (%s)\n", crdInfo.Kind, crdInfo.GetAPIVersion())
-	}
+	logger.Info("parsed CRD", "crd", crdFile, "kind", crdInfo.Kind, "apiVersion", crdInfo.GetAPIVersion())
 
 	// Load documentation if requested
 	if generateDocResource != "" {
-		if verbose {
-			fmt.Printf("Loading documentation from: %s\n", generateDocResource)
-		}
 		docContent, err := analyzer.LoadDocumentationContent(generateDocResource)
 		if err != nil {
 			return fmt.Errorf("failed to load documentation: %w", err)
 		}
 		crdInfo.DocContent = docContent
-		if verbose {
-			fmt.Printf("Loaded documentation: %d bytes\n", len(docContent))
-		}
+		crdInfo.DocMimeType = analyzer.DocumentationMimeType(generateDocResource)
+		logger.Debug("loaded documentation", "source", generateDocResource, "bytes", len(docContent))
 	}
 
 	// Create generation config
@@ -266,20 +651,43 @@ func generateFromSingleCRD() error {
 	config.PackageName = packageName
 	config.ModulePath = modulePath
 	config.OutputDir = outputDir
+	config.OutputLayout = outputLayout
+	config.ImportPath = importPath
 	config.TemplateDir = templateDir
 	config.SelectedOperations = parseCRUDOperations(crudOperations)
 	config.GenerateCRDResource = generateCRDResource
+	config.GenerateTests = generateTests
+	config.GeneratePrompts = generatePrompts
 	config.GenerateDocResource = generateDocResource != ""
 	config.DocResourcePath = generateDocResource
-
-	if config.PackageName == "" {
-		config.PackageName = crdInfo.GetPackageName()
+	config.ExcludedFields = excludeFields
+	config.KeepExcludedFieldsInTypes = keepExcludedInTypes
+	config.MaxDepth = maxDepth
+	config.HandlerTimeout = handlerTimeout
+	config.DefaultNamespace = defaultNamespace
+	config.AllNamespacesList = namespaceAll
+	config.ShortNameTools = useShortNames
+	config.Scope = scopeValues[scope]
+	config.Plural = pluralName
+	config.Singular = singularName
+	config.Group = groupOverride
+	config.AllowUnstructured = allowUnstructured
+	config.ToolPrefix = toolPrefix
+	config.WithCache = withCache
+	config.FindTool = withFind
+	config.ReadOnly = readOnly
+	if config.ToolsetDescription, err = loadToolsetDescription(); err != nil {
+		return err
 	}
-
-	if verbose {
-		fmt.Printf("Selected CRUD operations: %v\n", config.SelectedOperations)
+	if operationsFile != "" {
+		if config.CustomOperations, err = analyzer.LoadCustomOperations(operationsFile); err != nil {
+			return err
+		}
+		logger.Debug("loaded custom operations", "file", operationsFile, "count", len(config.CustomOperations))
 	}
 
+	logger.Debug("selected CRUD operations", "operations", config.SelectedOperations)
+
 	// Create toolset info
 	toolsetInfo, err := analyzer.NewToolsetInfo(crdInfo, config)
 	if err != nil {
@@ -287,18 +695,32 @@ func generateFromSingleCRD() error {
 	}
 
 	// Generate code
-	return generateToolset(toolsetInfo, outputDir)
+	return generateToolset(toolsetInfo, crdFile, outputDir)
+}
+
+// directorySummary counts the outcome of the CRDs processed by directory or cluster generation
+type directorySummary struct {
+	generated int // CRDs a toolset was generated for
+	skipped   int // Manifests of other kinds than CRD
+	failed    int // CRDs that could not be parsed or generated
+}
+
+// log reports the summary for the source the CRDs came from, as a warning if any CRD failed
+func (s directorySummary) log(source string) {
+	level := slog.LevelInfo
+	if s.failed > 0 {
+		level = slog.LevelWarn
+	}
+	logger.Log(context.Background(), level, "generated toolsets from "+source,
+		"generated", s.generated, "skipped", s.skipped, "failed", s.failed)
 }
 
 // generateFromDirectory generates code from all CRD files in a directory
 func generateFromDirectory() error {
-	if verbose {
-		fmt.Printf("Generating toolsets from directory: %s\n", crdDir)
-		fmt.Printf("Output base directory: %s\n", outputBase)
-	}
+	logger.Debug("generating toolsets from directory", "crdDir", crdDir, "outputBase", outputBase)
 
 	// Find all CRD files
-	crdFiles, err := findCRDFiles(crdDir)
+	crdFiles, err := findCRDFiles(crdDir, includePatterns, excludePatterns)
 	if err != nil {
 		return fmt.Errorf("failed to find CRD files: %w", err)
 	}
@@ -307,42 +729,110 @@ func generateFromDirectory() error {
 		return fmt.Errorf("no CRD files found in directory %s", crdDir)
 	}
 
-	if verbose {
-		fmt.Printf("Found %d CRD files\n", len(crdFiles))
+	logger.Debug("found CRD files", "count", len(crdFiles))
+
+	crds, summary, err := parseCRDFiles(crdFiles, os.ReadFile)
+	if err != nil {
+		return err
+	}
+	return generateFromParsedCRDs(crds, summary, "directory")
+}
+
+// generateFromHelmChart generates code from the CRD files in the crds/ directory of a Helm chart
+func generateFromHelmChart() error {
+	logger.Debug("generating toolsets from Helm chart", "chart", helmChartDir, "outputBase", outputBase)
+
+	chart, err := loadHelmChart(helmChartDir)
+	if err != nil {
+		return err
+	}
+	dir, err := chart.crdDir()
+	if err != nil {
+		return err
 	}
 
-	// Generate toolset for each CRD
+	crdFiles, err := findCRDFiles(dir, includePatterns, excludePatterns)
+	if err != nil {
+		return fmt.Errorf("failed to find CRD files: %w", err)
+	}
+
+	if len(crdFiles) == 0 {
+		return fmt.Errorf("no CRD files found in directory %s", dir)
+	}
+
+	logger.Debug("found CRD files", "count", len(crdFiles))
+
+	crds, summary, err := parseCRDFiles(crdFiles, chart.readCRDFile)
+	if err != nil {
+		return err
+	}
+	return generateFromParsedCRDs(crds, summary, "Helm chart")
+}
+
+// parseCRDFiles parses the CRDs of a batch of files, reading their content with read. All CRDs are
+// parsed first so package name collisions can be detected across the batch. Files that are not
+// CRDs are skipped and CRDs that fail to parse are counted as failed, while a file that cannot be
+// read fails the batch.
+func parseCRDFiles(crdFiles []string, read func(string) ([]byte, error)) ([]parsedCRD, directorySummary, error) {
 	crdAnalyzer := analyzer.NewCRDAnalyzer()
+	var crds []parsedCRD
+	var summary directorySummary
 	for _, crdFile := range crdFiles {
-		if verbose {
-			fmt.Printf("Processing %s...\n", crdFile)
+		data, err := read(crdFile)
+		if err != nil {
+			return nil, summary, fmt.Errorf("failed to read CRD file: %w", err)
+		}
+		crdInfo, err := crdAnalyzer.ParseCRDFromYAML(data)
+		if errors.Is(err, analyzer.ErrNotCRD) {
+			// Manifests of other kinds commonly live next to CRDs
+			logger.Debug("skipping file that is not a CRD", "file", crdFile, "error", err)
+			summary.skipped++
+			continue
 		}
-
-		// Parse CRD
-		crdInfo, err := crdAnalyzer.ParseCRDFromFile(crdFile)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to parse %s: %v\n", crdFile, err)
+			logger.Warn("failed to parse CRD", "crd", crdFile, "error", err)
+			summary.failed++
 			continue
 		}
+		logger.Info("parsed CRD", "crd", crdFile, "kind", crdInfo.Kind, "apiVersion", crdInfo.GetAPIVersion())
+		crds = append(crds, parsedCRD{path: crdFile, info: crdInfo})
+	}
+	return crds, summary, nil
+}
+
+// generateFromParsedCRDs generates a toolset for each CRD into its own package below --output-base
+// and logs the summary of the batch
+func generateFromParsedCRDs(crds []parsedCRD, summary directorySummary, source string) error {
+	packageNames, err := resolvePackageNames(crds, onCollision)
+	if err != nil {
+		return err
+	}
+
+	description, err := loadToolsetDescription()
+	if err != nil {
+		return err
+	}
+
+	// Generate toolset for each CRD, collecting the import paths of the generated packages
+	var importPaths []string
+	for i, crd := range crds {
+		crdFile, crdInfo := crd.path, crd.info
 
 		// Load documentation if requested
 		if generateDocResource != "" {
-			if verbose {
-				fmt.Printf("Loading documentation from: %s\n", generateDocResource)
-			}
 			docContent, err := analyzer.LoadDocumentationContent(generateDocResource)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to load documentation for %s: %v\n", crdFile, err)
+				logger.Warn("failed to load documentation", "crd", crdFile, "source", generateDocResource, "error", err)
+				summary.failed++
 				continue
 			}
 			crdInfo.DocContent = docContent
-			if verbose {
-				fmt.Printf("Loaded documentation: %d bytes\n", len(docContent))
-			}
+			crdInfo.DocMimeType = analyzer.DocumentationMimeType(generateDocResource)
+			logger.Debug("loaded documentation", "source", generateDocResource, "bytes", len(docContent))
 		}
 
 		// Create output directory for this CRD
-		packageName := crdInfo.GetPackageName()
+		packageName := packageNames[i]
 		crdOutputDir := filepath.Join(outputBase, packageName)
 
 		// Create generation config
@@ -350,35 +840,111 @@ func generateFromDirectory() error {
 		config.PackageName = packageName
 		config.ModulePath = modulePath
 		config.OutputDir = crdOutputDir
+		config.OutputLayout = outputLayout
 		config.TemplateDir = templateDir
 		config.SelectedOperations = parseCRUDOperations(crudOperations)
 		config.GenerateCRDResource = generateCRDResource
+		config.GenerateTests = generateTests
+		config.GeneratePrompts = generatePrompts
 		config.GenerateDocResource = generateDocResource != ""
 		config.DocResourcePath = generateDocResource
+		config.ExcludedFields = excludeFields
+		config.KeepExcludedFieldsInTypes = keepExcludedInTypes
+		config.MaxDepth = maxDepth
+		config.HandlerTimeout = handlerTimeout
+		config.DefaultNamespace = defaultNamespace
+		config.AllNamespacesList = namespaceAll
+		config.ShortNameTools = useShortNames
+		config.Scope = scopeValues[scope]
+		config.Group = groupOverride
+		config.AllowUnstructured = allowUnstructured
+		config.ToolPrefix = toolPrefix
+		config.WithCache = withCache
+		config.FindTool = withFind
+		config.ReadOnly = readOnly
+		config.ToolsetDescription = description
 
 		// Create toolset info
 		toolsetInfo, err := analyzer.NewToolsetInfo(crdInfo, config)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to create toolset info for %s: %v\n", crdFile, err)
+			logger.Warn("failed to create toolset info", "crd", crdFile, "error", err)
+			summary.failed++
 			continue
 		}
 
 		// Generate code
-		if err := generateToolset(toolsetInfo, crdOutputDir); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to generate toolset for %s: %v\n", crdFile, err)
+		if err := generateToolset(toolsetInfo, crdFile, crdOutputDir); err != nil {
+			logger.Warn("failed to generate toolset", "crd", crdFile, "error", err)
+			summary.failed++
 			continue
 		}
+		importPaths = append(importPaths, toolsetInfo.ImportPath)
+		summary.generated++
+	}
+	summary.log(source)
+
+	if aggregateScheme != "" {
+		return writeAggregateScheme(importPaths)
+	}
 
-		if verbose {
-			fmt.Printf("Generated toolset for %s in %s\n", crdInfo.Kind, crdOutputDir)
+	return nil
+}
+
+// writeAggregateScheme writes the --aggregate-scheme file for the generated toolset packages
+func writeAggregateScheme(importPaths []string) error {
+	if showDiff {
+		logger.Debug("skipping aggregate scheme in diff mode", "file", aggregateScheme)
+		return nil
+	}
+
+	if dryRun {
+		if outputFormat == outputFormatJSON {
+			plannedToolsets.AggregateScheme = aggregateScheme
+			return nil
 		}
+		fmt.Printf("Would generate aggregate scheme %s for %d toolsets\n", aggregateScheme, len(importPaths))
+		return nil
+	}
+
+	license, err := loadHeaderFile()
+	if err != nil {
+		return err
+	}
+	header, err := generator.FileHeader(buildTag, license)
+	if err != nil {
+		return err
 	}
 
+	if err := generator.GenerateAggregateScheme(aggregateScheme, importPaths, overwrite, header, logger); err != nil {
+		return fmt.Errorf("failed to generate aggregate scheme: %w", err)
+	}
+
+	logger.Info("generated aggregate scheme", "file", aggregateScheme, "packages", len(importPaths))
 	return nil
 }
 
-// generateToolset generates a complete toolset
-func generateToolset(toolsetInfo *analyzer.ToolsetInfo, outputDir string) error {
+// generateToolset generates a complete toolset from the CRD at crdPath
+func generateToolset(toolsetInfo *analyzer.ToolsetInfo, crdPath, outputDir string) error {
+	for _, warning := range toolsetInfo.Warnings {
+		logger.Warn("schema warning", "kind", toolsetInfo.CRD.Kind, "warning", warning)
+	}
+	if crd := toolsetInfo.CRD; crd.CRD != nil && crd.Scope != crd.CRD.Spec.Scope {
+		logger.Warn("overriding CRD scope", "kind", crd.Kind, "declared", crd.CRD.Spec.Scope, "scope", crd.Scope)
+	}
+	if crd := toolsetInfo.CRD; crd.CRD != nil && crd.Group != crd.CRD.Spec.Group {
+		logger.Warn("overriding CRD API group, the CRD must be installed under this group",
+			"kind", crd.Kind, "declared", crd.CRD.Spec.Group, "group", crd.Group)
+	}
+	if crd := toolsetInfo.CRD; crd.IsDeprecated() {
+		logger.Warn("generating from deprecated CRD version", "kind", crd.Kind, "version", crd.Version,
+			"warning", crd.GetDeprecationWarning())
+	}
+
+	header, err := loadHeaderFile()
+	if err != nil {
+		return err
+	}
+
 	// Create generator config
 	genConfig := &generator.GeneratorConfig{
 		OutputDir:       outputDir,
@@ -387,6 +953,21 @@ func generateToolset(toolsetInfo *analyzer.ToolsetInfo, outputDir string) error
 		ModulePath:      modulePath,
 		OverwriteFiles:  overwrite,
 		IncludeComments: true,
+		Logger:          logger,
+		BuildTag:        buildTag,
+		Header:          header,
+		MergeCustom:     overwriteMode == overwriteMerge,
+		SingleFile:      singleFile,
+		Prune:           prune,
+		Force:           force,
+		ForceClean:      forceClean,
+		ClientQPS:       clientQPS,
+		ClientBurst:     clientBurst,
+		SchemaDraft:     schemaDraft,
+		VerifyCompiles:  verifyCompiles,
+		AlwaysWrite:     alwaysWrite,
+
+		DescriptionMaxLength: descriptionMaxLen,
 	}
 
 	// Create generator
@@ -395,11 +976,41 @@ func generateToolset(toolsetInfo *analyzer.ToolsetInfo, outputDir string) error
 		return fmt.Errorf("failed to create generator: %w", err)
 	}
 
+	// Diff mode renders the toolset and compares it with the files on disk without writing
+	if showDiff {
+		if _, err := gen.DiffToolset(toolsetInfo, os.Stdout); err != nil {
+			return fmt.Errorf("failed to diff toolset: %w", err)
+		}
+		return nil
+	}
+
 	// Dry run check
 	if dryRun {
+		if outputFormat == outputFormatJSON {
+			plan, err := gen.PlanToolset(toolsetInfo)
+			if err != nil {
+				return fmt.Errorf("failed to plan toolset: %w", err)
+			}
+			if emitGoGenerate {
+				plan.Files = append(plan.Files, filepath.Join(outputDir, generator.GoGenerateFilename))
+			}
+			plannedToolsets.Toolsets = append(plannedToolsets.Toolsets, plan)
+			return nil
+		}
+
+		if dryRunShowContent || verbose {
+			if err := gen.PrintToolset(toolsetInfo, os.Stdout); err != nil {
+				return fmt.Errorf("failed to render toolset: %w", err)
+			}
+			return nil
+		}
+
 		fmt.Printf("Would generate toolset for %s in %s\n", toolsetInfo.CRD.Kind, outputDir)
 		fmt.Printf("Package: %s\n", toolsetInfo.PackageName)
-		fmt.Printf("Files: toolset.go, types.go, client.go, handlers.go, schema.go, doc.go\n")
+		fmt.Printf("Files: %s\n", strings.Join(gen.OutputFilenames(toolsetInfo), ", "))
+		if emitGoGenerate {
+			fmt.Printf("go:generate file: %s\n", generator.GoGenerateFilename)
+		}
 		return nil
 	}
 
@@ -408,25 +1019,53 @@ func generateToolset(toolsetInfo *analyzer.ToolsetInfo, outputDir string) error
 		return fmt.Errorf("failed to generate toolset: %w", err)
 	}
 
-	if verbose {
-		fmt.Printf("Successfully generated toolset in %s\n", outputDir)
+	logger.Debug("generated toolset", "kind", toolsetInfo.CRD.Kind, "output", outputDir)
+
+	if emitGoGenerate {
+		if err := writeGoGenerateFile(toolsetInfo, crdPath, outputDir); err != nil {
+			return err
+		}
 	}
 
 	// Register toolset if --register flag is set
 	if registerToolset {
-		if err := registerToolsetImport(toolsetInfo.PackageName, outputDir); err != nil {
+		if err := registerToolsetImport(toolsetInfo.ImportPath, outputDir); err != nil {
 			return fmt.Errorf("failed to register toolset: %w", err)
 		}
-		if verbose {
-			fmt.Printf("Successfully registered toolset in modules.go\n")
-		}
 	}
 
 	return nil
 }
 
-// findCRDFiles finds all YAML files in a directory that could be CRDs
-func findCRDFiles(dir string) ([]string, error) {
+// loadToolsetDescription returns the --toolset-description, or the content of the --description-file
+func loadToolsetDescription() (string, error) {
+	if descriptionFile == "" {
+		return toolsetDescription, nil
+	}
+
+	content, err := os.ReadFile(descriptionFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read description file: %w", err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// loadHeaderFile returns the content of the --header-file, or an empty string if none is set
+func loadHeaderFile() (string, error) {
+	if headerFile == "" {
+		return "", nil
+	}
+
+	content, err := os.ReadFile(headerFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read header file: %w", err)
+	}
+	return string(content), nil
+}
+
+// findCRDFiles finds all YAML files in a directory that could be CRDs, keeping the files that
+// match an include pattern, if any are given, and no exclude pattern
+func findCRDFiles(dir string, include, exclude []string) ([]string, error) {
 	var crdFiles []string
 
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
@@ -440,32 +1079,61 @@ func findCRDFiles(dir string) ([]string, error) {
 
 		// Check for YAML files
 		ext := filepath.Ext(path)
-		if ext == ".yaml" || ext == ".yml" {
-			crdFiles = append(crdFiles, path)
+		if ext != ".yaml" && ext != ".yml" {
+			return nil
 		}
 
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if len(include) > 0 && !matchesAnyPattern(include, rel) {
+			logger.Debug("skipping file not matching --include", "file", path)
+			return nil
+		}
+		if matchesAnyPattern(exclude, rel) {
+			logger.Debug("skipping file matching --exclude", "file", path)
+			return nil
+		}
+
+		crdFiles = append(crdFiles, path)
 		return nil
 	})
 
 	return crdFiles, err
 }
 
-// registerToolsetImport adds the generated toolset import to modules.go
-func registerToolsetImport(packageName, outputDir string) error {
+// matchesAnyPattern reports whether the slash-separated relative path matches one of the glob
+// patterns. Patterns without a slash are also matched against the file name, so *-crd.yaml
+// selects files in every subdirectory.
+func matchesAnyPattern(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, rel); matched {
+			return true
+		}
+		if !strings.Contains(pattern, "/") {
+			if matched, _ := path.Match(pattern, path.Base(rel)); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// registerToolsetImport adds the import of the generated toolset package to modules.go
+func registerToolsetImport(importPath, outputDir string) error {
 	// Determine modules.go location
-	modulesPath, err := generator.DetermineModulesFilePath(outputDir, modulePath, modulesFilePath)
+	modulesPath, err := generator.DetermineModulesFilePath(outputDir, modulePath, modulesFilePath, outputLayout)
 	if err != nil {
 		return err
 	}
 
-	// Construct import path
-	importPath := filepath.Join(modulePath, "pkg", packageName)
-
-	if verbose {
-		fmt.Printf("Registering toolset: %s\n", importPath)
-		fmt.Printf("In modules file: %s\n", modulesPath)
+	// Register the import
+	if err := generator.RegisterInModulesFile(modulesPath, importPath); err != nil {
+		return err
 	}
 
-	// Register the import
-	return generator.RegisterInModulesFile(modulesPath, importPath)
+	logger.Info("registered toolset", "import", importPath, "modulesFile", modulesPath)
+	return nil
 }
@@ -0,0 +1,892 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/friedrichwilken/mcp-toolgen/pkg/generator"
+)
+
+func TestDirectoryGenerationAggregateScheme(t *testing.T) {
+	outputBase := t.TempDir()
+	schemeFile := filepath.Join(t.TempDir(), "scheme", "scheme.go")
+
+	_, err := executeGenerate(t, "--crd-dir", collisionFixtures, "--output-base", outputBase,
+		"--module-path", "github.com/test/module", "--on-collision", "group-prefix",
+		"--aggregate-scheme", schemeFile)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(schemeFile)
+	require.NoError(t, err)
+	source := string(content)
+
+	assert.Contains(t, source, "package scheme\n")
+	assert.Contains(t, source, "func AddAllToScheme(scheme *runtime.Scheme) error {")
+	for _, packageName := range []string{"examplecomwidgets", "otheriowidgets"} {
+		assert.Contains(t, source, `"github.com/test/module/pkg/`+packageName+`"`)
+		assert.Contains(t, source, packageName+".AddToScheme,")
+	}
+
+	_, err = parser.ParseFile(token.NewFileSet(), schemeFile, content, parser.AllErrors)
+	assert.NoError(t, err, "aggregate scheme should be valid Go")
+}
+
+func TestDirectoryGenerationIncludeExclude(t *testing.T) {
+	const mixedFixtures = "../../test/fixtures/mixed"
+
+	outputBase := t.TempDir()
+	logs, err := executeGenerate(t, "--crd-dir", mixedFixtures, "--output-base", outputBase,
+		"--module-path", "github.com/test/module", "--exclude", "drafts/*")
+	require.NoError(t, err)
+	assert.NotContains(t, logs, "failed to parse CRD", "manifests that are not CRDs should be skipped quietly")
+	assert.ElementsMatch(t, []string{"certificates", "widgets"}, listDirNames(t, outputBase))
+
+	outputBase = t.TempDir()
+	logs, err = executeGenerate(t, "--crd-dir", mixedFixtures, "--output-base", outputBase,
+		"--module-path", "github.com/test/module", "--include", "*-crd.yaml", "--exclude", "certificate-*,drafts/*",
+		"--log-level", "debug")
+	require.NoError(t, err)
+	assert.Contains(t, logs, "skipping file not matching --include")
+	assert.Contains(t, logs, "skipping file matching --exclude")
+	assert.NotContains(t, logs, "skipping file that is not a CRD", "the include pattern should leave out the other manifests")
+	assert.Equal(t, []string{"widgets"}, listDirNames(t, outputBase))
+
+	// Without an exclude pattern the draft CRD is processed and fails
+	logs, err = executeGenerate(t, "--crd-dir", mixedFixtures, "--output-base", t.TempDir(),
+		"--module-path", "github.com/test/module", "--include", "drafts/*")
+	require.NoError(t, err)
+	assert.Contains(t, logs, "failed to parse CRD")
+
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module", "--include", "*.yaml")
+	assert.ErrorContains(t, err, "--include and --exclude require --crd-dir")
+
+	_, err = executeGenerate(t, "--crd-dir", mixedFixtures, "--output-base", t.TempDir(),
+		"--module-path", "github.com/test/module", "--exclude", "[")
+	assert.ErrorContains(t, err, `invalid pattern "["`)
+}
+
+func TestHelmChartGeneration(t *testing.T) {
+	const chartDir = "../../test/fixtures/helm/widgets"
+
+	outputBase := t.TempDir()
+	_, err := executeGenerate(t, "--helm-chart", chartDir, "--output-base", outputBase,
+		"--module-path", "github.com/test/module", "--generate-crd-resource")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"globalconfigs", "widgets"}, listDirNames(t, outputBase))
+
+	// The embedded manifest is the expanded CRD
+	resources := readDir(t, filepath.Join(outputBase, "widgets"))["resources.go"]
+	assert.Contains(t, resources, "app.kubernetes.io/version: 1.4.0")
+	assert.Contains(t, resources, "helm.sh/resource-policy: keep")
+	assert.NotContains(t, resources, "{{")
+
+	outputBase = t.TempDir()
+	_, err = executeGenerate(t, "--helm-chart", chartDir, "--output-base", outputBase,
+		"--module-path", "github.com/test/module", "--include", "widget-*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"widgets"}, listDirNames(t, outputBase))
+
+	// Templating the expansion cannot resolve fails the run
+	brokenChart := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(brokenChart, "Chart.yaml"), []byte("apiVersion: v2\nname: broken\nversion: 0.1.0\n"), 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(brokenChart, "crds"), 0o755))
+	crdData, err := os.ReadFile("../../test/fixtures/simple-crd.yaml")
+	require.NoError(t, err)
+	crd := strings.Replace(string(crdData), "name: widgets.example.com",
+		"name: widgets.example.com\n  namespace: {{ .Release.Namespace }}", 1)
+	require.NoError(t, os.WriteFile(filepath.Join(brokenChart, "crds", "widget-crd.yaml"), []byte(crd), 0o644))
+	_, err = executeGenerate(t, "--helm-chart", brokenChart, "--output-base", t.TempDir(),
+		"--module-path", "github.com/test/module")
+	assert.ErrorContains(t, err, "cannot resolve {{ .Release.Namespace }}")
+
+	_, err = executeGenerate(t, "--helm-chart", chartDir, "--module-path", "github.com/test/module")
+	assert.ErrorContains(t, err, "--output-base is required when using --helm-chart")
+
+	_, err = executeGenerate(t, "--helm-chart", chartDir, "--crd-dir", "../../test/fixtures/mixed",
+		"--output-base", t.TempDir(), "--module-path", "github.com/test/module")
+	assert.ErrorContains(t, err, "--helm-chart is mutually exclusive with --crd, --crd-dir and --from-cluster")
+}
+
+func TestDirectoryGenerationSummary(t *testing.T) {
+	logs, err := executeGenerate(t, "--crd-dir", "../../test/fixtures/mixed", "--output-base", t.TempDir(),
+		"--module-path", "github.com/test/module")
+	require.NoError(t, err)
+	assert.Contains(t, logs, "level=WARN msg=\"generated toolsets from directory\" generated=2 skipped=3 failed=1",
+		"the draft CRD should fail and the deployment, service and config map should be skipped")
+
+	logs, err = executeGenerate(t, "--crd-dir", "../../test/fixtures/mixed", "--output-base", t.TempDir(),
+		"--module-path", "github.com/test/module", "--exclude", "drafts/*", "--log-level", "info")
+	require.NoError(t, err)
+	assert.Contains(t, logs, "level=INFO msg=\"generated toolsets from directory\" generated=2 skipped=3 failed=0")
+}
+
+// listDirNames returns the names of the entries of dir
+func listDirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestAggregateSchemeRequiresDirectory(t *testing.T) {
+	_, err := executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module", "--aggregate-scheme", filepath.Join(t.TempDir(), "scheme.go"))
+	assert.ErrorContains(t, err, "--aggregate-scheme requires --crd-dir")
+}
+
+func TestFromClusterFlagValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "with --crd-dir",
+			args:    []string{"--from-cluster", "--crd-dir", "../../test/fixtures", "--output-base", t.TempDir()},
+			wantErr: "--from-cluster is mutually exclusive with --crd and --crd-dir",
+		},
+		{
+			name:    "without --output-base",
+			args:    []string{"--from-cluster", "--group", "example.com"},
+			wantErr: "--output-base is required when using --from-cluster",
+		},
+		{
+			name:    "--group without --from-cluster",
+			args:    []string{"--crd-dir", "../../test/fixtures", "--output-base", t.TempDir(), "--group", "example.com"},
+			wantErr: "--kubeconfig and --group require --from-cluster",
+		},
+		{
+			name:    "with --emit-gogenerate",
+			args:    []string{"--from-cluster", "--output-base", t.TempDir(), "--emit-gogenerate"},
+			wantErr: "--emit-gogenerate is not supported with --from-cluster",
+		},
+		{
+			name:    "unreadable kubeconfig",
+			args:    []string{"--from-cluster", "--output-base", t.TempDir(), "--kubeconfig", filepath.Join(t.TempDir(), "missing")},
+			wantErr: "failed to load kubeconfig",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := executeGenerate(t, append(tt.args, "--module-path", "github.com/test/module")...)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestGenerateWithHeaderFile(t *testing.T) {
+	headerPath := filepath.Join(t.TempDir(), "boilerplate.txt")
+	require.NoError(t, os.WriteFile(headerPath, []byte("Copyright {{.Year}} Example Corp.\n\nLicensed under the Apache License, Version 2.0\n"), 0o644))
+
+	outputDir := t.TempDir()
+	_, err := executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--header-file", headerPath, "--build-tag", "mytools")
+	require.NoError(t, err)
+
+	expected := fmt.Sprintf("//go:build mytools\n// +build mytools\n\n"+
+		"// Copyright %d Example Corp.\n//\n// Licensed under the Apache License, Version 2.0\n\n", time.Now().Year())
+
+	entries, err := os.ReadDir(outputDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 9)
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(outputDir, entry.Name()))
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(content), expected), "%s should start with the header:\n%s", entry.Name(), content)
+	}
+}
+
+func TestGenerateDocResource(t *testing.T) {
+	docPath := filepath.Join(t.TempDir(), "widgets.md")
+	require.NoError(t, os.WriteFile(docPath, []byte("# Widgets\n\nSet `spec.size` to scale a widget.\n"), 0o644))
+
+	outputDir := t.TempDir()
+	_, err := executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--generate-doc-resource", docPath)
+	require.NoError(t, err)
+
+	resources := readDir(t, outputDir)["resources.go"]
+	assert.Contains(t, resources, `"docs://example.com/v1/Widget",`)
+	assert.Contains(t, resources, `"text/markdown",`)
+	assert.Contains(t, resources, "const embeddedWidgetDocumentation = `# Widgets\n\nSet ` + \"`\" + `spec.size` + \"`\" + ` to scale a widget.\n`")
+	assert.NotContains(t, resources, "crd://", "the CRD resource has its own flag")
+
+	htmlPath := filepath.Join(t.TempDir(), "widgets.html")
+	require.NoError(t, os.WriteFile(htmlPath, []byte("<h1>Widgets</h1>\n"), 0o644))
+	outputDir = t.TempDir()
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--generate-doc-resource", htmlPath)
+	require.NoError(t, err)
+	assert.Contains(t, readDir(t, outputDir)["resources.go"], `"text/html",`)
+}
+
+func TestGenerateWithMissingHeaderFile(t *testing.T) {
+	_, err := executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module", "--header-file", filepath.Join(t.TempDir(), "missing.txt"))
+	assert.ErrorContains(t, err, "failed to read header file")
+}
+
+func TestGenerateWithToolsetDescription(t *testing.T) {
+	descriptionPath := filepath.Join(t.TempDir(), "description.txt")
+	require.NoError(t, os.WriteFile(descriptionPath, []byte("Manage widgets. Always list widgets before deleting one.\n"), 0o644))
+
+	for _, args := range [][]string{
+		{"--toolset-description", "Manage widgets. Always list widgets before deleting one."},
+		{"--description-file", descriptionPath},
+	} {
+		outputDir := t.TempDir()
+		_, err := executeGenerate(t, append([]string{"--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+			"--module-path", "github.com/test/module"}, args...)...)
+		require.NoError(t, err)
+
+		toolset, err := os.ReadFile(filepath.Join(outputDir, "toolset.go"))
+		require.NoError(t, err)
+		assert.Contains(t, string(toolset), `return "Manage widgets. Always list widgets before deleting one."`, "args %v", args)
+	}
+
+	_, err := executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module", "--toolset-description", "x", "--description-file", descriptionPath)
+	assert.ErrorContains(t, err, "mutually exclusive")
+}
+
+func TestGenerateWithConfigFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ".mcp-toolgen.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("module-path: github.com/config/module\ncrud: r\nexclude-fields:\n  - spec.size\n"), 0o644))
+
+	outputDir := t.TempDir()
+	_, err := executeGenerate(t, "--config", configPath, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir)
+	require.NoError(t, err)
+	assert.Equal(t, "github.com/config/module", modulePath)
+	assert.Equal(t, []string{"spec.size"}, excludeFields)
+
+	handlers, err := os.ReadFile(filepath.Join(outputDir, "handlers.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(handlers), "func HandleGetWidget(")
+	assert.NotContains(t, string(handlers), "func HandleCreateWidget(", "crud from the config file should be honored")
+
+	// Flags given on the command line take precedence over the config file
+	_, err = executeGenerate(t, "--config", configPath, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/cli/module")
+	require.NoError(t, err)
+	assert.Equal(t, "github.com/cli/module", modulePath)
+}
+
+func TestGenerateEmitsGoGenerateDirective(t *testing.T) {
+	headerPath := filepath.Join(t.TempDir(), "boilerplate.txt")
+	require.NoError(t, os.WriteFile(headerPath, []byte("Copyright Example Corp."), 0o644))
+
+	outputDir := filepath.Join(t.TempDir(), "pkg", "widgets")
+	_, err := executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--crud", "cr", "--exclude-fields", "spec.size,spec.color",
+		"--toolset-description", `Costs $5 per "widget"`, "--header-file", headerPath, "--emit-gogenerate")
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(outputDir, generator.GoGenerateFilename))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	directive := lines[len(lines)-1]
+	require.True(t, strings.HasPrefix(directive, "//go:generate mcp-toolgen "), directive)
+
+	args := splitGoGenerateDirective(t, directive)
+	assert.Contains(t, args, "--module-path=github.com/test/module")
+	assert.Contains(t, args, "--crud=cr")
+	assert.Contains(t, args, "--exclude-fields=spec.size,spec.color")
+	assert.Contains(t, args, "--emit-gogenerate=true")
+	assert.Contains(t, args, `--toolset-description=Costs $5 per "widget"`)
+	relHeaderPath, err := filepath.Rel(outputDir, headerPath)
+	require.NoError(t, err)
+	assert.Contains(t, args, "--header-file="+filepath.ToSlash(relHeaderPath), "paths should be relative to the package")
+
+	before := readDir(t, outputDir)
+
+	// Running the directive from the package directory, like go generate does, rebuilds the same toolset
+	t.Chdir(outputDir)
+	_, err = executeGenerate(t, args...)
+	require.NoError(t, err)
+	assert.Equal(t, before, readDir(t, "."))
+}
+
+// splitGoGenerateDirective returns the arguments go generate passes to the command of directive
+func splitGoGenerateDirective(t *testing.T, directive string) []string {
+	t.Helper()
+
+	rest := strings.TrimPrefix(directive, "//go:generate mcp-toolgen ")
+	var args []string
+	for rest != "" {
+		var word string
+		if rest[0] == '"' {
+			quoted, err := strconv.QuotedPrefix(rest)
+			require.NoError(t, err)
+			word, err = strconv.Unquote(quoted)
+			require.NoError(t, err)
+			rest = rest[len(quoted):]
+		} else {
+			word, rest, _ = strings.Cut(rest, " ")
+		}
+		args = append(args, os.Expand(word, func(name string) string {
+			if name == "DOLLAR" {
+				return "$"
+			}
+			return os.Getenv(name)
+		}))
+		rest = strings.TrimLeft(rest, " ")
+	}
+	return args
+}
+
+// readDir returns the content of every file in dir by name
+func readDir(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	files := make(map[string]string)
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		require.NoError(t, err)
+		files[entry.Name()] = string(content)
+	}
+	return files
+}
+
+func TestDryRunShowContent(t *testing.T) {
+	for _, flag := range []string{"--dry-run-show-content", "--verbose"} {
+		t.Run(flag, func(t *testing.T) {
+			outputDir := filepath.Join(t.TempDir(), "widgets")
+			var err error
+			output := captureStdout(t, func() {
+				_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+					"--module-path", "github.com/test/module", "--dry-run", flag)
+			})
+			require.NoError(t, err)
+
+			for _, filename := range []string{"toolset.go", "types.go", "register.go", "client.go", "handlers.go", "options.go", "errors.go", "schema.go", "doc.go"} {
+				assert.Contains(t, output, "// ===== "+filepath.Join(outputDir, filename)+" =====\n", filename)
+			}
+			assert.Equal(t, 9, strings.Count(output, "\npackage widgets\n"))
+			assert.NoDirExists(t, outputDir, "dry run must not write files")
+		})
+	}
+}
+
+func TestDryRunJSON(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "workerpools")
+	var err error
+	output := captureStdout(t, func() {
+		_, err = executeGenerate(t, "--crd", "../../test/fixtures/scalable-crd.yaml", "--output", outputDir,
+			"--module-path", "github.com/test/module", "--with-find", "--dry-run", "--output-format", "json")
+	})
+	require.NoError(t, err)
+
+	var plan struct {
+		Toolsets []struct {
+			Kind       string   `json:"kind"`
+			Package    string   `json:"package"`
+			ImportPath string   `json:"importPath"`
+			Files      []string `json:"files"`
+			Operations []string `json:"operations"`
+			Tools      []string `json:"tools"`
+		} `json:"toolsets"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(output), &plan), "dry run output is not JSON: %s", output)
+	require.Len(t, plan.Toolsets, 1)
+	toolset := plan.Toolsets[0]
+	assert.Equal(t, "WorkerPool", toolset.Kind)
+	assert.Equal(t, "github.com/test/module/pkg/workerpools", toolset.ImportPath)
+	assert.Equal(t, []string{
+		"workerpools_create", "workerpools_get", "workerpools_list", "workerpools_update", "workerpools_delete",
+		"workerpools_scale", "workerpools_find",
+	}, toolset.Tools)
+	for _, filename := range []string{"toolset.go", "types.go", "register.go", "client.go", "handlers.go", "options.go", "errors.go", "schema.go", "doc.go"} {
+		assert.Contains(t, toolset.Files, filepath.Join(outputDir, filename))
+	}
+	assert.NoDirExists(t, outputDir, "dry run must not write files")
+
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module", "--output-format", "json")
+	assert.ErrorContains(t, err, "--output-format json requires --dry-run")
+
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module", "--dry-run", "--output-format", "yaml")
+	assert.ErrorContains(t, err, `invalid --output-format "yaml", valid values are: text, json`)
+}
+
+func TestSingleFile(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "widgets")
+	var err error
+	output := captureStdout(t, func() {
+		_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+			"--module-path", "github.com/test/module", "--single-file", "--dry-run")
+	})
+	require.NoError(t, err)
+	assert.Contains(t, output, "Files: widgets.go\n")
+
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--single-file")
+	require.NoError(t, err)
+	files := readDir(t, outputDir)
+	assert.Len(t, files, 1)
+	assert.Contains(t, files, "widgets.go")
+}
+
+// captureStdout returns what fn writes to os.Stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		content, _ := io.ReadAll(r)
+		output <- string(content)
+	}()
+
+	fn()
+	require.NoError(t, w.Close())
+	return <-output
+}
+
+func TestScopeOverride(t *testing.T) {
+	outputDir := t.TempDir()
+	logs, err := executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--scope", "cluster")
+	require.NoError(t, err)
+	assert.Contains(t, logs, "overriding CRD scope")
+	assert.Contains(t, logs, "declared=Namespaced scope=Cluster")
+
+	files := readDir(t, outputDir)
+	assert.NotContains(t, files["options.go"], "Namespace")
+	assert.NotContains(t, files["schema.go"], "Kubernetes namespace")
+	assert.Contains(t, files["handlers.go"], `clusterFor(params).Get(params, "", opts.Name)`)
+
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module", "--scope", "global")
+	assert.ErrorContains(t, err, `invalid --scope "global"`)
+}
+
+func TestWebhookConversionWarning(t *testing.T) {
+	logs, err := executeGenerate(t, "--crd", "../../test/fixtures/webhook-conversion-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module")
+	require.NoError(t, err)
+	assert.Contains(t, logs, `level=WARN msg="schema warning" kind=Sprocket warning="Sprocket is converted between versions by a webhook, the generated types reflect only version v1"`)
+}
+
+func TestEmptySpecWarning(t *testing.T) {
+	outputDir := t.TempDir()
+	logs, err := executeGenerate(t, "--crd", "../../test/fixtures/empty-spec-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module")
+	require.NoError(t, err)
+	assert.Contains(t, logs, `level=WARN msg="schema warning" kind=Marker warning="the spec of Marker declares no properties, so it is generated as map[string]interface{}"`)
+
+	files := readDir(t, outputDir)
+	assert.Contains(t, files["types.go"], "Spec map[string]interface{} `json:\"spec,omitempty\"`")
+	assert.NotContains(t, files["types.go"], "type MarkerSpec struct")
+}
+
+func TestWithFind(t *testing.T) {
+	outputDir := t.TempDir()
+	_, err := executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--with-find")
+	require.NoError(t, err)
+	files := readDir(t, outputDir)
+	assert.Contains(t, files["toolset.go"], `"widgets_find"`)
+	assert.Contains(t, files["handlers.go"], "func handleWidgetFind(")
+}
+
+func TestReadOnly(t *testing.T) {
+	outputDir := t.TempDir()
+	_, err := executeGenerate(t, "--crd", "../../test/fixtures/scalable-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--readonly", "--with-find")
+	require.NoError(t, err)
+
+	toolset := readDir(t, outputDir)["toolset.go"]
+	for _, tool := range []string{"workerpools_get", "workerpools_list", "workerpools_find"} {
+		assert.Contains(t, toolset, `"`+tool+`"`)
+	}
+	for _, tool := range []string{"workerpools_create", "workerpools_update", "workerpools_delete", "workerpools_scale"} {
+		assert.NotContains(t, toolset, `"`+tool+`"`)
+	}
+	assert.Equal(t, 3, strings.Count(toolset, "ReadOnlyHint:    ptr.To(true),"))
+	assert.Equal(t, 3, strings.Count(toolset, "DestructiveHint: ptr.To(false),"))
+
+	// --crud r is what --readonly selects anyway
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/scalable-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module", "--readonly", "--crud", "r")
+	require.NoError(t, err)
+
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/scalable-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module", "--readonly", "--crud", "rd")
+	assert.ErrorContains(t, err, "--readonly cannot be combined with --crud rd, which selects write operations")
+
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module", "--readonly", "--operations-file", "../../test/fixtures/operations/pause.yaml")
+	assert.ErrorContains(t, err, "--readonly cannot be combined with --operations-file")
+}
+
+func TestOutputLayout(t *testing.T) {
+	const modules = "package mcp\n\nimport (\n\t_ \"github.com/acme/mono/config\"\n)\n"
+
+	// In the flat layout the toolset and modules.go sit directly in the module root
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "mcp"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "mcp", "modules.go"), []byte(modules), 0o644))
+	_, err := executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", filepath.Join(root, "widgets"),
+		"--module-path", "github.com/acme/mono", "--output-layout", "flat", "--register")
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(root, "mcp", "modules.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `_ "github.com/acme/mono/widgets"`)
+	assert.FileExists(t, filepath.Join(root, "widgets", "toolset.go"))
+
+	// --import-path names packages placed anywhere in the module
+	root = t.TempDir()
+	modulesFile := filepath.Join(root, "cmd", "server", "modules.go")
+	require.NoError(t, os.MkdirAll(filepath.Dir(modulesFile), 0o755))
+	require.NoError(t, os.WriteFile(modulesFile, []byte(modules), 0o644))
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", filepath.Join(root, "tools", "k8s", "widgets"),
+		"--module-path", "github.com/acme/mono", "--import-path", "github.com/acme/mono/tools/k8s/widgets",
+		"--register", "--modules-file", modulesFile)
+	require.NoError(t, err)
+	content, err = os.ReadFile(modulesFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `_ "github.com/acme/mono/tools/k8s/widgets"`)
+
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/acme/mono", "--output-layout", "nested")
+	assert.ErrorContains(t, err, `invalid --output-layout "nested", valid values are: pkg, flat`)
+
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/acme/mono", "--import-path", "github.com/acme/mono/tools/")
+	assert.ErrorContains(t, err, "invalid --import-path")
+
+	_, err = executeGenerate(t, "--crd-dir", "../../test/fixtures", "--output-base", t.TempDir(),
+		"--module-path", "github.com/acme/mono", "--import-path", "github.com/acme/mono/tools")
+	assert.ErrorContains(t, err, "--import-path requires --crd")
+}
+
+func TestHandlerTimeout(t *testing.T) {
+	outputDir := t.TempDir()
+	_, err := executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--handler-timeout", "5s")
+	require.NoError(t, err)
+	assert.Contains(t, readDir(t, outputDir)["handlers.go"], "const WidgetHandlerTimeout time.Duration = 5 * time.Second")
+
+	outputDir = t.TempDir()
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--handler-timeout", "0")
+	require.NoError(t, err)
+	assert.Contains(t, readDir(t, outputDir)["handlers.go"], "const WidgetHandlerTimeout time.Duration = 0")
+
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module", "--handler-timeout", "-1s")
+	assert.ErrorContains(t, err, "--handler-timeout must not be negative")
+}
+
+func TestDeprecatedVersion(t *testing.T) {
+	outputDir := t.TempDir()
+	logs, err := executeGenerate(t, "--crd", "../../test/fixtures/deprecated-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module")
+	require.NoError(t, err)
+	assert.Contains(t, logs, "generating from deprecated CRD version")
+	assert.Contains(t, logs, `version=v1beta1 warning="example.com/v1beta1 Gadget is deprecated; use example.com/v1 Gadget"`)
+
+	files := readDir(t, outputDir)
+	assert.Contains(t, files["doc.go"], "// # Deprecation")
+	assert.Contains(t, files["doc.go"], "// warns: example.com/v1beta1 Gadget is deprecated; use example.com/v1 Gadget\n")
+
+	logs, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module")
+	require.NoError(t, err)
+	assert.NotContains(t, logs, "deprecated")
+}
+
+func TestPluralOverride(t *testing.T) {
+	outputDir := t.TempDir()
+	_, err := executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--plural", "gizmos", "--singular", "gizmo")
+	require.NoError(t, err)
+
+	files := readDir(t, outputDir)
+	for _, tool := range []string{"gizmos_create", "gizmos_get", "gizmos_list", "gizmos_update", "gizmos_delete"} {
+		assert.Contains(t, files["toolset.go"], `"`+tool+`"`)
+	}
+	assert.NotContains(t, files["toolset.go"], `"widgets_`)
+	assert.Contains(t, files["toolset.go"], "func getgizmoTool() api.ServerTool {")
+	assert.Contains(t, files["toolset.go"], "func listgizmosTool() api.ServerTool {")
+	assert.Contains(t, files["toolset.go"], "package gizmos")
+
+	// The API server still serves the resource under the plural of the CRD
+	assert.Contains(t, files["client.go"], `Resource: "widgets"`)
+	assert.Contains(t, files["types.go"], `Resource: "widgets",`)
+
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module", "--plural", "Gizmos")
+	assert.ErrorContains(t, err, `invalid --plural "Gizmos"`)
+
+	_, err = executeGenerate(t, "--crd-dir", "../../test/fixtures", "--output-base", t.TempDir(),
+		"--module-path", "github.com/test/module", "--plural", "gizmos")
+	assert.ErrorContains(t, err, "--plural and --singular require --crd")
+}
+
+func TestGroupOverride(t *testing.T) {
+	outputDir := t.TempDir()
+	logs, err := executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--group-override", "fork.example.org")
+	require.NoError(t, err)
+	assert.Contains(t, logs, "overriding CRD API group, the CRD must be installed under this group")
+	assert.Contains(t, logs, "declared=example.com group=fork.example.org")
+
+	files := readDir(t, outputDir)
+	assert.Contains(t, files["types.go"], `Group:   "fork.example.org",`)
+	assert.Contains(t, files["handlers.go"], `Group:   "fork.example.org",`)
+	assert.Contains(t, files["register.go"], `"fork.example.org"`)
+	assert.NotContains(t, files["handlers.go"], `"example.com"`)
+
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module", "--group-override", "Fork_Group")
+	assert.ErrorContains(t, err, `invalid --group-override "Fork_Group"`)
+}
+
+func TestToolPrefix(t *testing.T) {
+	outputDir := t.TempDir()
+	_, err := executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--tool-prefix", "acme", "--use-short-names")
+	require.NoError(t, err)
+
+	files := readDir(t, outputDir)
+	for _, operation := range []string{"create", "get", "list", "update", "delete"} {
+		assert.Contains(t, files["toolset.go"], `"acme_widgets_`+operation+`"`)
+		assert.Contains(t, files["doc.go"], "//   - acme_widgets_"+operation+":")
+	}
+	assert.Contains(t, files["toolset.go"], `"acme_wgt_get")`, "short name aliases should be prefixed too")
+	assert.NotContains(t, files["toolset.go"], `"widgets_get"`)
+
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module", "--tool-prefix", "Acme-")
+	assert.ErrorContains(t, err, `invalid --tool-prefix "Acme-"`)
+}
+
+func TestAllowUnstructured(t *testing.T) {
+	_, err := executeGenerate(t, "--crd", "../../test/fixtures/schemaless-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module")
+	assert.ErrorContains(t, err, "use --allow-unstructured to generate an unstructured toolset")
+
+	outputDir := t.TempDir()
+	logs, err := executeGenerate(t, "--crd", "../../test/fixtures/schemaless-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--allow-unstructured")
+	require.NoError(t, err)
+	assert.Contains(t, logs, "Blob has no structural schema")
+
+	files := readDir(t, outputDir)
+	assert.Contains(t, files["types.go"], "type Blob = unstructured.Unstructured")
+	assert.Contains(t, files["client.go"], "blob := NewUnstructuredBlob()")
+	assert.Contains(t, files["doc.go"], "Blob and BlobList are aliases of\n// unstructured.Unstructured")
+}
+
+func TestClientRateLimits(t *testing.T) {
+	outputDir := t.TempDir()
+	_, err := executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--client-qps", "50", "--client-burst", "100")
+	require.NoError(t, err)
+
+	client := readDir(t, outputDir)["client.go"]
+	assert.Regexp(t, `WidgetClientQPS\s+float32 = 50\n`, client)
+	assert.Regexp(t, `WidgetClientBurst\s+= 100\n`, client)
+
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module", "--client-burst", "-1")
+	assert.ErrorContains(t, err, "--client-burst must not be negative")
+}
+
+func TestMaxDepth(t *testing.T) {
+	outputDir := t.TempDir()
+	logs, err := executeGenerate(t, "--crd", "../../test/fixtures/nested-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--max-depth", "2")
+	require.NoError(t, err)
+	assert.Contains(t, logs, "fields of spec.template.metadata are nested deeper than the maximum depth of 2")
+
+	files := readDir(t, outputDir)
+	assert.Contains(t, files["types.go"], "type PipelineSpecTemplate struct")
+	assert.NotContains(t, files["types.go"], "type PipelineSpecTemplateMetadata struct")
+	assert.NotContains(t, files["schema.go"], `"owner"`)
+
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/nested-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module", "--max-depth", "-1")
+	assert.ErrorContains(t, err, "--max-depth must not be negative")
+}
+
+func TestOperationsFile(t *testing.T) {
+	outputDir := t.TempDir()
+	_, err := executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--operations-file", "../../test/fixtures/operations/pause.yaml")
+	require.NoError(t, err)
+
+	files := readDir(t, outputDir)
+	assert.Contains(t, files["toolset.go"], `"widgets_pause"`)
+	assert.Contains(t, files["handlers.go"], "func HandlePauseWidget(")
+
+	invalid := filepath.Join(t.TempDir(), "operations.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("operations:\n- name: get\n  description: Get\n  patch: {spec: {}}\n"), 0o600))
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module", "--operations-file", invalid)
+	assert.ErrorContains(t, err, `name "get" is taken by a generated operation`)
+
+	_, err = executeGenerate(t, "--crd-dir", "../../test/fixtures/collisions", "--output-base", t.TempDir(),
+		"--module-path", "github.com/test/module", "--operations-file", "../../test/fixtures/operations/pause.yaml")
+	assert.ErrorContains(t, err, "--operations-file requires --crd")
+}
+
+func TestForce(t *testing.T) {
+	outputDir := t.TempDir()
+	_, err := executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--generate-example-prompts")
+	require.NoError(t, err)
+	require.Contains(t, readDir(t, outputDir), "prompts.go")
+
+	logs, err := executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--crud", "r", "--force", "--log-level", "info")
+	require.NoError(t, err)
+	assert.Contains(t, logs, "removed output directory")
+
+	files := readDir(t, outputDir)
+	assert.NotContains(t, files, "prompts.go", "stale files should be removed with the output directory")
+	assert.NotContains(t, files["handlers.go"], "HandleCreateWidget")
+
+	// Hand-written files block the removal unless --force-clean is given
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "custom.go"), []byte("package widgets\n"), 0o644))
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--force")
+	assert.ErrorContains(t, err, "contains files not generated by mcp-toolgen: custom.go")
+	assert.Contains(t, readDir(t, outputDir), "custom.go")
+	assert.Contains(t, readDir(t, outputDir), "handlers.go", "generated files should be kept when the removal is refused")
+
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--force", "--force-clean")
+	require.NoError(t, err)
+	assert.NotContains(t, readDir(t, outputDir), "custom.go")
+
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--force-clean")
+	assert.ErrorContains(t, err, "--force-clean requires --force")
+}
+
+func TestSchemaDraft(t *testing.T) {
+	outputDir := t.TempDir()
+	_, err := executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--schema-draft", "draft-07")
+	require.NoError(t, err)
+	assert.Contains(t, readDir(t, outputDir)["schema.go"], `s.Schema = "http://json-schema.org/draft-07/schema#"`)
+
+	_, err = executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", t.TempDir(),
+		"--module-path", "github.com/test/module", "--schema-draft", "draft-04")
+	assert.ErrorContains(t, err, `invalid --schema-draft "draft-04"`)
+}
+
+func TestDescriptionMaxLength(t *testing.T) {
+	long := strings.TrimSpace(strings.Repeat("Verbose upstream documentation of the field. ", 40))
+	crdPath := filepath.Join(t.TempDir(), "crd.yaml")
+	require.NoError(t, os.WriteFile(crdPath, fmt.Appendf(nil, `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+    plural: widgets
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        description: %q
+        properties:
+          spec:
+            type: object
+            properties:
+              notes:
+                type: string
+                description: %q
+`, long, long), 0o644))
+
+	// Descriptions that are not taken from the CRD are kept whole
+	toolsetDescription := strings.TrimSpace(strings.Repeat("Widgets of the platform team. ", 5))
+
+	outputDir := t.TempDir()
+	_, err := executeGenerate(t, "--crd", crdPath, "--output", outputDir,
+		"--module-path", "github.com/test/module", "--description-max-length", "60",
+		"--toolset-description", toolsetDescription)
+	require.NoError(t, err)
+
+	files := readDir(t, outputDir)
+	truncated := "Verbose upstream documentation of the field. Verbose upst..."
+	require.Len(t, []rune(truncated), 60)
+	assert.Contains(t, files["schema.go"], fmt.Sprintf("Description: %q,", truncated))
+	assert.Contains(t, files["types.go"], "// "+truncated+"\n")
+	assert.Contains(t, files["toolset.go"], fmt.Sprintf(`Description: "Get a Widget custom resource. %s",`, truncated))
+	assert.Contains(t, files["toolset.go"], fmt.Sprintf("return %q", toolsetDescription))
+	for filename, content := range files {
+		assert.NotContains(t, content, long[:100], "%s should not hold the whole description", filename)
+	}
+
+	// Without --toolset-description, the toolset description is taken from the CRD and truncated
+	outputDir = t.TempDir()
+	_, err = executeGenerate(t, "--crd", crdPath, "--output", outputDir,
+		"--module-path", "github.com/test/module", "--description-max-length", "60")
+	require.NoError(t, err)
+
+	files = readDir(t, outputDir)
+	assert.Contains(t, files["toolset.go"], fmt.Sprintf("return %q", truncated))
+	for filename, content := range files {
+		assert.NotContains(t, content, long[:100], "%s should not hold the whole description", filename)
+	}
+
+	_, err = executeGenerate(t, "--crd", crdPath, "--output", t.TempDir(),
+		"--module-path", "github.com/test/module", "--description-max-length", "-1")
+	assert.ErrorContains(t, err, "--description-max-length must not be negative")
+}
+
+func TestPrune(t *testing.T) {
+	outputDir := t.TempDir()
+	_, err := executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--generate-tests", "--generate-example-prompts")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "custom.go"), []byte("package widgets\n"), 0o644))
+	assert.Contains(t, readDir(t, outputDir), "prompts.go")
+
+	logs, err := executeGenerate(t, "--crd", "../../test/fixtures/simple-crd.yaml", "--output", outputDir,
+		"--module-path", "github.com/test/module", "--crud", "c", "--overwrite", "--prune", "--log-level", "info")
+	require.NoError(t, err)
+	assert.Contains(t, logs, "removed stale file")
+
+	files := readDir(t, outputDir)
+	assert.NotContains(t, files, "prompts.go")
+	assert.NotContains(t, files, "handlers_test.go")
+	assert.Contains(t, files, "custom.go", "files without the generated marker must be kept")
+	assert.NotContains(t, files["handlers.go"], "HandleDeleteWidget")
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/friedrichwilken/mcp-toolgen/pkg/analyzer"
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check that CRDs can be used for code generation",
+	Long: `Validate parses and analyzes CRDs exactly like code generation does, but writes no files.
+It reports missing schemas, invalid Go identifiers, and schema constructs that are not
+reflected in the generated types, and exits with a non-zero status if any CRD is invalid.`,
+	Example: `  # Validate a single CRD
+  mcp-toolgen validate --crd ./crds/function-crd.yaml
+
+  # Validate all CRDs in a directory
+  mcp-toolgen validate --crd-dir ./crds`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runValidate(cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().StringVar(&crdFile, "crd", "", "path to CRD YAML file")
+	validateCmd.Flags().StringVar(&crdDir, "crd-dir", "", "directory containing CRD YAML files")
+	markCRDFlagCompletion(validateCmd)
+}
+
+// runValidate validates the CRDs selected by --crd or --crd-dir and prints the problems per CRD
+func runValidate(out io.Writer) error {
+	if crdFile == "" && crdDir == "" {
+		return fmt.Errorf("either --crd or --crd-dir must be specified")
+	}
+	if crdFile != "" && crdDir != "" {
+		return fmt.Errorf("--crd and --crd-dir are mutually exclusive")
+	}
+
+	crdFiles := []string{crdFile}
+	if crdDir != "" {
+		var err error
+		crdFiles, err = findCRDFiles(crdDir, nil, nil)
+		if err != nil {
+			return fmt.Errorf("failed to find CRD files: %w", err)
+		}
+		if len(crdFiles) == 0 {
+			return fmt.Errorf("no CRD files found in directory %s", crdDir)
+		}
+	}
+
+	crdAnalyzer := analyzer.NewCRDAnalyzer()
+	failed := 0
+	for _, file := range crdFiles {
+		problems, warnings := validateCRDFile(crdAnalyzer, file)
+		if len(problems) > 0 {
+			failed++
+			fmt.Fprintf(out, "%s: FAIL\n", file)
+		} else {
+			fmt.Fprintf(out, "%s: OK\n", file)
+		}
+		for _, problem := range problems {
+			fmt.Fprintf(out, "  error: %s\n", problem)
+		}
+		for _, warning := range warnings {
+			fmt.Fprintf(out, "  warning: %s\n", warning)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d CRDs failed validation", failed, len(crdFiles))
+	}
+	return nil
+}
+
+// validateCRDFile runs the analysis steps of code generation on a CRD file and returns the problems
+// that prevent generation and the warnings about constructs missing from the generated types
+func validateCRDFile(crdAnalyzer *analyzer.CRDAnalyzer, file string) (problems, warnings []string) {
+	crdInfo, err := crdAnalyzer.ParseCRDFromFile(file)
+	if err != nil {
+		return []string{err.Error()}, nil
+	}
+
+	toolsetInfo, err := analyzer.NewToolsetInfo(crdInfo, nil)
+	if err != nil {
+		return []string{err.Error()}, nil
+	}
+
+	return toolsetInfo.Validate(), toolsetInfo.Warnings
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const missingSchemaCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: gadgets.example.com
+spec:
+  group: example.com
+  versions:
+  - name: v1
+    served: true
+    storage: true
+  scope: Namespaced
+  names:
+    plural: gadgets
+    singular: gadget
+    kind: Gadget
+`
+
+// executeValidate runs the validate command with the given arguments and returns its output
+func executeValidate(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	crdFile, crdDir = "", ""
+	t.Cleanup(func() { crdFile, crdDir = "", "" })
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs(append([]string{"validate"}, args...))
+	t.Cleanup(func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	})
+
+	err := rootCmd.Execute()
+	return out.String(), err
+}
+
+func TestValidateValidCRD(t *testing.T) {
+	output, err := executeValidate(t, "--crd", "../../test/fixtures/simple-crd.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "../../test/fixtures/simple-crd.yaml: OK\n", output)
+}
+
+func TestValidateReportsWarnings(t *testing.T) {
+	output, err := executeValidate(t, "--crd", "../../test/fixtures/composition-crd.yaml")
+	require.NoError(t, err, "warnings should not fail validation")
+	assert.Contains(t, output, "composition-crd.yaml: OK")
+	assert.Contains(t, output, "  warning: RouteSpecBackend: oneOf")
+}
+
+func TestValidateMalformedCRD(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "gadget-crd.yaml"), []byte(missingSchemaCRD), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "widget-crd.yaml"), mustReadFile(t, "../../test/fixtures/simple-crd.yaml"), 0o644))
+
+	output, err := executeValidate(t, "--crd-dir", dir)
+	require.Error(t, err)
+	assert.Equal(t, "1 of 2 CRDs failed validation", err.Error())
+	assert.Contains(t, output, "gadget-crd.yaml: FAIL\n  error: ")
+	assert.Contains(t, output, "OpenAPI v3 schema")
+	assert.Contains(t, output, "widget-crd.yaml: OK")
+}
+
+func TestValidateRequiresInput(t *testing.T) {
+	_, err := executeValidate(t)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "either --crd or --crd-dir must be specified")
+}
+
+func mustReadFile(t *testing.T, path string) []byte {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return content
+}
@@ -0,0 +1,106 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// aggregateSchemeTemplate renders a file that registers the types of several toolsets at once
+var aggregateSchemeTemplate = template.Must(template.New("aggregate").Parse(`// Package {{.Package}} registers the types of all generated toolsets with a scheme.
+package {{.Package}}
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+{{range .Imports}}
+	{{.Alias}} "{{.Path}}"
+{{- end}}
+)
+
+// AddAllToScheme adds the types of every generated toolset to scheme
+func AddAllToScheme(scheme *runtime.Scheme) error {
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+{{- range .Imports}}
+		{{.Alias}}.AddToScheme,
+{{- end}}
+	} {
+		if err := addToScheme(scheme); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+`))
+
+// aggregateImport is a toolset package referenced by the aggregate scheme file
+type aggregateImport struct {
+	Alias string
+	Path  string
+}
+
+// GenerateAggregateScheme writes a Go file at filePath with an AddAllToScheme function that calls
+// AddToScheme of every toolset package in importPaths. The package name is taken from the
+// directory the file is written to, and header (see FileHeader) is placed before the package clause.
+// logger receives a warning if the file fails to format.
+func GenerateAggregateScheme(filePath string, importPaths []string, overwrite bool, header string, logger *slog.Logger) error {
+	if len(importPaths) == 0 {
+		return fmt.Errorf("no toolset packages to aggregate")
+	}
+
+	dir, filename := filepath.Split(filePath)
+	if dir == "" {
+		dir = "."
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path for %s: %w", dir, err)
+	}
+
+	data := struct {
+		Package string
+		Imports []aggregateImport
+	}{
+		Package: toPackageName(filepath.Base(absDir)),
+	}
+
+	aliases := make(map[string]int)
+	for _, importPath := range importPaths {
+		alias := toPackageName(path.Base(importPath))
+		aliases[alias]++
+		if aliases[alias] > 1 {
+			alias = fmt.Sprintf("%s%d", alias, aliases[alias])
+		}
+		data.Imports = append(data.Imports, aggregateImport{Alias: alias, Path: importPath})
+	}
+
+	buf := bytes.NewBufferString(header)
+	if err := aggregateSchemeTemplate.Execute(buf, data); err != nil {
+		return fmt.Errorf("failed to render aggregate scheme: %w", err)
+	}
+
+	writer := NewFileWriter(dir, overwrite, true, logger)
+	return writer.WriteFile(filename, buf.String())
+}
+
+// toPackageName turns a directory name into a valid Go package name
+func toPackageName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return -1
+		}
+	}, name)
+
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "pkg" + name
+	}
+	return name
+}
@@ -0,0 +1,98 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/friedrichwilken/mcp-toolgen/pkg/analyzer"
+)
+
+// DiffToolset renders every toolset file and writes a unified diff against the files that already
+// exist in the output directory. Nothing is written to disk. Files that would be created are diffed
+// against /dev/null and unchanged files produce no output, so the result can be applied with patch.
+// It reports whether any file would change.
+func (g *Generator) DiffToolset(toolsetInfo *analyzer.ToolsetInfo, out io.Writer) (bool, error) {
+	if toolsetInfo == nil {
+		return false, fmt.Errorf("toolset info is required")
+	}
+
+	files, err := g.renderToolset(toolsetInfo)
+	if err != nil {
+		return false, err
+	}
+
+	writer := NewFileWriter(g.config.OutputDir, true, true, g.logger)
+	changed := false
+
+	for _, file := range files {
+		content, err := g.renderOutput(writer, file)
+		if err != nil {
+			return false, err
+		}
+
+		fileChanged, err := writeFileDiff(out, filepath.Join(g.config.OutputDir, file.filename), content)
+		if err != nil {
+			return false, fmt.Errorf("failed to diff %s: %w", file.filename, err)
+		}
+		changed = changed || fileChanged
+	}
+
+	return changed, nil
+}
+
+// renderOutput returns a rendered toolset file the way it would be written to the output
+// directory: formatted, and merged with the existing file in merge mode
+func (g *Generator) renderOutput(writer *FileWriter, file renderedFile) (string, error) {
+	content := file.content
+	if g.config.MergeCustom {
+		var err error
+		if content, err = writer.mergeExisting(file.filename, content); err != nil {
+			return "", err
+		}
+	}
+	return writer.formatContent(file.filename, content), nil
+}
+
+// writeFileDiff writes a unified diff from the file at path to the given content
+func writeFileDiff(out io.Writer, path, content string) (bool, error) {
+	fromFile := path
+	existing, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		fromFile = "/dev/null"
+	} else if err != nil {
+		return false, err
+	}
+
+	if string(existing) == content {
+		return false, nil
+	}
+
+	diff := difflib.UnifiedDiff{
+		A:        splitLines(string(existing)),
+		B:        splitLines(content),
+		FromFile: fromFile,
+		ToFile:   path,
+		Context:  3,
+	}
+	return true, difflib.WriteUnifiedDiff(out, diff)
+}
+
+// splitLines splits text into lines that keep their line endings. Unlike difflib.SplitLines, it
+// does not add an empty trailing line, so new and deleted files diff cleanly.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
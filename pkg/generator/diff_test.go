@@ -0,0 +1,90 @@
+package generator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/friedrichwilken/mcp-toolgen/pkg/analyzer"
+)
+
+func TestDiffToolset(t *testing.T) {
+	outputDir := t.TempDir()
+
+	gen, err := NewGenerator(&GeneratorConfig{
+		OutputDir:       outputDir,
+		PackageName:     "widgets",
+		ModulePath:      "github.com/test/module",
+		OverwriteFiles:  true,
+		IncludeComments: true,
+	})
+	require.NoError(t, err)
+
+	original := loadDiffTestToolset(t, "../../test/fixtures/simple-crd.yaml")
+
+	// Before generation every file is new
+	var buf bytes.Buffer
+	changed, err := gen.DiffToolset(original, &buf)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Contains(t, buf.String(), "--- /dev/null\n+++ "+filepath.Join(outputDir, "types.go")+"\n@@ -0,0 +1,")
+	entries, err := os.ReadDir(outputDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "diff mode must not write files")
+
+	require.NoError(t, gen.GenerateToolset(original))
+
+	// Regenerating the same CRD produces no diff
+	buf.Reset()
+	changed, err = gen.DiffToolset(original, &buf)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Empty(t, buf.String())
+
+	// Adding a field to the CRD shows up as added lines
+	fixture, err := os.ReadFile("../../test/fixtures/simple-crd.yaml")
+	require.NoError(t, err)
+	mutated := strings.Replace(string(fixture), "              enabled:\n", "              color:\n                type: string\n              enabled:\n", 1)
+	mutatedPath := filepath.Join(t.TempDir(), "widget-crd.yaml")
+	require.NoError(t, os.WriteFile(mutatedPath, []byte(mutated), 0o644))
+
+	typesBefore, err := os.ReadFile(filepath.Join(outputDir, "types.go"))
+	require.NoError(t, err)
+
+	buf.Reset()
+	changed, err = gen.DiffToolset(loadDiffTestToolset(t, mutatedPath), &buf)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	diff := buf.String()
+	typesPath := filepath.Join(outputDir, "types.go")
+	assert.Contains(t, diff, "--- "+typesPath+"\n+++ "+typesPath+"\n")
+	assert.Contains(t, diff, "+\tWidgetSpecColor string `json:\"color,omitempty\"`\n")
+	assert.Contains(t, diff, "+\t\t\t\t\t\t\t\"color\": &jsonschema.Schema{\n")
+	assert.NotContains(t, diff, "register.go", "unchanged files should not appear in the diff")
+
+	typesAfter, err := os.ReadFile(typesPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(typesBefore), string(typesAfter), "diff mode must not modify files")
+}
+
+// loadDiffTestToolset parses a CRD file into toolset info for the widgets package
+func loadDiffTestToolset(t *testing.T, crdFile string) *analyzer.ToolsetInfo {
+	t.Helper()
+
+	crdInfo, err := analyzer.NewCRDAnalyzer().ParseCRDFromFile(crdFile)
+	require.NoError(t, err)
+
+	config := analyzer.DefaultGenerationConfig()
+	config.PackageName = "widgets"
+	config.ModulePath = "github.com/test/module"
+
+	toolsetInfo, err := analyzer.NewToolsetInfo(crdInfo, config)
+	require.NoError(t, err)
+	return toolsetInfo
+}
@@ -0,0 +1,205 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"github.com/friedrichwilken/mcp-toolgen/pkg/analyzer"
+)
+
+// exampleStrings are the example values of strings with a format that restricts their content
+var exampleStrings = map[string]string{
+	"date-time": "2024-01-01T00:00:00Z",
+	"date":      "2024-01-01",
+	"duration":  "1h",
+	"byte":      "ZXhhbXBsZQ==",
+	"email":     "user@example.com",
+	"uri":       "https://example.com",
+	"hostname":  "example.com",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"uuid":      "00000000-0000-0000-0000-000000000000",
+}
+
+// exampleArguments returns an example argument object of the tool for operation as indented JSON.
+// Resource specs contain their required fields and the fields with a default.
+func exampleArguments(toolsetInfo *analyzer.ToolsetInfo, operation string) (string, error) {
+	name := "example-" + toLower(toolsetInfo.CRD.Kind)
+	namespace := toolsetInfo.GetDefaultNamespace()
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	args := map[string]any{}
+	switch operation {
+	case "create", "update":
+		metadata := map[string]any{"name": name}
+		if toolsetInfo.CRD.IsNamespaced() {
+			metadata["namespace"] = namespace
+		}
+		resource := map[string]any{"metadata": metadata}
+		if toolsetInfo.SpecType != nil {
+			spec := map[string]any{}
+			if specSchema, ok := toolsetInfo.Schema.Properties["spec"]; ok {
+				if value, ok := exampleValue(&specSchema).(map[string]any); ok {
+					spec = value
+				}
+			}
+			resource["spec"] = spec
+		}
+		args["args"] = resource
+	case "get", "delete":
+		args["name"] = name
+		if toolsetInfo.CRD.IsNamespaced() {
+			args["namespace"] = namespace
+		}
+	case "list":
+		if toolsetInfo.CRD.IsNamespaced() {
+			args["namespace"] = namespace
+		}
+	case "find":
+		args["labelSelector"] = "app.kubernetes.io/name=" + name
+		if toolsetInfo.CRD.IsNamespaced() {
+			args["namespace"] = namespace
+		}
+	case "scale":
+		args["name"] = name
+		if toolsetInfo.CRD.IsNamespaced() {
+			args["namespace"] = namespace
+		}
+		args["replicas"] = 3
+	default:
+		return "", fmt.Errorf("unknown operation %q", operation)
+	}
+
+	content, err := json.MarshalIndent(args, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal example arguments for %s: %w", operation, err)
+	}
+	return string(content), nil
+}
+
+// exampleValue returns an example value the schema accepts: its default or first enum value if
+// it has one, or else a value built from its type. Objects are built recursively from their
+// required properties and the properties with a default.
+func exampleValue(schema *apiextensionsv1.JSONSchemaProps) any {
+	if schema.Default != nil && len(schema.Default.Raw) > 0 {
+		var value any
+		if err := json.Unmarshal(schema.Default.Raw, &value); err == nil {
+			return value
+		}
+	}
+	if len(schema.Enum) > 0 {
+		var value any
+		if err := json.Unmarshal(schema.Enum[0].Raw, &value); err == nil {
+			return value
+		}
+	}
+	if schema.XIntOrString {
+		return 1
+	}
+	if schema.XPreserveUnknownFields != nil && *schema.XPreserveUnknownFields {
+		return map[string]any{}
+	}
+
+	// Schemas without a type of their own take the first alternative
+	if schema.Type == "" {
+		for _, alternatives := range [][]apiextensionsv1.JSONSchemaProps{schema.OneOf, schema.AnyOf} {
+			if len(alternatives) > 0 {
+				return exampleValue(&alternatives[0])
+			}
+		}
+	}
+
+	switch schema.Type {
+	case "object":
+		return exampleObject(schema)
+	case "array":
+		if schema.Items != nil && schema.Items.Schema != nil {
+			return []any{exampleValue(schema.Items.Schema)}
+		}
+		return []any{}
+	case "string":
+		if example, ok := exampleStrings[schema.Format]; ok {
+			return example
+		}
+		example := "example"
+		if schema.MinLength != nil && int64(len(example)) < *schema.MinLength {
+			example += strings.Repeat("x", int(*schema.MinLength)-len(example))
+		}
+		return example
+	case "integer", "number":
+		return exampleNumber(schema)
+	case "boolean":
+		return true
+	default:
+		if len(schema.Properties) > 0 || len(schema.AllOf) > 0 {
+			return exampleObject(schema)
+		}
+		return nil
+	}
+}
+
+// exampleObject returns an example object with the required properties and the properties with
+// a default of the schema and of its allOf parts
+func exampleObject(schema *apiextensionsv1.JSONSchemaProps) map[string]any {
+	object := map[string]any{}
+	for name, property := range schema.Properties {
+		hasDefault := property.Default != nil && len(property.Default.Raw) > 0
+		if hasDefault || slices.Contains(schema.Required, name) {
+			object[name] = exampleValue(&property)
+		}
+	}
+	for i := range schema.AllOf {
+		for name, value := range exampleObject(&schema.AllOf[i]) {
+			if _, ok := object[name]; !ok {
+				object[name] = value
+			}
+		}
+	}
+	// Required properties only declared in the required list get a placeholder
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			object[name] = "example"
+		}
+	}
+	return object
+}
+
+// exampleNumber returns 1, moved to the nearest whole number within the minimum and maximum
+func exampleNumber(schema *apiextensionsv1.JSONSchemaProps) any {
+	value := 1.0
+	if schema.Minimum != nil && *schema.Minimum > value {
+		value = math.Ceil(*schema.Minimum)
+	}
+	if schema.Maximum != nil && *schema.Maximum < value {
+		value = math.Floor(*schema.Maximum)
+	}
+	return json.Number(strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// examplePrompt returns the text of the prompt with example arguments of the tool for operation,
+// as a Go string literal
+func examplePrompt(toolsetInfo *analyzer.ToolsetInfo, operation string) (string, error) {
+	args, err := exampleArguments(toolsetInfo, operation)
+	if err != nil {
+		return "", err
+	}
+
+	prompt := fmt.Sprintf("Example arguments for the %s tool, which can %s %s resources. "+
+		"Required fields are filled in with placeholder values and optional fields with their defaults; "+
+		"replace them with the values you need:\n\n%s\n",
+		generateToolName(toolsetInfo.Config.ToolPrefix, operation, toolsetInfo.CRD.Plural), operation, toolsetInfo.CRD.Kind, args)
+
+	// A raw string keeps the JSON readable in the generated code
+	if !strings.Contains(prompt, "`") {
+		return "`" + prompt + "`", nil
+	}
+	return strconv.Quote(prompt), nil
+}
@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestExampleArgumentsCreateHasRequiredSpecFields(t *testing.T) {
+	for _, crdFile := range []string{
+		"../../test/fixtures/simple-crd.yaml",
+		"../../test/fixtures/complex-crd.yaml",
+	} {
+		t.Run(crdFile, func(t *testing.T) {
+			toolsetInfo := loadDiffTestToolset(t, crdFile)
+
+			content, err := exampleArguments(toolsetInfo, "create")
+			require.NoError(t, err)
+
+			var args struct {
+				Args struct {
+					Metadata map[string]any `json:"metadata"`
+					Spec     map[string]any `json:"spec"`
+				} `json:"args"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(content), &args))
+			assert.NotEmpty(t, args.Args.Metadata["name"])
+
+			specSchema := toolsetInfo.Schema.Properties["spec"]
+			assertRequiredFields(t, &specSchema, args.Args.Spec, "spec")
+		})
+	}
+}
+
+// assertRequiredFields asserts that object has every required property of schema, recursing into
+// the properties that are objects themselves
+func assertRequiredFields(t *testing.T, schema *apiextensionsv1.JSONSchemaProps, object map[string]any, path string) {
+	t.Helper()
+
+	for _, name := range schema.Required {
+		require.Contains(t, object, name, "%s.%s is required", path, name)
+		if nested, ok := object[name].(map[string]any); ok {
+			property := schema.Properties[name]
+			assertRequiredFields(t, &property, nested, path+"."+name)
+		}
+	}
+}
+
+func TestExampleArgumentsByOperation(t *testing.T) {
+	toolsetInfo := loadDiffTestToolset(t, "../../test/fixtures/simple-crd.yaml")
+
+	content, err := exampleArguments(toolsetInfo, "get")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name": "example-widget", "namespace": "default"}`, content)
+
+	content, err = exampleArguments(toolsetInfo, "create")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"args": {"metadata": {"name": "example-widget", "namespace": "default"}, "spec": {"name": "example"}}}`, content)
+
+	clusterScoped := loadDiffTestToolset(t, "../../test/fixtures/cluster-scoped-crd.yaml")
+	content, err = exampleArguments(clusterScoped, "list")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, content, "cluster-scoped resources have no namespace argument")
+
+	_, err = exampleArguments(toolsetInfo, "patch")
+	assert.ErrorContains(t, err, `unknown operation "patch"`)
+}
+
+func TestExampleValue(t *testing.T) {
+	minimum, maximum := 2.5, 10.0
+	minLength := int64(10)
+	preserveUnknownFields := true
+	schema := &apiextensionsv1.JSONSchemaProps{
+		Type:     "object",
+		Required: []string{"mode", "size", "created", "name", "tags", "port", "extra"},
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"mode":     {Type: "string", Enum: []apiextensionsv1.JSON{{Raw: []byte(`"fast"`)}}},
+			"size":     {Type: "integer", Minimum: &minimum, Maximum: &maximum},
+			"created":  {Type: "string", Format: "date-time"},
+			"name":     {Type: "string", MinLength: &minLength},
+			"tags":     {Type: "array", Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{Type: "string"}}},
+			"port":     {XIntOrString: true},
+			"extra":    {Type: "object", XPreserveUnknownFields: &preserveUnknownFields},
+			"replicas": {Type: "integer", Default: &apiextensionsv1.JSON{Raw: []byte(`3`)}},
+			"optional": {Type: "string"},
+		},
+	}
+
+	content, err := json.Marshal(exampleValue(schema))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"mode": "fast",
+		"size": 3,
+		"created": "2024-01-01T00:00:00Z",
+		"name": "examplexxx",
+		"tags": ["example"],
+		"port": 1,
+		"extra": {},
+		"replicas": 3
+	}`, string(content))
+}
+
+func TestExamplePrompt(t *testing.T) {
+	toolsetInfo := loadDiffTestToolset(t, "../../test/fixtures/simple-crd.yaml")
+
+	literal, err := examplePrompt(toolsetInfo, "delete")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(literal, "`Example arguments for the widgets_delete tool"), literal)
+	assert.Contains(t, literal, `"name": "example-widget"`)
+}
@@ -3,9 +3,12 @@
 package generator
 
 import (
+	"bytes"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"text/template"
 
 	"github.com/friedrichwilken/mcp-toolgen/pkg/analyzer"
@@ -15,6 +18,52 @@ import (
 type Generator struct {
 	config    *GeneratorConfig
 	templates *template.Template
+	logger    *slog.Logger
+	header    string // Build constraint, license and generated marker lines prepended to every generated file
+}
+
+// toolsetFile is a file of a generated toolset and the template it is rendered from
+type toolsetFile struct {
+	template string
+	filename string
+}
+
+// toolsetFiles lists the files of a generated toolset
+var toolsetFiles = []toolsetFile{
+	{"toolset.go.tmpl", "toolset.go"},
+	{"types.go.tmpl", "types.go"},
+	{"register.go.tmpl", "register.go"},
+	{"client.go.tmpl", "client.go"},
+	{"handlers.go.tmpl", "handlers.go"},
+	{"options.go.tmpl", "options.go"},
+	{"errors.go.tmpl", "errors.go"},
+	{"schema.go.tmpl", "schema.go"},
+	{"doc.go.tmpl", "doc.go"},
+}
+
+// promptsFile registers an MCP prompt with example arguments per tool, generated when
+// GenerationConfig.GeneratePrompts is set
+var promptsFile = toolsetFile{"prompts.go.tmpl", "prompts.go"}
+
+// resourcesFile registers the MCP resources with the CRD manifest and its documentation, generated
+// when GenerationConfig.GenerateCRDResource or GenerationConfig.GenerateDocResource is set
+var resourcesFile = toolsetFile{"resources.go.tmpl", "resources.go"}
+
+// testFile is the test of the generated operations, generated when GenerationConfig.GenerateTests
+// is set. It stays a separate file in single-file mode, as tests cannot share a file with code.
+var testFile = toolsetFile{"handlers_test.go.tmpl", "handlers_test.go"}
+
+// codeFiles returns the files with the code of a toolset, including the optional ones its
+// generation config enables
+func codeFiles(toolsetInfo *analyzer.ToolsetInfo) []toolsetFile {
+	files := slices.Clone(toolsetFiles)
+	if toolsetInfo.Config.GenerateCRDResource || toolsetInfo.Config.GenerateDocResource {
+		files = append(files, resourcesFile)
+	}
+	if toolsetInfo.Config.GeneratePrompts {
+		files = append(files, promptsFile)
+	}
+	return files
 }
 
 // GeneratorConfig holds configuration for code generation
@@ -25,6 +74,29 @@ type GeneratorConfig struct {
 	ModulePath      string
 	OverwriteFiles  bool
 	IncludeComments bool
+	Logger          *slog.Logger // Receives a "generated file" event per written file; nil discards them
+	BuildTag        string       // Build constraint expression the generated files are guarded by, e.g. mytools
+	Header          string       // License header text for every generated file; {{.Year}} is replaced with the current year
+	MergeCustom     bool         // Keep the custom regions of existing files when overwriting them
+	SingleFile      bool         // Generate one <package>.go file instead of a file per template, without doc.go
+	Prune           bool         // Remove generated .go files of the output directory that are no longer generated
+	Force           bool         // Remove the output directory before generating, if it only holds files mcp-toolgen wrote
+	ForceClean      bool         // With Force, also remove the output directory if it holds other files
+	ClientQPS       float32      // Queries per second of clients created from a REST config; 0 keeps the client-go default
+	ClientBurst     int          // Request burst of clients created from a REST config; 0 keeps the client-go default
+	SchemaDraft     string       // Key of SchemaDrafts the tool schemas declare with $schema; empty declares none, which the go-sdk reads as 2020-12
+	VerifyCompiles  bool         // Type-check the written toolset and fail if it does not compile; slow, as imports are resolved with go list
+	AlwaysWrite     bool         // Also write files whose content is unchanged, instead of leaving them untouched
+
+	// DescriptionMaxLength is the number of characters CRD descriptions are truncated to, with an
+	// ellipsis, in the tool schemas and field comments; 0 keeps them whole
+	DescriptionMaxLength int
+}
+
+// SchemaDrafts maps the JSON Schema drafts the generated tool schemas can declare to their $schema URI
+var SchemaDrafts = map[string]string{
+	"2020-12":  "https://json-schema.org/draft/2020-12/schema",
+	"draft-07": "http://json-schema.org/draft-07/schema#",
 }
 
 // NewGenerator creates a new code generator
@@ -37,9 +109,27 @@ func NewGenerator(config *GeneratorConfig) (*Generator, error) {
 		return nil, fmt.Errorf("output directory is required")
 	}
 
+	if _, ok := SchemaDrafts[config.SchemaDraft]; config.SchemaDraft != "" && !ok {
+		return nil, fmt.Errorf("unknown JSON Schema draft %q", config.SchemaDraft)
+	}
+
+	if config.DescriptionMaxLength < 0 {
+		return nil, fmt.Errorf("description max length must not be negative")
+	}
+
 	generator := &Generator{
 		config: config,
+		logger: config.Logger,
+	}
+	if generator.logger == nil {
+		generator.logger = slog.New(slog.DiscardHandler)
+	}
+
+	header, err := FileHeader(config.BuildTag, config.Header)
+	if err != nil {
+		return nil, err
 	}
+	generator.header = header + generatedMarker + "\n\n"
 
 	// Load templates
 	if err := generator.loadTemplates(); err != nil {
@@ -55,77 +145,153 @@ func (g *Generator) GenerateToolset(toolsetInfo *analyzer.ToolsetInfo) error {
 		return fmt.Errorf("toolset info is required")
 	}
 
+	// The files are rendered before the old ones are removed, so a template error leaves them in place
+	files, err := g.renderToolset(toolsetInfo)
+	if err != nil {
+		return err
+	}
+
+	if g.config.Force {
+		if err := g.removeOutputDir(); err != nil {
+			return err
+		}
+	}
+
 	// Create output directory if it doesn't exist
 	if err := os.MkdirAll(g.config.OutputDir, 0o755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
 	// Generate each file
-	files := []struct {
-		template string
-		filename string
-	}{
-		{"toolset.go.tmpl", "toolset.go"},
-		{"types.go.tmpl", "types.go"},
-		{"client.go.tmpl", "client.go"},
-		{"handlers.go.tmpl", "handlers.go"},
-		{"schema.go.tmpl", "schema.go"},
-		{"doc.go.tmpl", "doc.go"},
-	}
-
 	for _, file := range files {
-		if err := g.generateFile(toolsetInfo, file.template, file.filename); err != nil {
+		if err := g.writeFile(file); err != nil {
 			return fmt.Errorf("failed to generate %s: %w", file.filename, err)
 		}
 	}
 
+	// Stale files are only removed once the new ones are in place
+	if g.config.Prune {
+		if err := g.pruneStaleFiles(files); err != nil {
+			return err
+		}
+	}
+
+	if g.config.VerifyCompiles {
+		if err := g.verifyCompiles(files); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-// generateFile generates a single file from a template
-func (g *Generator) generateFile(toolsetInfo *analyzer.ToolsetInfo, templateName, filename string) error {
-	// Check if file exists and we're not overwriting
-	outputPath := filepath.Join(g.config.OutputDir, filename)
-	if !g.config.OverwriteFiles {
-		if _, err := os.Stat(outputPath); err == nil {
-			return fmt.Errorf("file %s already exists and overwrite is disabled", outputPath)
+// OutputFilenames returns the names of the files a toolset is generated into
+func (g *Generator) OutputFilenames(toolsetInfo *analyzer.ToolsetInfo) []string {
+	var filenames []string
+	if g.config.SingleFile {
+		filenames = []string{g.singleFilename()}
+	} else {
+		for _, file := range codeFiles(toolsetInfo) {
+			filenames = append(filenames, file.filename)
 		}
 	}
+	if toolsetInfo.Config.GenerateTests {
+		filenames = append(filenames, testFile.filename)
+	}
+	return filenames
+}
 
-	// Execute template
+// renderedFile is the unformatted content of a toolset file
+type renderedFile struct {
+	filename string
+	content  string
+}
+
+// renderToolset renders the files of the toolset, or the one combined file in single-file mode
+func (g *Generator) renderToolset(toolsetInfo *analyzer.ToolsetInfo) ([]renderedFile, error) {
+	var files []renderedFile
+	var templateFiles []toolsetFile
+	if g.config.SingleFile {
+		content, err := g.renderSingleFile(toolsetInfo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", g.singleFilename(), err)
+		}
+		files = append(files, renderedFile{g.singleFilename(), content})
+	} else {
+		templateFiles = append(templateFiles, codeFiles(toolsetInfo)...)
+	}
+	if toolsetInfo.Config.GenerateTests {
+		templateFiles = append(templateFiles, testFile)
+	}
+
+	for _, file := range templateFiles {
+		content, err := g.renderFile(toolsetInfo, file.template)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", file.filename, err)
+		}
+		files = append(files, renderedFile{file.filename, content})
+	}
+	return files, nil
+}
+
+// writeFile writes a rendered file to the output directory
+func (g *Generator) writeFile(file renderedFile) error {
+	// Write the output with imports fixed up and gofmt applied
+	writer := NewFileWriter(g.config.OutputDir, g.config.OverwriteFiles, true, g.logger)
+	writer.SetAlwaysWrite(g.config.AlwaysWrite)
+	write := writer.writeFile
+	if g.config.MergeCustom {
+		write = writer.writeFileMerging
+	}
+	written, err := write(file.filename, file.content)
+	if err != nil {
+		return err
+	}
+
+	if !written {
+		g.logger.Debug("skipped unchanged file", "filename", file.filename, "dir", g.config.OutputDir)
+		return nil
+	}
+	g.logger.Info("generated file", "filename", file.filename, "dir", g.config.OutputDir)
+	return nil
+}
+
+// renderFile executes a template and returns the unformatted output
+func (g *Generator) renderFile(toolsetInfo *analyzer.ToolsetInfo, templateName string) (string, error) {
+	content, err := g.executeTemplate(toolsetInfo, templateName)
+	if err != nil {
+		return "", err
+	}
+	return g.header + content, nil
+}
+
+// executeTemplate executes a template without prepending the file header
+func (g *Generator) executeTemplate(toolsetInfo *analyzer.ToolsetInfo, templateName string) (string, error) {
 	tmpl := g.templates.Lookup(templateName)
 	if tmpl == nil {
-		return fmt.Errorf("template %s not found", templateName)
+		return "", fmt.Errorf("template %s not found", templateName)
 	}
 
 	// Create template data
 	data := g.createTemplateData(toolsetInfo)
 
-	// Create output file
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create output file %s: %w", outputPath, err)
-	}
-	defer func() {
-		if closeErr := file.Close(); closeErr != nil {
-			// Log error but don't override the main error
-		}
-	}()
-
-	// Execute template
-	if err := tmpl.Execute(file, data); err != nil {
-		return fmt.Errorf("failed to execute template %s: %w", templateName, err)
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template %s: %w", templateName, err)
 	}
 
-	return nil
+	return buf.String(), nil
 }
 
 // createTemplateData creates the data structure passed to templates
 func (g *Generator) createTemplateData(toolsetInfo *analyzer.ToolsetInfo) map[string]interface{} {
-	return map[string]interface{}{
+	data := map[string]interface{}{
 		"Package":             g.config.PackageName,
 		"ModulePath":          g.config.ModulePath,
 		"IncludeComments":     g.config.IncludeComments,
+		"ClientQPS":           g.config.ClientQPS,
+		"ClientBurst":         g.config.ClientBurst,
+		"SchemaDialect":       SchemaDrafts[g.config.SchemaDraft],
 		"GenerateCRDResource": toolsetInfo.Config.GenerateCRDResource,
 		"GenerateDocResource": toolsetInfo.Config.GenerateDocResource,
 		"Toolset":             toolsetInfo,
@@ -135,35 +301,30 @@ func (g *Generator) createTemplateData(toolsetInfo *analyzer.ToolsetInfo) map[st
 		"StatusType":          toolsetInfo.StatusType,
 		"ListType":            toolsetInfo.ListType,
 		"Operations":          toolsetInfo.GetResourceOperations(),
+		"CustomOperations":    toolsetInfo.Config.CustomOperations,
+		"ToolCount":           len(toolsetInfo.GetResourceOperations()) + len(toolsetInfo.Config.CustomOperations),
 		"Imports":             toolsetInfo.GetImports(),
 		"KubernetesImports":   toolsetInfo.GetKubernetesImports(),
 		"MCPImports":          toolsetInfo.GetMCPImports(),
+	}
 
-		// Helper functions for templates
-		"ToLower":               toLower,
-		"ToUpper":               toUpper,
-		"ToTitle":               toTitle,
-		"ToCamelCase":           toCamelCase,
-		"ToSnakeCase":           toSnakeCase,
-		"Pluralize":             pluralize,
-		"Contains":              contains,
-		"Join":                  join,
-		"Quote":                 quote,
-		"ConvertSchemaToGoCode": convertSchemaToGoCode,
+	// The helper functions are also passed as data, for templates calling them as {{call .ToLower .CRD.Kind}}
+	for name, fn := range templateFuncs(g.config.DescriptionMaxLength) {
+		data[name] = fn
 	}
+	return data
 }
 
 // loadTemplates loads all template files
 func (g *Generator) loadTemplates() error {
 	templateDir := g.config.TemplateDir
 	if templateDir == "" {
-		// Use embedded templates (we'll implement this)
 		return g.loadEmbeddedTemplates()
 	}
 
-	// Load templates from directory
+	// Load templates from directory, with the helper functions of the embedded templates
 	pattern := filepath.Join(templateDir, "*.tmpl")
-	templates, err := template.ParseGlob(pattern)
+	templates, err := template.New("").Funcs(templateFuncs(g.config.DescriptionMaxLength)).ParseGlob(pattern)
 	if err != nil {
 		return fmt.Errorf("failed to parse templates from %s: %w", pattern, err)
 	}
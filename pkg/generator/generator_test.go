@@ -1,8 +1,18 @@
 package generator
 
 import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log/slog"
+	"os"
+	"path"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -55,6 +65,28 @@ func TestNewGeneratorValidation(t *testing.T) {
 			wantError: true,
 			errorMsg:  "output directory is required",
 		},
+		{
+			name: "invalid build tag",
+			config: &GeneratorConfig{
+				OutputDir:   "/tmp/test",
+				PackageName: "testpkg",
+				ModulePath:  "github.com/test/module",
+				BuildTag:    "mytools &&",
+			},
+			wantError: true,
+			errorMsg:  `invalid build tag "mytools &&"`,
+		},
+		{
+			name: "unknown schema draft",
+			config: &GeneratorConfig{
+				OutputDir:   "/tmp/test",
+				PackageName: "testpkg",
+				ModulePath:  "github.com/test/module",
+				SchemaDraft: "draft-04",
+			},
+			wantError: true,
+			errorMsg:  `unknown JSON Schema draft "draft-04"`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -106,7 +138,7 @@ func TestGenerateFromCRDFile(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify all expected files were created
-	expectedFiles := []string{"toolset.go", "types.go", "client.go", "handlers.go", "schema.go", "doc.go"}
+	expectedFiles := []string{"toolset.go", "types.go", "register.go", "client.go", "handlers.go", "errors.go", "schema.go", "doc.go"}
 	for _, filename := range expectedFiles {
 		filePath := filepath.Join(config.OutputDir, filename)
 		assert.FileExists(t, filePath, "Expected file %s to exist", filename)
@@ -146,7 +178,7 @@ func TestGenerateFromCRDFileComplex(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify files were created
-	expectedFiles := []string{"toolset.go", "types.go", "client.go", "handlers.go", "schema.go", "doc.go"}
+	expectedFiles := []string{"toolset.go", "types.go", "register.go", "client.go", "handlers.go", "errors.go", "schema.go", "doc.go"}
 	for _, filename := range expectedFiles {
 		filePath := filepath.Join(config.OutputDir, filename)
 		assert.FileExists(t, filePath, "Expected file %s to exist", filename)
@@ -346,9 +378,1045 @@ func TestGenerateFromCRDStruct(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify files were created
-	expectedFiles := []string{"toolset.go", "types.go", "client.go", "handlers.go", "schema.go", "doc.go"}
+	expectedFiles := []string{"toolset.go", "types.go", "register.go", "client.go", "handlers.go", "errors.go", "schema.go", "doc.go"}
 	for _, filename := range expectedFiles {
 		filePath := filepath.Join(config.OutputDir, filename)
 		assert.FileExists(t, filePath, "Expected file %s to exist", filename)
 	}
 }
+
+func TestGenerateWithEmbeddedTemplates(t *testing.T) {
+	crdPath, err := filepath.Abs("../../test/fixtures/simple-crd.yaml")
+	require.NoError(t, err)
+
+	// Run from a directory without a templates tree so nothing can be read from disk
+	t.Chdir(t.TempDir())
+
+	crdInfo, err := analyzer.NewCRDAnalyzer().ParseCRDFromFile(crdPath)
+	require.NoError(t, err)
+
+	outputDir := t.TempDir()
+	config := analyzer.DefaultGenerationConfig()
+	config.PackageName = "widgets"
+	config.ModulePath = "github.com/test/module"
+	config.OutputDir = outputDir
+
+	toolsetInfo, err := analyzer.NewToolsetInfo(crdInfo, config)
+	require.NoError(t, err)
+
+	gen, err := NewGenerator(&GeneratorConfig{
+		OutputDir:      outputDir,
+		PackageName:    "widgets",
+		ModulePath:     "github.com/test/module",
+		OverwriteFiles: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, gen.GenerateToolset(toolsetInfo))
+
+	for _, filename := range []string{"toolset.go", "types.go", "client.go", "handlers.go", "errors.go", "schema.go", "doc.go"} {
+		content, err := os.ReadFile(filepath.Join(outputDir, filename))
+		require.NoError(t, err, "expected %s to be generated", filename)
+		assert.Contains(t, string(content), "package widgets")
+	}
+}
+
+func TestGenerateWithTemplateDir(t *testing.T) {
+	// A copy of the embedded templates with doc.go replaced by a custom template calling helpers
+	templateDir := t.TempDir()
+	embedded, err := filepath.Glob("templates/*.tmpl")
+	require.NoError(t, err)
+	for _, file := range embedded {
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(templateDir, filepath.Base(file)), content, 0o644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(templateDir, "doc.go.tmpl"), []byte(`// Package {{.Package}} serves {{ToPascalCase .CRD.Plural}} as {{generateToolName "" "get" .CRD.Plural}} and friends.
+package {{.Package}}
+
+// {{ToCamelCase .CRD.Kind}}SpecSchema is the schema of the {{.CRD.Kind}} spec
+var {{ToCamelCase .CRD.Kind}}SpecSchema = {{ConvertSchemaToGoCode (index .Toolset.Schema.Properties "spec") 0}}
+`), 0o644))
+
+	crdInfo, err := analyzer.NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/simple-crd.yaml")
+	require.NoError(t, err)
+
+	outputDir := t.TempDir()
+	config := analyzer.DefaultGenerationConfig()
+	config.PackageName = "widgets"
+	config.OutputDir = outputDir
+
+	toolsetInfo, err := analyzer.NewToolsetInfo(crdInfo, config)
+	require.NoError(t, err)
+
+	gen, err := NewGenerator(&GeneratorConfig{
+		OutputDir:      outputDir,
+		TemplateDir:    templateDir,
+		PackageName:    "widgets",
+		ModulePath:     "github.com/test/module",
+		OverwriteFiles: true,
+	})
+	require.NoError(t, err, "templates of a directory should be able to call the helper functions")
+	require.NoError(t, gen.GenerateToolset(toolsetInfo))
+
+	doc, err := os.ReadFile(filepath.Join(outputDir, "doc.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(doc), "// Package widgets serves Widgets as widgets_get and friends.")
+	assert.Contains(t, string(doc), "var widgetSpecSchema = &jsonschema.Schema{")
+	assert.Contains(t, string(doc), `Required: []string{"name"},`)
+}
+
+func TestGenerateTemplateStringHelpers(t *testing.T) {
+	templateDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(templateDir, "names.go.tmpl"), []byte(
+		`{{ToKebabCase .CRD.Kind}} {{Singularize .CRD.Plural}} {{ToPascalCase .CRD.Plural}} {{call .ToKebabCase "GlobalConfig"}}
+{{WrapComment "Widgets are small things" 16}}
+{{Indent "a\nb" "  "}}`), 0o644))
+
+	crdInfo, err := analyzer.NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/simple-crd.yaml")
+	require.NoError(t, err)
+	toolsetInfo, err := analyzer.NewToolsetInfo(crdInfo, analyzer.DefaultGenerationConfig())
+	require.NoError(t, err)
+
+	gen, err := NewGenerator(&GeneratorConfig{OutputDir: t.TempDir(), TemplateDir: templateDir, PackageName: "widgets"})
+	require.NoError(t, err)
+
+	content, err := gen.executeTemplate(toolsetInfo, "names.go.tmpl")
+	require.NoError(t, err)
+	assert.Equal(t, "widget widget Widgets global-config\n// Widgets are\n// small things\n  a\n  b", content)
+}
+
+func TestGenerateBuildTag(t *testing.T) {
+	crdInfo, err := analyzer.NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/simple-crd.yaml")
+	require.NoError(t, err)
+
+	outputDir := t.TempDir()
+	config := analyzer.DefaultGenerationConfig()
+	config.PackageName = "widgets"
+	config.OutputDir = outputDir
+
+	toolsetInfo, err := analyzer.NewToolsetInfo(crdInfo, config)
+	require.NoError(t, err)
+
+	gen, err := NewGenerator(&GeneratorConfig{
+		OutputDir:       outputDir,
+		PackageName:     "widgets",
+		ModulePath:      "github.com/test/module",
+		OverwriteFiles:  true,
+		IncludeComments: true,
+		BuildTag:        "mytools && !nomcp",
+	})
+	require.NoError(t, err)
+	require.NoError(t, gen.GenerateToolset(toolsetInfo))
+
+	for _, file := range toolsetFiles {
+		content, err := os.ReadFile(filepath.Join(outputDir, file.filename))
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(content), "//go:build mytools && !nomcp\n// +build mytools,!nomcp\n\n"),
+			"%s should start with the build constraint", file.filename)
+		assert.Contains(t, string(content), "\npackage widgets\n", file.filename)
+	}
+}
+
+func TestGenerateSkipsUnchangedFiles(t *testing.T) {
+	toolsetInfo := loadDiffTestToolset(t, "../../test/fixtures/simple-crd.yaml")
+	outputDir := t.TempDir()
+
+	// generate runs a generation and returns the number of files it reported as written
+	generate := func(alwaysWrite bool) int {
+		var logs bytes.Buffer
+		gen, err := NewGenerator(&GeneratorConfig{
+			OutputDir:       outputDir,
+			PackageName:     "widgets",
+			ModulePath:      "github.com/test/module",
+			OverwriteFiles:  true,
+			IncludeComments: true,
+			Logger:          slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+			AlwaysWrite:     alwaysWrite,
+		})
+		require.NoError(t, err)
+		require.NoError(t, gen.GenerateToolset(toolsetInfo))
+		return strings.Count(logs.String(), `msg="generated file"`)
+	}
+
+	require.Equal(t, len(toolsetFiles), generate(false))
+
+	// Backdate the files, so a rewrite would show in their modification time
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, file := range toolsetFiles {
+		require.NoError(t, os.Chtimes(filepath.Join(outputDir, file.filename), past, past))
+	}
+
+	assert.Zero(t, generate(false), "the second pass should not write unchanged files")
+	for _, file := range toolsetFiles {
+		info, err := os.Stat(filepath.Join(outputDir, file.filename))
+		require.NoError(t, err)
+		assert.True(t, info.ModTime().Equal(past), "%s should keep its modification time", file.filename)
+	}
+
+	assert.Equal(t, len(toolsetFiles), generate(true), "AlwaysWrite should write every file")
+}
+
+func TestFileWriterLogsFormatFailures(t *testing.T) {
+	var logs bytes.Buffer
+	outputDir := t.TempDir()
+	writer := NewFileWriter(outputDir, true, true, slog.New(slog.NewTextHandler(&logs, nil)))
+
+	// Content that does not parse is written unformatted, with a warning instead of an error
+	broken := "package widgets\n\nfunc broken( {\n"
+	require.NoError(t, writer.WriteFile("broken.go", broken))
+	content, err := os.ReadFile(filepath.Join(outputDir, "broken.go"))
+	require.NoError(t, err)
+	assert.Equal(t, broken, string(content))
+
+	assert.Contains(t, logs.String(), `level=WARN msg="failed to format file" filename=broken.go error=`)
+}
+
+func TestGenerateClientRateLimits(t *testing.T) {
+	crdInfo, err := analyzer.NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/simple-crd.yaml")
+	require.NoError(t, err)
+
+	outputDir := t.TempDir()
+	config := analyzer.DefaultGenerationConfig()
+	config.PackageName = "widgets"
+	config.OutputDir = outputDir
+
+	toolsetInfo, err := analyzer.NewToolsetInfo(crdInfo, config)
+	require.NoError(t, err)
+
+	gen, err := NewGenerator(&GeneratorConfig{
+		OutputDir:       outputDir,
+		PackageName:     "widgets",
+		ModulePath:      "github.com/test/module",
+		OverwriteFiles:  true,
+		IncludeComments: true,
+		ClientQPS:       12.5,
+		ClientBurst:     40,
+	})
+	require.NoError(t, err)
+	require.NoError(t, gen.GenerateToolset(toolsetInfo))
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "client.go"))
+	require.NoError(t, err)
+	client := string(content)
+	assert.Regexp(t, `WidgetClientQPS\s+float32 = 12\.5\n`, client)
+	assert.Regexp(t, `WidgetClientBurst\s+= 40\n`, client)
+	assert.Contains(t, client, "return NewWidgetClientForConfigWithRateLimits(cfg, namespace, WidgetClientQPS, WidgetClientBurst)")
+	assert.Contains(t, client, "func NewWidgetClientForConfigWithRateLimits(cfg *rest.Config, namespace string, qps float32, burst int) (*WidgetClient, error) {")
+	assert.Contains(t, client, "cfg = rest.CopyConfig(cfg)", "the caller's config should not be modified")
+}
+
+func TestGenerateSchemaDraft(t *testing.T) {
+	crdPath := filepath.Join(t.TempDir(), "crd.yaml")
+	require.NoError(t, os.WriteFile(crdPath, []byte(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+    plural: widgets
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              ratio:
+                type: number
+                minimum: 0
+                exclusiveMinimum: true
+                maximum: 1
+              replicas:
+                type: integer
+                minimum: 1
+                maximum: 10
+                exclusiveMaximum: true
+`), 0o644))
+
+	generate := func(draft string) map[string]string {
+		crdInfo, err := analyzer.NewCRDAnalyzer().ParseCRDFromFile(crdPath)
+		require.NoError(t, err)
+
+		config := analyzer.DefaultGenerationConfig()
+		config.PackageName = "widgets"
+		config.OutputDir = t.TempDir()
+		config.SelectedOperations = []string{"get", "create"}
+		toolsetInfo, err := analyzer.NewToolsetInfo(crdInfo, config)
+		require.NoError(t, err)
+
+		gen, err := NewGenerator(&GeneratorConfig{
+			OutputDir:       config.OutputDir,
+			PackageName:     "widgets",
+			ModulePath:      "github.com/test/module",
+			OverwriteFiles:  true,
+			IncludeComments: true,
+			SchemaDraft:     draft,
+		})
+		require.NoError(t, err)
+		require.NoError(t, gen.GenerateToolset(toolsetInfo))
+
+		files := make(map[string]string)
+		for _, name := range []string{"schema.go", "toolset.go"} {
+			content, err := os.ReadFile(filepath.Join(config.OutputDir, name))
+			require.NoError(t, err)
+			files[name] = string(content)
+		}
+		return files
+	}
+
+	// The boolean exclusive bounds of the CRD are emitted in the numeric form both drafts use
+	for _, draft := range []string{"", "2020-12", "draft-07"} {
+		schema := generate(draft)["schema.go"]
+		assert.Regexp(t, `ExclusiveMinimum:\s+ptr\.To\(float64\(0\)\),`, schema, draft)
+		assert.Regexp(t, `Maximum:\s+ptr\.To\(float64\(1\)\),`, schema, draft)
+		assert.Regexp(t, `Minimum:\s+ptr\.To\(float64\(1\)\),`, schema, draft)
+		assert.Regexp(t, `ExclusiveMaximum:\s+ptr\.To\(float64\(10\)\),`, schema, draft)
+		assert.NotRegexp(t, `\bMinimum:\s+ptr\.To\(float64\(0\)\)`, schema, draft)
+	}
+
+	files := generate("")
+	assert.NotContains(t, files["schema.go"], "withSchemaDialect", "without a draft no $schema is declared")
+	assert.Contains(t, files["toolset.go"], "InputSchema: getWidgetSchema(),")
+
+	files = generate("draft-07")
+	assert.Contains(t, files["schema.go"], `s.Schema = "http://json-schema.org/draft-07/schema#"`)
+	assert.Contains(t, files["toolset.go"], "InputSchema: withSchemaDialect(getWidgetSchema()),")
+
+	files = generate("2020-12")
+	assert.Contains(t, files["schema.go"], `s.Schema = "https://json-schema.org/draft/2020-12/schema"`)
+}
+
+func TestGenerateSchemaConstraints(t *testing.T) {
+	schema := generateFromTemplates(t, "../../test/fixtures/constraints-crd.yaml", []string{"create"})["schema.go"]
+
+	for _, constraint := range []string{
+		`ExclusiveMinimum:\s+ptr\.To\(float64\(0\)\),`,
+		`ExclusiveMaximum:\s+ptr\.To\(float64\(1\)\),`,
+		`MultipleOf:\s+ptr\.To\(float64\(64\)\),`,
+		`MultipleOf:\s+ptr\.To\(float64\(0\.25\)\),`,
+		`MinItems:\s+ptr\.To\(1\),`,
+		`MaxItems:\s+ptr\.To\(10\),`,
+		`UniqueItems:\s+true,`,
+		`MinProperties:\s+ptr\.To\(1\),`,
+		`MaxProperties:\s+ptr\.To\(8\),`,
+		`Required:\s+\[\]string\{"cpu"\},\s+AdditionalProperties: &jsonschema\.Schema\{`,
+	} {
+		assert.Regexp(t, constraint, schema)
+	}
+
+	// The constraints are summarized after the description for LLMs
+	assert.Contains(t, schema, `Description:      "Share of the CPU, above 0 and below 1 (number, greater than 0, less than 1)",`)
+	assert.Contains(t, schema, `Description: "(integer, min 64, max 65536, multiple of 64)",`)
+}
+
+func TestGenerateSchemeRegistration(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", nil)
+
+	register := files["register.go"]
+	assert.Contains(t, register, `GroupVersion = schema.GroupVersion{Group: "example.com", Version: "v1"}`)
+	assert.Contains(t, register, "AddToScheme = SchemeBuilder.AddToScheme")
+	assert.Contains(t, register, "&Widget{}")
+	assert.Contains(t, register, "&WidgetList{}")
+
+	assert.Contains(t, files["client.go"], "func NewWidgetClientForConfig(cfg *rest.Config, namespace string) (*WidgetClient, error)")
+}
+
+func TestGenerateIntOrString(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/complex-crd.yaml", nil)
+
+	types := files["types.go"]
+	assert.Contains(t, types, `"k8s.io/apimachinery/pkg/util/intstr"`)
+	assert.Regexp(t, `Targetport\s+intstr\.IntOrString\s+`+"`"+`json:"targetPort,omitempty"`+"`", types)
+
+	assert.Contains(t, files["schema.go"], `OneOf: []*jsonschema.Schema{{Type: "integer"}, {Type: "string"}}`)
+}
+
+func TestGeneratePreserveUnknownFields(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/complex-crd.yaml", nil)
+
+	assert.Regexp(t, `Extensions\s+runtime\.RawExtension\s+`+"`"+`json:"extensions,omitempty"`+"`", files["types.go"])
+	assert.Regexp(t, `"extensions": &jsonschema\.Schema\{\s+Type:\s+"object",\s+AdditionalProperties: &jsonschema\.Schema\{\},\s+\}`, files["schema.go"])
+}
+
+func TestGenerateSchemaDefaults(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/complex-crd.yaml", nil)
+
+	schema := files["schema.go"]
+	assert.Contains(t, schema, `"encoding/json"`)
+	assert.Regexp(t, `"replicas": &jsonschema\.Schema\{\s+Type:\s+"integer",\s+Description:\s+"\(integer, min 0, max 100\)",\s+Default:\s+json\.RawMessage\("1"\),`, schema)
+}
+
+func TestGenerateSchemaComposition(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/composition-crd.yaml", nil)
+
+	types := files["types.go"]
+	assert.Regexp(t, `Host\s+string\s+`+"`"+`json:"host,omitempty"`+"`", types)
+	assert.Regexp(t, `Timeoutseconds\s+int32\s+`+"`"+`json:"timeoutSeconds,omitempty"`+"`", types)
+
+	schema := files["schema.go"]
+	assert.Regexp(t, `AllOf: \[\]\*jsonschema\.Schema\{\s+&jsonschema\.Schema\{\s+Properties: map\[string\]\*jsonschema\.Schema\{\s+"host"`, schema)
+	assert.Regexp(t, `OneOf: \[\]\*jsonschema\.Schema\{\s+&jsonschema\.Schema\{\s+Required: \[\]string\{"service"\},\s+\},\s+&jsonschema\.Schema\{\s+Required: \[\]string\{"url"\},`, schema)
+	assert.Regexp(t, `AnyOf: \[\]\*jsonschema\.Schema\{\s+&jsonschema\.Schema\{\s+Required: \[\]string\{"secretName"\},\s+\},\s+&jsonschema\.Schema\{\s+Required: \[\]string\{"acme"\},`, schema)
+}
+
+func TestGeneratePrinterColumns(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/complex-crd.yaml", []string{"list"})
+
+	handlers := files["handlers.go"]
+	assert.Contains(t, handlers, `{name: "Replicas", jsonPath: ".spec.replicas"},`)
+	assert.Contains(t, handlers, `{name: "Ready", jsonPath: ".status.readyReplicas"},`)
+	assert.NotContains(t, handlers, ".spec.strategy.type", "priority columns should not be part of the compact output")
+	assert.Contains(t, handlers, "envelope.Table, err = printApplicationColumns(ret)")
+	assert.Contains(t, handlers, "case !opts.Verbose:")
+	assert.Regexp(t, "Verbose\\s+bool\\s+`json:\"verbose,omitempty\"`", files["options.go"])
+	assert.Contains(t, files["schema.go"], `"verbose": {`)
+
+	files = generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", []string{"list"})
+	assert.NotContains(t, files["handlers.go"], "jsonpath")
+	assert.NotContains(t, files["schema.go"], `"verbose"`)
+}
+
+func TestGenerateToolAnnotations(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", nil)
+	toolset := files["toolset.go"]
+
+	tests := []struct {
+		operation   string
+		readOnly    bool
+		destructive bool
+	}{
+		{"create", false, false},
+		{"get", true, false},
+		{"list", true, false},
+		{"update", false, true},
+		{"delete", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.operation, func(t *testing.T) {
+			pattern := fmt.Sprintf(`Name:\s+"widgets_%s",(?s:.*?)Annotations: api\.ToolAnnotations\{\s+ReadOnlyHint:\s+ptr\.To\(%t\),\s+DestructiveHint: ptr\.To\(%t\),`,
+				tt.operation, tt.readOnly, tt.destructive)
+			assert.Regexp(t, pattern, toolset)
+		})
+	}
+	assert.Contains(t, toolset, `"k8s.io/utils/ptr"`)
+}
+
+func TestGenerateToolsetRegistration(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", nil)
+	toolset := files["toolset.go"]
+
+	assert.Contains(t, toolset, "var _ api.Toolset = (*WidgetToolset)(nil)")
+	assert.Regexp(t, `func \(t \*WidgetToolset\) GetName\(\) string \{\s+return "widgets"`, toolset)
+	assert.Contains(t, toolset, "func (t *WidgetToolset) GetDescription() string {")
+	assert.Regexp(t, `func \(t \*WidgetToolset\) GetTools\(o internalk8s\.Openshift\) \[\]api\.ServerTool \{\s+tools := \[\]api\.ServerTool\{\s+createwidgetTool\(\),\s+getwidgetTool\(\),\s+listwidgetsTool\(\),\s+updatewidgetTool\(\),\s+deletewidgetTool\(\),\s+\}`, toolset)
+	assert.Regexp(t, `if t\.client != nil \{\s+for i := range tools \{\s+tools\[i\]\.Handler = withClient\(tools\[i\]\.Handler, t\.client\)`, toolset, "a kubeconfig toolset should pass its client to the handlers")
+	assert.Regexp(t, `func NewToolset\(\) \*WidgetToolset \{\s+return &WidgetToolset\{\}\s+\}`, toolset)
+	assert.Regexp(t, `func Register\(register func\(api\.Toolset\)\) \{\s+register\(NewToolset\(\)\)\s+\}`, toolset)
+	assert.Regexp(t, `func NewToolsetFromKubeconfig\(path string\) \(\*WidgetToolset, error\) \{\s+c, err := NewWidgetClientFromKubeconfig\(path, "default"\)`, toolset)
+	assert.Contains(t, files["client.go"], "cfg, err = rest.InClusterConfig()", "an empty kubeconfig path should use the in-cluster config")
+	assert.Regexp(t, `func init\(\) \{\s+Register\(toolsets\.Register\)\s+\}`, toolset, "the blank import should keep registering the toolset")
+}
+
+func TestGenerateShortNameTools(t *testing.T) {
+	withShortNames := func(config *analyzer.GenerationConfig) {
+		config.ShortNameTools = true
+	}
+
+	files := generateFromTemplatesWithConfig(t, "../../test/fixtures/cluster-scoped-crd.yaml", withShortNames)
+	toolset := files["toolset.go"]
+	for _, alias := range []string{"gc", "gconf"} {
+		assert.Contains(t, toolset, fmt.Sprintf(`withToolAlias(getglobalconfigTool(), "%s_get"),`, alias))
+		assert.Contains(t, toolset, fmt.Sprintf(`withToolAlias(listglobalconfigsTool(), "%s_list"),`, alias))
+	}
+	assert.Contains(t, toolset, "func withToolAlias(tool api.ServerTool, alias string) api.ServerTool {")
+	assert.Contains(t, files["doc.go"], "registered under the short names of the CRD, e.g. gc_get.")
+
+	// Without the option, or without short names, only the canonical tools are generated
+	files = generateFromTemplates(t, "../../test/fixtures/cluster-scoped-crd.yaml", nil)
+	assert.NotContains(t, files["toolset.go"], "withToolAlias")
+	files = generateFromTemplatesWithConfig(t, "../../test/fixtures/testwidget-crd.yaml", withShortNames)
+	assert.NotContains(t, files["toolset.go"], "withToolAlias")
+}
+
+func TestGenerateScaleTool(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/scalable-crd.yaml", nil)
+
+	assert.Contains(t, files["toolset.go"], `Name:        "workerpools_scale",`)
+	assert.Contains(t, files["toolset.go"], "Handler: HandleScaleWorkerPool,")
+	assert.Contains(t, files["handlers.go"], "func handleWorkerPoolScale(params api.ToolHandlerParams) (*api.ToolCallResult, error) {")
+	assert.Contains(t, files["handlers.go"], `clusterFor(params).Patch(params, opts.Namespace, opts.Name, types.MergePatchType, patch, "scale")`)
+	assert.NotContains(t, files["handlers.go"], "clientcmd")
+	assert.Regexp(t, `"replicas": \{\s+Type:\s+"integer",\s+Description: "Desired number of replicas, set at \.spec\.replicas through the scale subresource",`, files["schema.go"])
+	assert.Contains(t, files["schema.go"], `Required: []string{"name", "replicas"},`)
+	assert.Contains(t, files["client.go"], "func (c *WorkerPoolClient) Scale(ctx context.Context, name string, replicas int32) (*autoscalingv1.Scale, error) {")
+	assert.Contains(t, files["doc.go"], "workerpools_scale: set the replicas of a WorkerPool through its scale subresource")
+
+	// Scaling changes existing resources, so it comes with updates
+	files = generateFromTemplates(t, "../../test/fixtures/scalable-crd.yaml", []string{"get", "list"})
+	assert.NotContains(t, files["toolset.go"], "workerpools_scale")
+
+	// CRDs without a scale subresource get no scale tool
+	files = generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", nil)
+	assert.NotContains(t, files["toolset.go"], "_scale")
+	assert.NotContains(t, files["client.go"], "autoscalingv1")
+}
+
+func TestGenerateCustomOperations(t *testing.T) {
+	operations, err := analyzer.LoadCustomOperations("../../test/fixtures/operations/pause.yaml")
+	require.NoError(t, err)
+	files := generateFromTemplatesWithConfig(t, "../../test/fixtures/simple-crd.yaml", func(config *analyzer.GenerationConfig) {
+		config.CustomOperations = operations
+		config.SelectedOperations = []string{"get"}
+	})
+
+	handlers := files["handlers.go"]
+	assert.Contains(t, handlers, "func HandlePauseWidget(params api.ToolHandlerParams) (*api.ToolCallResult, error) {")
+	assert.Contains(t, handlers, `return handleWidgetPatch(params, "pause", types.MergePatchType, widgetPausePatch)`)
+	assert.Contains(t, handlers, `const widgetPausePatch = "{\"spec\":{\"paused\":true}}"`)
+	assert.Contains(t, handlers, `return handleWidgetPatch(params, "rotate-credentials", types.JSONPatchType, widgetRotateCredentialsPatch)`)
+	assert.Contains(t, handlers, "clusterFor(params).Patch(params, opts.Namespace, opts.Name, patchType, []byte(patch))")
+	assert.NotContains(t, handlers, "newWidgetHandlerClient")
+
+	toolset := files["toolset.go"]
+	assert.Contains(t, toolset, `Name:        "widgets_pause",`)
+	assert.Contains(t, toolset, `Name:        "widgets_rotate_credentials",`)
+	assert.Contains(t, toolset, "Handler: HandleRotateCredentialsWidget,")
+	assert.Contains(t, files["schema.go"], "func rotateCredentialsWidgetSchema() *jsonschema.Schema {")
+
+	// Without custom operations there is nothing to patch
+	handlers = generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", []string{"get"})["handlers.go"]
+	assert.NotContains(t, handlers, "handleWidgetPatch")
+	assert.NotContains(t, handlers, "newWidgetHandlerClient")
+}
+
+func TestGenerateCachedClient(t *testing.T) {
+	files := generateFromTemplatesWithConfig(t, "../../test/fixtures/simple-crd.yaml", func(config *analyzer.GenerationConfig) {
+		config.WithCache = true
+	})
+
+	client := files["client.go"]
+	assert.Contains(t, client, `"sigs.k8s.io/controller-runtime/pkg/cache"`)
+	assert.Contains(t, client, "func NewWidgetCachedClientForConfig(cfg *rest.Config, namespace string) (*WidgetClient, error) {")
+	assert.Contains(t, client, "Cache:  &client.CacheOptions{Reader: informers},")
+	assert.Contains(t, client, "func (c *WidgetClient) Start(ctx context.Context) error {")
+	assert.Contains(t, client, "c.cache.GetInformer(ctx, &Widget{})")
+	assert.Regexp(t, `namespace: namespace,\s+cache:\s+c\.cache,`, client, "WithNamespace should share the cache")
+
+	// Without the option the client reads from the API server only
+	client = generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", nil)["client.go"]
+	assert.NotContains(t, client, "cache")
+	assert.NotContains(t, client, "func (c *WidgetClient) Start(")
+}
+
+func TestGenerateToolErrors(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", nil)
+
+	apiErrors := files["errors.go"]
+	assert.Contains(t, apiErrors, `apierrors "k8s.io/apimachinery/pkg/api/errors"`)
+	assert.Regexp(t, `case apierrors\.IsNotFound\(err\):\s+code, reason = ToolErrorNotFound, "the widget does not exist"`, apiErrors)
+	assert.Contains(t, apiErrors, "case apierrors.IsConflict(err):")
+	assert.Contains(t, apiErrors, "case apierrors.IsForbidden(err):")
+	assert.Regexp(t, `case errors\.Is\(err, context\.DeadlineExceeded\):\s+code, reason = ToolErrorTimeout,`, apiErrors)
+
+	handlers := files["handlers.go"]
+	for _, call := range []string{
+		`newToolError("get widget "+opts.Name, err)`,
+		`newToolError("list widgets", err)`,
+		`newToolError("create widget", err)`,
+		`newToolError("update widget", err)`,
+		`newToolError("delete widget "+opts.Name, err)`,
+	} {
+		assert.Contains(t, handlers, call)
+	}
+}
+
+func TestGenerateFindOperation(t *testing.T) {
+	files := generateFromTemplatesWithConfig(t, "../../test/fixtures/simple-crd.yaml", func(config *analyzer.GenerationConfig) {
+		config.FindTool = true
+	})
+	assert.Contains(t, files["toolset.go"], `Name:        "widgets_find"`)
+	assert.Contains(t, files["toolset.go"], "Find the one Widget custom resource matching a label selector")
+	assert.Regexp(t, `Handler: HandleFindWidget`, files["toolset.go"])
+	assert.Regexp(t, `func findWidgetSchema\(\) \*jsonschema\.Schema \{[\s\S]*?Required: \[\]string\{"labelSelector"\}`, files["schema.go"])
+	assert.Contains(t, files["handlers.go"], "resourceListOptions := internalk8s.ResourceListOptions{AsTable: false}")
+	assert.Contains(t, files["handlers.go"], "ret, err := clusterFor(params).List(params, opts.Namespace, resourceListOptions)")
+	assert.NotContains(t, files["handlers.go"], "newWidgetHandlerClient")
+	assert.NotContains(t, files["handlers.go"], "clientcmd")
+	assert.Contains(t, files["client.go"], "func (c *WidgetClient) Find(ctx context.Context, labelSelector string) (*Widget, error) {")
+	assert.Contains(t, files["doc.go"], "widgets_find: get the one Widget matching a label selector")
+
+	// find is a variant of get and needs it selected
+	files = generateFromTemplatesWithConfig(t, "../../test/fixtures/simple-crd.yaml", func(config *analyzer.GenerationConfig) {
+		config.FindTool = true
+		config.SelectedOperations = []string{"list", "delete"}
+	})
+	assert.NotContains(t, files["toolset.go"], "widgets_find")
+	assert.NotContains(t, files["client.go"], ") Find(")
+
+	files = generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", nil)
+	assert.NotContains(t, files["toolset.go"], "widgets_find")
+}
+
+func TestGenerateHandlerTimeout(t *testing.T) {
+	handlers := generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", []string{"get", "delete"})["handlers.go"]
+	assert.Contains(t, handlers, "const WidgetHandlerTimeout time.Duration = 30 * time.Second")
+	assert.Contains(t, handlers, "ctx, cancel := context.WithTimeout(params.Context, WidgetHandlerTimeout)")
+	assert.Equal(t, 2, strings.Count(handlers, "params, cancel := withHandlerTimeout(params)"), "every tool call should be bounded")
+
+	handlers = generateFromTemplatesWithConfig(t, "../../test/fixtures/simple-crd.yaml", func(config *analyzer.GenerationConfig) {
+		config.HandlerTimeout = 1500 * time.Millisecond
+	})["handlers.go"]
+	assert.Contains(t, handlers, "const WidgetHandlerTimeout time.Duration = 1500 * time.Millisecond")
+
+	handlers = generateFromTemplatesWithConfig(t, "../../test/fixtures/simple-crd.yaml", func(config *analyzer.GenerationConfig) {
+		config.HandlerTimeout = 0
+	})["handlers.go"]
+	assert.Contains(t, handlers, "const WidgetHandlerTimeout time.Duration = 0")
+}
+
+func TestGenerateManifestArgument(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", []string{"create", "update"})
+	assert.Equal(t, 2, strings.Count(files["schema.go"], `"manifest": {`))
+	assert.NotContains(t, files["schema.go"], `Required: []string{"args"},`)
+	assert.Contains(t, files["handlers.go"], `resource, err := resourceFromArguments(opts.Args, opts.Manifest, "create")`)
+	assert.Contains(t, files["handlers.go"], `resource, err := resourceFromArguments(opts.Args, opts.Manifest, "update")`)
+	assert.Contains(t, files["handlers.go"], `apiVersion != "example.com/v1"`)
+
+	files = generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", []string{"get", "delete"})
+	assert.NotContains(t, files["schema.go"], `"manifest"`)
+	assert.NotContains(t, files["handlers.go"], "resourceFromArguments")
+}
+
+func TestGenerateUpdateRetryOnConflict(t *testing.T) {
+	handlers := generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", nil)["handlers.go"]
+	assert.Contains(t, handlers, `"k8s.io/client-go/util/retry"`)
+	assert.Contains(t, handlers, "const WidgetUpdateRetries = 4")
+	assert.Contains(t, handlers, "backoff.Steps = WidgetUpdateRetries + 1")
+	assert.Contains(t, handlers, "err = retry.RetryOnConflict(backoff, func() error {")
+	assert.Contains(t, handlers, `metadata["resourceVersion"] = latest.GetResourceVersion()`)
+
+	handlers = generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", []string{"get", "create"})["handlers.go"]
+	assert.NotContains(t, handlers, "retry")
+}
+
+func TestGenerateDeleteOptions(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", []string{"delete"})
+	assert.Contains(t, files["schema.go"], `Enum:        []any{"Foreground", "Background", "Orphan"},`)
+	assert.Contains(t, files["schema.go"], `"gracePeriodSeconds": {`)
+
+	handlers := files["handlers.go"]
+	assert.Contains(t, handlers, "deleteOptions.GracePeriodSeconds = opts.GracePeriodSeconds")
+	assert.Contains(t, handlers, "deleteOptions.PropagationPolicy = &policy")
+	assert.Contains(t, handlers, "err = clusterFor(params).Delete(params, opts.Namespace, opts.Name, deleteOptions)")
+	assert.Contains(t, handlers, "return s.params.AccessControlClientset().DynamicClient().Resource(WidgetGroupVersionResource).")
+	assert.Contains(t, handlers, "Namespace(s.params.NamespaceOrDefault(namespace))")
+	assert.Contains(t, handlers, "return c.client.client.Delete(ctx, c.object(namespace, name), &client.DeleteOptions{")
+	assert.NotContains(t, handlers, "clientcmd")
+
+	handlers = generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", []string{"get"})["handlers.go"]
+	assert.NotContains(t, handlers, "clientcmd")
+	assert.NotContains(t, handlers, "deleteOptions")
+}
+
+func TestGenerateGetFieldProjection(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", []string{"get"})
+	assert.Contains(t, files["schema.go"], `"fields": {`)
+	assert.Contains(t, files["schema.go"], `Items:       &jsonschema.Schema{Type: "string"},`)
+	assert.Contains(t, files["handlers.go"], "output.MarshalYaml(projectFields(ret.Object, opts.Fields))")
+
+	handlers := generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", []string{"list"})["handlers.go"]
+	assert.NotContains(t, handlers, "projectFields")
+}
+
+func TestGenerateToolNamesUseCRDPlural(t *testing.T) {
+	tests := []struct {
+		kind     string
+		plural   string
+		singular string
+	}{
+		{"Gateway", "gateways", "gateway"},
+		{"Ingress", "ingresses", "ingress"},
+		{"Proxy", "proxies", "proxy"},
+		{"Octopus", "octopodes", "octopus"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			crdPath := filepath.Join(t.TempDir(), "crd.yaml")
+			require.NoError(t, os.WriteFile(crdPath, fmt.Appendf(nil, `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: %[2]s.example.com
+spec:
+  group: example.com
+  names:
+    kind: %[1]s
+    plural: %[2]s
+    singular: %[3]s
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+`, tt.kind, tt.plural, tt.singular), 0o644))
+
+			files := generateFromTemplates(t, crdPath, []string{"get", "list"})
+			assert.Contains(t, files["toolset.go"], fmt.Sprintf(`Name:        "%s_list",`, tt.plural))
+			assert.Contains(t, files["toolset.go"], fmt.Sprintf(`Name:        "%s_get",`, tt.plural))
+			assert.Contains(t, files["toolset.go"], fmt.Sprintf("func list%sTool() api.ServerTool {", tt.plural))
+			assert.Contains(t, files["toolset.go"], fmt.Sprintf("func get%sTool() api.ServerTool {", tt.singular))
+			assert.Contains(t, files["doc.go"], fmt.Sprintf("//   - %s_list: list %s", tt.plural, tt.plural))
+		})
+	}
+}
+
+func TestGenerateCategories(t *testing.T) {
+	toolset := generateFromTemplates(t, "../../test/fixtures/categories-crd.yaml", nil)["toolset.go"]
+	assert.Regexp(t, `func \(t \*CertificateToolset\) GetCategories\(\) \[\]string \{\s+return \[\]string\{"all", "security"\}\s+\}`, toolset)
+
+	toolset = generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", nil)["toolset.go"]
+	assert.NotContains(t, toolset, "GetCategories")
+}
+
+func TestGeneratePackageDoc(t *testing.T) {
+	doc := generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", []string{"create", "get", "list"})["doc.go"]
+
+	// The overview has to be attached to the package clause for godoc to pick it up
+	file, err := parser.ParseFile(token.NewFileSet(), "doc.go", doc, parser.ParseComments|parser.PackageClauseOnly)
+	require.NoError(t, err)
+	require.NotNil(t, file.Doc, "doc.go should have a package comment")
+	overview := file.Doc.Text()
+
+	assert.True(t, strings.HasPrefix(overview, "Package widgets provides"))
+	assert.Contains(t, overview, "GroupVersionKind: example.com/v1, Kind=Widget")
+	assert.Contains(t, overview, "Scope: Namespaced")
+	for _, tool := range []string{"widgets_create", "widgets_get", "widgets_list"} {
+		assert.Contains(t, overview, tool)
+	}
+	assert.NotContains(t, overview, "widgets_delete", "only generated tools should be listed")
+	assert.Contains(t, overview, `under the name "widgets"`)
+}
+
+func TestGenerateTests(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", []string{"get", "delete"})
+	assert.NotContains(t, files, "handlers_test.go", "tests are only generated on request")
+
+	files = generateFromTemplatesWithConfig(t, "../../test/fixtures/simple-crd.yaml", func(config *analyzer.GenerationConfig) {
+		config.SelectedOperations = []string{"get", "delete"}
+		config.GenerateTests = true
+	})
+	tests := files["handlers_test.go"]
+	assert.Contains(t, tests, `"sigs.k8s.io/controller-runtime/pkg/client/fake"`)
+	assert.Contains(t, tests, `WidgetSpecName: "sample",`, "the required spec field should get a sample value")
+	assert.Contains(t, tests, `operation: "get"`)
+	assert.Contains(t, tests, `operation: "delete"`)
+	assert.NotContains(t, tests, `operation: "create"`)
+	assert.Contains(t, tests, `{"widgets_delete", deleteWidgetSchema()}`)
+	assert.NotContains(t, tests, "createWidgetSchema()")
+}
+
+func TestGenerateExamplePrompts(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", []string{"create", "get"})
+	assert.NotContains(t, files, "prompts.go", "prompts are only generated on request")
+
+	files = generateFromTemplatesWithConfig(t, "../../test/fixtures/simple-crd.yaml", func(config *analyzer.GenerationConfig) {
+		config.SelectedOperations = []string{"create", "get"}
+		config.GeneratePrompts = true
+	})
+	prompts := files["prompts.go"]
+	assert.Contains(t, prompts, "var _ ek8sapi.PromptProvider = (*WidgetToolset)(nil)")
+	assert.Contains(t, prompts, `"widgets_create_example",`)
+	assert.Contains(t, prompts, `"widgets_get_example",`)
+	assert.NotContains(t, prompts, "widgets_delete")
+	assert.Contains(t, prompts, "const createWidgetExamplePrompt = `Example arguments for the widgets_create tool")
+	assert.Regexp(t, `"spec": \{\s+"name": "example"\s+\}`, prompts)
+}
+
+func TestGenerateCRDResource(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", nil)
+	assert.NotContains(t, files, "resources.go", "resources are only generated on request")
+	assert.NotContains(t, files["toolset.go"], "RegisterResources")
+
+	files = generateFromTemplatesWithConfig(t, "../../test/fixtures/simple-crd.yaml", func(config *analyzer.GenerationConfig) {
+		config.GenerateCRDResource = true
+	})
+	resources := files["resources.go"]
+	assert.Contains(t, resources, "var _ ek8sapi.ResourceProvider = (*WidgetToolset)(nil)")
+	assert.Contains(t, resources, "func (t *WidgetToolset) RegisterResources(")
+	assert.Contains(t, resources, `"crd://example.com/v1/Widget",`)
+	assert.Contains(t, resources, `"Widget v1 CRD",`)
+	assert.Contains(t, resources, "return embeddedWidgetCRDYAML, nil")
+	assert.Contains(t, resources, "const embeddedWidgetCRDYAML = `apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\n")
+	assert.Contains(t, resources, "  name: widgets.example.com\n")
+	assert.NotContains(t, resources, "docs://", "the documentation resource has its own flag")
+}
+
+func TestGenerateSchemaDescription(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/described-crd.yaml", nil)
+	description := `Backup is a point-in-time snapshot of a \"PersistentVolumeClaim\". The snapshot is taken when the resource is created and kept until spec.retentionDays have passed.`
+
+	assert.Contains(t, files["toolset.go"], "return \""+description+"\"")
+	assert.Contains(t, files["toolset.go"], "\"Get a Backup custom resource. "+description+"\",")
+
+	files = generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", nil)
+	assert.Contains(t, files["toolset.go"], `return "Tools for managing Widget custom resources"`)
+	assert.Regexp(t, `Description:\s+"Get a Widget custom resource",`, files["toolset.go"])
+}
+
+func TestGenerateFieldDescriptionComments(t *testing.T) {
+	types := generateFromTemplates(t, "../../test/fixtures/described-crd.yaml", nil)["types.go"]
+
+	assert.Contains(t, types, "\t// Name of the PersistentVolumeClaim to snapshot\n\tBackupSpecClaimname string")
+	assert.Contains(t, types, "\t// Number of days the snapshot is kept after it was taken.\n"+
+		"\t//\n"+
+		"\t// Once they have passed, the snapshot is deleted together with the Backup, unless the Backup is\n"+
+		"\t// annotated to be kept.\n"+
+		"\tBackupSpecRetentiondays ")
+}
+
+func TestGenerateArrayItemStructs(t *testing.T) {
+	types := generateFromTemplates(t, "../../test/fixtures/containers-crd.yaml", nil)["types.go"]
+
+	assert.Contains(t, types, "type WidgetSpecContainerItem struct {")
+	assert.Regexp(t, `WidgetSpecContainers\s+\[\]WidgetSpecContainerItem\s+`+"`json:\"containers", types)
+	assert.Contains(t, types, "type WidgetSpecContainerItemPortItem struct {")
+	assert.Regexp(t, `WidgetSpecContainerItemPorts\s+\[\]WidgetSpecContainerItemPortItem\s`, types)
+	assert.Contains(t, types, "func (in *WidgetSpecContainerItem) DeepCopyInto(out *WidgetSpecContainerItem) {")
+}
+
+func TestGenerateStringFormatTypes(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/formats-crd.yaml", nil)
+	types := files["types.go"]
+
+	assert.Regexp(t, `ScheduleSpecStarttime\s+metav1\.Time\s`, types)
+	assert.Regexp(t, `ScheduleSpecInterval\s+metav1\.Duration\s`, types)
+	assert.Regexp(t, `ScheduleSpecPayload\s+\[\]byte\s`, types)
+	assert.Regexp(t, `ScheduleSpecEndpoint\s+string\s`, types)
+	assert.Regexp(t, `ScheduleSpecLabels\s+map\[string\]\[\]byte\s`, types)
+	assert.Regexp(t, `ScheduleStatusLastruntime\s+metav1\.Time\s`, types)
+	assert.Contains(t, types, `metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"`)
+	assert.NotContains(t, types, "\t\"k8s.io/apimachinery/pkg/apis/meta/v1\"", "metav1 must not be imported twice")
+
+	// Formatted strings are still strings in the JSON schema
+	assert.Regexp(t, `"lastRunTime": \{\s+Type:\s+"string",`, files["schema.go"])
+}
+
+func TestGenerateDefaultNamespace(t *testing.T) {
+	withDefault := func(config *analyzer.GenerationConfig) {
+		config.DefaultNamespace = "team-a"
+	}
+
+	files := generateFromTemplatesWithConfig(t, "../../test/fixtures/simple-crd.yaml", withDefault)
+	assert.Equal(t, 3, strings.Count(files["options.go"], `Namespace: "team-a"`), "get, list and delete should fall back to the default")
+	assert.Equal(t, 2, strings.Count(files["handlers.go"], `metadata["namespace"] = "team-a"`), "create and update should fall back to the default")
+	assert.Equal(t, 2, strings.Count(files["handlers.go"], `metadata["namespace"] = opts.Namespace`), "create and update should prefer the namespace argument")
+	assert.Contains(t, files["schema.go"], "Kubernetes namespace (optional, defaults to 'team-a')")
+	assert.NotContains(t, files["schema.go"], "defaults to 'default'")
+
+	// Cluster-scoped resources have no namespace to default to
+	files = generateFromTemplatesWithConfig(t, "../../test/fixtures/cluster-scoped-crd.yaml", withDefault)
+	assert.NotContains(t, files["handlers.go"], "team-a")
+	assert.NotContains(t, files["handlers.go"], "opts.Namespace")
+	assert.NotContains(t, files["schema.go"], "team-a")
+}
+
+func TestGenerateAllNamespacesList(t *testing.T) {
+	withAllNamespaces := func(config *analyzer.GenerationConfig) {
+		config.AllNamespacesList = true
+	}
+
+	files := generateFromTemplatesWithConfig(t, "../../test/fixtures/simple-crd.yaml", withAllNamespaces)
+	assert.Contains(t, files["schema.go"], `"allNamespaces": {`)
+	assert.Contains(t, files["handlers.go"], "if opts.AllNamespaces {")
+	assert.Regexp(t, "AllNamespaces\\s+bool\\s+`json:\"allNamespaces,omitempty\"`", files["options.go"])
+
+	files = generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", []string{"list"})
+	assert.NotContains(t, files["schema.go"], "allNamespaces", "allNamespaces should only be generated on request")
+	assert.NotContains(t, files["handlers.go"], "allNamespaces")
+
+	// Cluster-scoped resources are always listed across the cluster
+	files = generateFromTemplatesWithConfig(t, "../../test/fixtures/cluster-scoped-crd.yaml", withAllNamespaces)
+	assert.NotContains(t, files["schema.go"], "allNamespaces")
+	assert.NotContains(t, files["handlers.go"], "allNamespaces")
+}
+
+func TestGenerateExcludedFields(t *testing.T) {
+	exclude := []string{"spec.secret", "spec.credentials.password"}
+
+	files := generateFromTemplatesWithConfig(t, "../../test/fixtures/sensitive-crd.yaml", func(config *analyzer.GenerationConfig) {
+		config.ExcludedFields = exclude
+	})
+	for _, filename := range []string{"schema.go", "types.go"} {
+		assert.NotContains(t, files[filename], `"secret"`, filename)
+		assert.NotContains(t, files[filename], "password", filename)
+		assert.Contains(t, files[filename], "username", filename)
+	}
+	assert.Contains(t, files["schema.go"], `Required: []string{"endpoint"}`)
+
+	files = generateFromTemplatesWithConfig(t, "../../test/fixtures/sensitive-crd.yaml", func(config *analyzer.GenerationConfig) {
+		config.ExcludedFields = exclude
+		config.KeepExcludedFieldsInTypes = true
+	})
+	assert.NotContains(t, files["schema.go"], `"secret"`)
+	assert.Contains(t, files["types.go"], `json:"secret,omitempty"`)
+	assert.Contains(t, files["types.go"], `json:"password,omitempty"`)
+}
+
+func TestGenerateListSelectors(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", []string{"get", "list"})
+
+	handlers := files["handlers.go"]
+	assert.Contains(t, handlers, "resourceListOptions.FieldSelector = opts.FieldSelector")
+	assert.Contains(t, handlers, "labels.Parse(opts.LabelSelector)")
+	assert.Contains(t, handlers, "fields.ParseSelector(opts.FieldSelector)")
+	assert.Contains(t, handlers, `"k8s.io/apimachinery/pkg/fields"`)
+
+	client := files["client.go"]
+	assert.Contains(t, client, "func (c *WidgetClient) ListWithSelectors(")
+	assert.Contains(t, client, "client.MatchingLabelsSelector{Selector: selector}")
+	assert.Contains(t, client, "client.MatchingFieldsSelector{Selector: selector}")
+
+	schema := files["schema.go"]
+	assert.Contains(t, schema, `"labelSelector": {`)
+	assert.Contains(t, schema, `"fieldSelector": {`)
+}
+
+func TestGenerateListPagination(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", []string{"list"})
+
+	handlers := files["handlers.go"]
+	assert.Contains(t, handlers, "resourceListOptions.Limit = *opts.Limit")
+	assert.Contains(t, handlers, "resourceListOptions.Continue = opts.Continue")
+	assert.Regexp(t, "Limit\\s+\\*int64\\s+`json:\"limit,omitempty\"`", files["options.go"])
+	assert.Contains(t, handlers, "meta.ListAccessor(ret)")
+
+	assert.Contains(t, files["client.go"], "func (c *WidgetClient) ListPage(ctx context.Context, limit int64, continueToken string")
+
+	schema := files["schema.go"]
+	assert.Contains(t, schema, `"limit": {`)
+	assert.Contains(t, schema, `"continue": {`)
+}
+
+func TestGenerateListSummary(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", []string{"list"})
+
+	handlers := files["handlers.go"]
+	assert.Contains(t, handlers, "}{ItemCount: meta.LenList(ret)}")
+	assert.Contains(t, handlers, "envelope.RemainingItemCount = listMeta.GetRemainingItemCount()")
+	assert.Contains(t, handlers, "out, err := json.Marshal(envelope)")
+	assert.Contains(t, handlers, `"encoding/json"`)
+}
+
+func TestGenerateListSort(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", []string{"list"})
+
+	handlers := files["handlers.go"]
+	assert.Contains(t, handlers, "var widgetSortableFields = []string{")
+	assert.Contains(t, handlers, `"metadata.creationTimestamp",`)
+	assert.Contains(t, handlers, `"spec.name",`)
+	assert.Contains(t, handlers, "!slices.Contains(widgetSortableFields, opts.SortBy)")
+	assert.Contains(t, handlers, `sortListItems(ret, opts.SortBy, opts.Order == "desc")`)
+	assert.Regexp(t, "SortBy\\s+string\\s+`json:\"sortBy,omitempty\"`", files["options.go"])
+
+	schema := files["schema.go"]
+	assert.Contains(t, schema, `"sortBy": {`)
+	assert.Contains(t, schema, `Enum:        []any{"asc", "desc"},`)
+}
+
+func TestGenerateWithoutListOmitsSelectorValidation(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", []string{"create", "get"})
+
+	assert.NotContains(t, files["handlers.go"], "labels.Parse(opts.LabelSelector)")
+	assert.NotContains(t, files["handlers.go"], "fields.ParseSelector(opts.FieldSelector)")
+	// The client of a kubeconfig toolset still parses the selectors it lists with
+	assert.Contains(t, files["handlers.go"], "labels.Parse(options.LabelSelector)")
+}
+
+func TestGenerateCreateOnlyHasNoUnusedImports(t *testing.T) {
+	files := generateFromTemplates(t, "../../test/fixtures/simple-crd.yaml", []string{"create"})
+
+	for filename, content := range files {
+		file, err := parser.ParseFile(token.NewFileSet(), filename, content, 0)
+		require.NoError(t, err, "%s should be valid Go", filename)
+
+		used := make(map[string]bool)
+		ast.Inspect(file, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if ident, ok := sel.X.(*ast.Ident); ok {
+					used[ident.Name] = true
+				}
+			}
+			return true
+		})
+
+		for _, imp := range file.Imports {
+			importPath := strings.Trim(imp.Path.Value, `"`)
+			name := path.Base(importPath)
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			assert.True(t, used[name], "%s imports %s but does not use it", filename, importPath)
+		}
+	}
+}
+
+// generateFromTemplates renders a fixture CRD with the embedded templates and returns the
+// generated files keyed by filename.
+func generateFromTemplates(t *testing.T, crdFile string, operations []string) map[string]string {
+	t.Helper()
+	return generateFromTemplatesWithConfig(t, crdFile, func(config *analyzer.GenerationConfig) {
+		config.SelectedOperations = operations
+	})
+}
+
+// generateFromTemplatesWithConfig works like generateFromTemplates and lets configure adjust the
+// generation config before the CRD is analyzed.
+func generateFromTemplatesWithConfig(t *testing.T, crdFile string, configure func(*analyzer.GenerationConfig)) map[string]string {
+	t.Helper()
+
+	crdInfo, err := analyzer.NewCRDAnalyzer().ParseCRDFromFile(crdFile)
+	require.NoError(t, err)
+
+	config := analyzer.DefaultGenerationConfig()
+	config.PackageName = crdInfo.GetPackageName()
+	config.ModulePath = "github.com/test/module"
+	config.OutputDir = t.TempDir()
+	configure(config)
+
+	toolsetInfo, err := analyzer.NewToolsetInfo(crdInfo, config)
+	require.NoError(t, err)
+
+	gen, err := NewGenerator(&GeneratorConfig{
+		OutputDir:       config.OutputDir,
+		PackageName:     config.PackageName,
+		ModulePath:      config.ModulePath,
+		OverwriteFiles:  true,
+		IncludeComments: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, gen.GenerateToolset(toolsetInfo))
+
+	files := make(map[string]string)
+	entries, err := os.ReadDir(config.OutputDir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(config.OutputDir, entry.Name()))
+		require.NoError(t, err)
+		files[entry.Name()] = string(content)
+	}
+	return files
+}
@@ -0,0 +1,43 @@
+package generator
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GoGenerateFilename is the name of the file holding the go:generate directive of a toolset package
+const GoGenerateFilename = "generate.go"
+
+// GenerateGoGenerateFile writes a file to dir with a go:generate directive that runs mcp-toolgen
+// with args, so that `go generate` rebuilds the toolset package. Paths in args must be relative
+// to dir, which is the working directory of the directive. header (see FileHeader) is placed
+// before the package clause. logger receives a warning if the file fails to format.
+func GenerateGoGenerateFile(dir, packageName string, args []string, overwrite bool, header string, logger *slog.Logger) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no arguments for the go:generate directive")
+	}
+
+	content := fmt.Sprintf("%spackage %s\n\n%s\n", header, packageName, goGenerateDirective(args))
+
+	writer := NewFileWriter(dir, overwrite, true, logger)
+	return writer.WriteFile(GoGenerateFilename, content)
+}
+
+// goGenerateDirective returns the //go:generate line running mcp-toolgen with args. Arguments that
+// go generate would split or alter are quoted, and $ is escaped since go generate expands
+// environment variables.
+func goGenerateDirective(args []string) string {
+	words := []string{"//go:generate", "mcp-toolgen"}
+	for _, arg := range args {
+		if arg == "" || strings.ContainsFunc(arg, func(r rune) bool {
+			return unicode.IsSpace(r) || r == '"' || !unicode.IsPrint(r)
+		}) {
+			arg = strconv.Quote(arg)
+		}
+		words = append(words, strings.ReplaceAll(arg, "$", "${DOLLAR}"))
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,52 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoGenerateDirective(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "plain arguments",
+			args:     []string{"--crd", "../crds/widget.yaml", "--output", "."},
+			expected: "//go:generate mcp-toolgen --crd ../crds/widget.yaml --output .",
+		},
+		{
+			name:     "arguments with spaces and quotes",
+			args:     []string{"--toolset-description=Manage \"widgets\" carefully", ""},
+			expected: `//go:generate mcp-toolgen "--toolset-description=Manage \"widgets\" carefully" ""`,
+		},
+		{
+			name:     "environment variables are not expanded",
+			args:     []string{"--header-file=$HOME/boilerplate.txt", "--toolset-description=$5"},
+			expected: "//go:generate mcp-toolgen --header-file=${DOLLAR}HOME/boilerplate.txt --toolset-description=${DOLLAR}5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, goGenerateDirective(tt.args))
+		})
+	}
+}
+
+func TestGenerateGoGenerateFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, GenerateGoGenerateFile(dir, "widgets", []string{"--crd", "widget.yaml"}, false, "//go:build mytools\n\n", nil))
+
+	content, err := os.ReadFile(filepath.Join(dir, GoGenerateFilename))
+	require.NoError(t, err)
+	assert.Equal(t, "//go:build mytools\n\npackage widgets\n\n//go:generate mcp-toolgen --crd widget.yaml\n", string(content))
+
+	assert.ErrorContains(t, GenerateGoGenerateFile(dir, "widgets", []string{"--crd", "widget.yaml"}, false, "", nil), "already exists")
+	assert.ErrorContains(t, GenerateGoGenerateFile(dir, "widgets", nil, true, "", nil), "no arguments")
+}
@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/build/constraint"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// FileHeader returns the lines placed before the package clause of every generated file: the
+// build constraint for buildTag followed by license as a comment block. Either may be empty.
+func FileHeader(buildTag, license string) (string, error) {
+	constraintLines, err := buildConstraintHeader(buildTag)
+	if err != nil {
+		return "", err
+	}
+
+	licenseLines, err := licenseHeader(license)
+	if err != nil {
+		return "", err
+	}
+
+	return constraintLines + licenseLines, nil
+}
+
+// buildConstraintHeader returns the //go:build line and the matching legacy // +build lines for
+// the build tag expression, or an empty string if no tag is set
+func buildConstraintHeader(tag string) (string, error) {
+	if tag == "" {
+		return "", nil
+	}
+
+	expr, err := constraint.Parse("//go:build " + tag)
+	if err != nil {
+		return "", fmt.Errorf("invalid build tag %q: %w", tag, err)
+	}
+	plusBuild, err := constraint.PlusBuildLines(expr)
+	if err != nil {
+		return "", fmt.Errorf("invalid build tag %q: %w", tag, err)
+	}
+
+	lines := append([]string{"//go:build " + expr.String()}, plusBuild...)
+	return strings.Join(lines, "\n") + "\n\n", nil
+}
+
+// licenseHeader renders the license text with {{.Year}} substituted and turns it into a comment
+// block. Text that already is a Go comment is kept as is.
+func licenseHeader(license string) (string, error) {
+	if strings.TrimSpace(license) == "" {
+		return "", nil
+	}
+
+	tmpl, err := template.New("header").Parse(license)
+	if err != nil {
+		return "", fmt.Errorf("invalid header template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, struct{ Year int }{Year: time.Now().Year()}); err != nil {
+		return "", fmt.Errorf("failed to render header: %w", err)
+	}
+
+	text := strings.TrimSpace(buf.String())
+	if strings.HasPrefix(text, "//") || strings.HasPrefix(text, "/*") {
+		return text + "\n\n", nil
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("// "+line, " ")
+	}
+	return strings.Join(lines, "\n") + "\n\n", nil
+}
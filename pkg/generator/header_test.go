@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileHeader(t *testing.T) {
+	year := time.Now().Year()
+
+	tests := []struct {
+		name     string
+		buildTag string
+		license  string
+		expected string
+	}{
+		{
+			name:     "nothing set",
+			expected: "",
+		},
+		{
+			name:     "build tag only",
+			buildTag: "linux || darwin",
+			expected: "//go:build linux || darwin\n// +build linux darwin\n\n",
+		},
+		{
+			name:     "plain text license",
+			license:  "Copyright {{.Year}} Example Corp.\n\nAll rights reserved.\n",
+			expected: fmt.Sprintf("// Copyright %d Example Corp.\n//\n// All rights reserved.\n\n", year),
+		},
+		{
+			name:     "license that already is a comment",
+			license:  "/*\nCopyright {{.Year}} Example Corp.\n*/\n",
+			expected: fmt.Sprintf("/*\nCopyright %d Example Corp.\n*/\n\n", year),
+		},
+		{
+			name:     "build tag and license",
+			buildTag: "mytools",
+			license:  "Copyright Example Corp.",
+			expected: "//go:build mytools\n// +build mytools\n\n// Copyright Example Corp.\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, err := FileHeader(tt.buildTag, tt.license)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, header)
+		})
+	}
+}
+
+func TestFileHeaderInvalidTemplate(t *testing.T) {
+	_, err := FileHeader("", "Copyright {{.Year")
+	assert.ErrorContains(t, err, "invalid header template")
+}
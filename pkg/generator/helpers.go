@@ -2,12 +2,17 @@ package generator
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"github.com/friedrichwilken/mcp-toolgen/pkg/analyzer"
 )
 
 // Template helper functions
@@ -160,7 +165,9 @@ func toKebabCase(s string) string {
 	return string(result)
 }
 
-// pluralize returns a simple pluralized form of a word
+// pluralize returns a simple pluralized form of a word. It gets irregular plurals wrong, so the
+// generated names use the plural the CRD declares instead; it is only offered to custom templates
+// as Pluralize.
 func pluralize(s string) string {
 	if s == "" {
 		return s
@@ -283,6 +290,25 @@ func quote(s string) string {
 	return fmt.Sprintf("%q", s)
 }
 
+// goDuration returns the Go expression of d in its largest whole unit, e.g. 30 * time.Second
+func goDuration(d time.Duration) string {
+	if d == 0 {
+		return "0"
+	}
+	for _, unit := range []struct {
+		duration time.Duration
+		name     string
+	}{
+		{time.Hour, "Hour"}, {time.Minute, "Minute"}, {time.Second, "Second"},
+		{time.Millisecond, "Millisecond"}, {time.Microsecond, "Microsecond"},
+	} {
+		if d%unit.duration == 0 {
+			return fmt.Sprintf("%d * time.%s", d/unit.duration, unit.name)
+		}
+	}
+	return fmt.Sprintf("%d * time.Nanosecond", d)
+}
+
 // indent adds indentation to each line of a string
 func indent(s, indentStr string) string {
 	lines := strings.Split(s, "\n")
@@ -335,6 +361,69 @@ func wrapComment(text string, maxWidth int) string {
 	return strings.Join(lines, "\n")
 }
 
+// formatDescriptionComment turns a schema description into a Go comment block, wrapping each
+// paragraph with wrapComment and separating paragraphs with an empty comment line. Unlike
+// escapeString, it keeps long and multi-paragraph descriptions readable in generated code.
+func formatDescriptionComment(description string) string {
+	description = strings.ReplaceAll(description, "\r", "")
+
+	var paragraphs []string
+	for _, paragraph := range strings.Split(description, "\n\n") {
+		if wrapped := wrapComment(paragraph, 100); wrapped != "" {
+			paragraphs = append(paragraphs, wrapped)
+		}
+	}
+	return strings.Join(paragraphs, "\n//\n")
+}
+
+// descriptionEllipsis ends the descriptions truncateDescription shortens
+const descriptionEllipsis = "..."
+
+// truncateDescription shortens description to at most maxLength characters, ending it with an
+// ellipsis, so the enormous descriptions of some CRDs do not blow up the generated schema and tool
+// metadata. A maxLength of 0 keeps the description whole.
+func truncateDescription(description string, maxLength int) string {
+	runes := []rune(description)
+	if maxLength <= 0 || len(runes) <= maxLength {
+		return description
+	}
+	keep := max(maxLength-len(descriptionEllipsis), 0)
+	return strings.TrimRight(string(runes[:keep]), " \t\r\n") + descriptionEllipsis
+}
+
+// truncateSchemaDescriptions returns a copy of the schema with the descriptions of it and all its
+// subschemas truncated by truncateDescription, for convertSchemaToGoCode. A maxLength of 0 returns
+// the schema as is.
+func truncateSchemaDescriptions(schemaInterface interface{}, maxLength int) interface{} {
+	schema := normalizeSchemaInterface(schemaInterface)
+	if schema == nil || maxLength <= 0 {
+		return schemaInterface
+	}
+
+	truncated := schema.DeepCopy()
+	var truncate func(schema *apiextensionsv1.JSONSchemaProps)
+	truncate = func(schema *apiextensionsv1.JSONSchemaProps) {
+		schema.Description = truncateDescription(schema.Description, maxLength)
+		for name, prop := range schema.Properties {
+			truncate(&prop)
+			schema.Properties[name] = prop
+		}
+		for _, subschemas := range [][]apiextensionsv1.JSONSchemaProps{schema.AllOf, schema.AnyOf, schema.OneOf} {
+			for i := range subschemas {
+				truncate(&subschemas[i])
+			}
+		}
+		if schema.Items != nil && schema.Items.Schema != nil {
+			truncate(schema.Items.Schema)
+		}
+		if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+			truncate(schema.AdditionalProperties.Schema)
+		}
+	}
+	truncate(truncated)
+	return truncated
+}
+
 // escapeString escapes a string for use in Go string literals
 // It handles newlines, quotes, and other special characters
 func escapeString(s string) string {
@@ -359,40 +448,108 @@ func generateFieldName(jsonName string) string {
 	return toPascalCase(jsonName)
 }
 
-// generateMethodName generates a Go method name
-func generateMethodName(operation, resourceName string) string {
+// generateMethodName generates a Go method name from the singular and plural of the resource,
+// e.g. GetWidget and ListWidgets. The names are taken as they are rather than derived from each
+// other, since heuristics get irregular plurals wrong.
+func generateMethodName(operation, singular, plural string) string {
 	switch operation {
 	case "create":
-		return fmt.Sprintf("Create%s", toPascalCase(resourceName))
+		return fmt.Sprintf("Create%s", toPascalCase(singular))
 	case "get":
-		return fmt.Sprintf("Get%s", toPascalCase(resourceName))
+		return fmt.Sprintf("Get%s", toPascalCase(singular))
 	case "list":
-		return fmt.Sprintf("List%s", toPascalCase(pluralize(resourceName)))
+		return fmt.Sprintf("List%s", toPascalCase(plural))
 	case "update":
-		return fmt.Sprintf("Update%s", toPascalCase(resourceName))
+		return fmt.Sprintf("Update%s", toPascalCase(singular))
 	case "delete":
-		return fmt.Sprintf("Delete%s", toPascalCase(resourceName))
+		return fmt.Sprintf("Delete%s", toPascalCase(singular))
 	default:
-		return toPascalCase(operation + resourceName)
+		return toPascalCase(operation) + toPascalCase(singular)
 	}
 }
 
-// generateToolName generates an MCP tool name
-func generateToolName(operation, resourceName string) string {
-	switch operation {
-	case "create":
-		return fmt.Sprintf("%s_%s", toSnakeCase(resourceName), "create")
-	case "get":
-		return fmt.Sprintf("%s_%s", toSnakeCase(resourceName), "get")
-	case "list":
-		return fmt.Sprintf("%s_%s", toSnakeCase(pluralize(resourceName)), "list")
-	case "update":
-		return fmt.Sprintf("%s_%s", toSnakeCase(resourceName), "update")
-	case "delete":
-		return fmt.Sprintf("%s_%s", toSnakeCase(resourceName), "delete")
+// generateToolName generates an MCP tool name, prefixed with the plural of the resource for every
+// operation, e.g. widgets_get and widgets_list. A non-empty prefix goes in front of the plural, e.g.
+// acme_widgets_get, to tell apart the tools of resources with the same plural in different groups.
+func generateToolName(prefix, operation, plural string) string {
+	name := fmt.Sprintf("%s_%s", toSnakeCase(plural), toSnakeCase(operation))
+	if prefix != "" {
+		name = toSnakeCase(prefix) + "_" + name
+	}
+	return name
+}
+
+// deepCopyField returns the statements that deep copy a struct field from in to out inside a
+// generated DeepCopyInto method. Value fields are already copied by "*out = *in", so an empty
+// string is returned for them.
+func deepCopyField(field *analyzer.GoTypeInfo) string {
+	name := field.GetGoFieldName()
+
+	switch {
+	case field.IsComplexType(), field.IsRawJSON():
+		return fmt.Sprintf("in.%s.DeepCopyInto(&out.%s)", name, name)
+	case field.GoType == "map[string]interface{}":
+		return fmt.Sprintf("if in.%[1]s != nil {\n\tout.%[1]s = runtime.DeepCopyJSON(in.%[1]s)\n}", name)
+	case field.GoType == "interface{}":
+		return fmt.Sprintf("if in.%[1]s != nil {\n\tout.%[1]s = runtime.DeepCopyJSONValue(in.%[1]s)\n}", name)
+	case field.IsArrayType():
+		return deepCopySliceField(name, field)
+	case field.IsMapType() && field.Values != nil:
+		return deepCopyMapField(name, field)
 	default:
-		return fmt.Sprintf("%s_%s", toSnakeCase(resourceName), toSnakeCase(operation))
+		return ""
+	}
+}
+
+// deepCopySliceField returns the statements that deep copy a slice field
+func deepCopySliceField(name string, field *analyzer.GoTypeInfo) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "if in.%s != nil {\n", name)
+	fmt.Fprintf(&sb, "\tin, out := &in.%s, &out.%s\n", name, name)
+	fmt.Fprintf(&sb, "\t*out = make(%s, len(*in))\n", field.GoType)
+
+	switch {
+	case field.Items != nil && field.Items.IsComplexType():
+		sb.WriteString("\tfor i := range *in {\n\t\t(*in)[i].DeepCopyInto(&(*out)[i])\n\t}\n")
+	case field.GoType == "[]interface{}":
+		sb.WriteString("\tfor i := range *in {\n\t\t(*out)[i] = runtime.DeepCopyJSONValue((*in)[i])\n\t}\n")
+	default:
+		sb.WriteString("\tcopy(*out, *in)\n")
+	}
+
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// deepCopyMapField returns the statements that deep copy a typed map field
+func deepCopyMapField(name string, field *analyzer.GoTypeInfo) string {
+	values := field.Values
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "if in.%s != nil {\n", name)
+	fmt.Fprintf(&sb, "\tin, out := &in.%s, &out.%s\n", name, name)
+	fmt.Fprintf(&sb, "\t*out = make(%s, len(*in))\n", field.GoType)
+	sb.WriteString("\tfor key, val := range *in {\n")
+
+	switch {
+	case values.IsComplexType(), values.IsRawJSON():
+		sb.WriteString("\t\t(*out)[key] = *val.DeepCopy()\n")
+	case values.GoType == "map[string]interface{}":
+		sb.WriteString("\t\t(*out)[key] = runtime.DeepCopyJSON(val)\n")
+	case values.GoType == "interface{}":
+		sb.WriteString("\t\t(*out)[key] = runtime.DeepCopyJSONValue(val)\n")
+	case values.IsArrayType() && (values.GoType == "[]byte" || values.Items != nil && values.Items.IsPrimitiveType()):
+		fmt.Fprintf(&sb, "\t\tvar outVal %s\n", values.GoType)
+		sb.WriteString("\t\tif val != nil {\n")
+		fmt.Fprintf(&sb, "\t\t\toutVal = make(%s, len(val))\n", values.GoType)
+		sb.WriteString("\t\t\tcopy(outVal, val)\n\t\t}\n")
+		sb.WriteString("\t\t(*out)[key] = outVal\n")
+	default:
+		sb.WriteString("\t\t(*out)[key] = val\n")
 	}
+
+	sb.WriteString("\t}\n}")
+	return sb.String()
 }
 
 // convertSchemaToGoCode converts an OpenAPI schema to Go code that generates a JSON schema
@@ -404,16 +561,92 @@ func convertSchemaToGoCode(schemaInterface interface{}, indent int) string {
 		return ""
 	}
 
-	indentStr := strings.Repeat("\t", indent)
 	var sb strings.Builder
+	sb.Grow(estimateSchemaCodeSize(schema, indent))
+	writeSchemaGoCode(&sb, schema, indent)
+	return sb.String()
+}
+
+// writeSchemaGoCode writes the Go code of schema to sb. Subschemas are written to the same builder
+// instead of being converted on their own and copied, which keeps large schemas fast.
+func writeSchemaGoCode(sb *strings.Builder, schema *apiextensionsv1.JSONSchemaProps, indent int) {
+	indentStr := indentation(indent)
 
 	sb.WriteString("&jsonschema.Schema{\n")
-	appendBasicSchemaFields(&sb, schema, indentStr)
-	appendSchemaValidation(&sb, schema, indentStr)
-	appendSchemaStructure(&sb, schema, indentStr, indent)
-	sb.WriteString(fmt.Sprintf("%s}", indentStr))
+	appendBasicSchemaFields(sb, schema, indentStr)
+	appendSchemaValidation(sb, schema, indentStr)
+	appendSchemaComposition(sb, schema, indentStr, indent)
+	appendSchemaStructure(sb, schema, indentStr, indent)
+	sb.WriteString(indentStr)
+	sb.WriteString("}")
+}
 
-	return sb.String()
+// indents holds the indentation of schema code up to a depth deeper than CRDs usually nest, so it
+// is sliced rather than built for every schema
+const indents = "\t\t\t\t\t\t\t\t\t\t\t\t\t\t\t\t\t\t\t\t\t\t\t\t\t\t\t\t\t\t\t\t"
+
+// indentation returns level tabs
+func indentation(level int) string {
+	if level <= len(indents) {
+		return indents[:level]
+	}
+	return strings.Repeat("\t", level)
+}
+
+// estimateSchemaCodeSize estimates the length of the Go code of schema, so the builder it is
+// written to is allocated once. Each schema takes a few lines at its indentation, plus a line per
+// constraint, which is also summarized in the description, and its enum, default and required lists.
+func estimateSchemaCodeSize(schema *apiextensionsv1.JSONSchemaProps, indent int) int {
+	size := 96 + 4*indent + len(schema.Description)
+
+	constraints := 0
+	for _, set := range []bool{
+		schema.Format != "", schema.Minimum != nil, schema.Maximum != nil, schema.MultipleOf != nil,
+		schema.MinLength != nil, schema.MaxLength != nil, schema.Pattern != "", schema.MinItems != nil,
+		schema.MaxItems != nil, schema.UniqueItems, schema.MinProperties != nil, schema.MaxProperties != nil,
+	} {
+		if set {
+			constraints++
+		}
+	}
+	size += constraints*(64+indent) + 2*len(schema.Pattern)
+	if len(schema.Enum) > 0 {
+		size += 32 + indent
+		for _, value := range schema.Enum {
+			size += len(value.Raw) + 2
+		}
+	}
+	if schema.Default != nil {
+		size += 48 + indent + 2*len(schema.Default.Raw)
+	}
+	if schema.XIntOrString {
+		size += 96 + indent
+	}
+	if schema.XPreserveUnknownFields != nil && *schema.XPreserveUnknownFields {
+		size += 48 + indent
+	}
+	if len(schema.Required) > 0 {
+		size += 32 + indent
+		for _, name := range schema.Required {
+			size += len(name) + 4
+		}
+	}
+
+	for name, prop := range schema.Properties {
+		size += len(name) + indent + estimateSchemaCodeSize(&prop, indent+2)
+	}
+	for _, subschemas := range [][]apiextensionsv1.JSONSchemaProps{schema.AllOf, schema.AnyOf, schema.OneOf} {
+		for i := range subschemas {
+			size += estimateSchemaCodeSize(&subschemas[i], indent+2)
+		}
+	}
+	if schema.Items != nil && schema.Items.Schema != nil {
+		size += estimateSchemaCodeSize(schema.Items.Schema, indent+1)
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		size += estimateSchemaCodeSize(schema.AdditionalProperties.Schema, indent+1)
+	}
+	return size
 }
 
 // normalizeSchemaInterface handles both pointer and value types for schema
@@ -428,17 +661,25 @@ func normalizeSchemaInterface(schemaInterface interface{}) *apiextensionsv1.JSON
 	}
 }
 
-// appendBasicSchemaFields appends type, description, and enum to schema code
+// appendBasicSchemaFields appends type, description, default, and enum to schema code
 func appendBasicSchemaFields(sb *strings.Builder, schema *apiextensionsv1.JSONSchemaProps, indentStr string) {
 	if schema.Type != "" {
 		fmt.Fprintf(sb, "%s\tType:        %q,\n", indentStr, schema.Type)
 	}
 
-	if schema.Description != "" {
-		desc := strings.ReplaceAll(schema.Description, `"`, `\"`)
+	if schema.XIntOrString {
+		fmt.Fprintf(sb, "%s\tOneOf:       []*jsonschema.Schema{{Type: \"integer\"}, {Type: \"string\"}},\n", indentStr)
+	}
+
+	if description := describeWithConstraints(schema); description != "" {
+		desc := strings.ReplaceAll(description, `"`, `\"`)
 		fmt.Fprintf(sb, "%s\tDescription: %q,\n", indentStr, desc)
 	}
 
+	if schema.Default != nil && len(schema.Default.Raw) > 0 {
+		fmt.Fprintf(sb, "%s\tDefault:     json.RawMessage(%q),\n", indentStr, string(schema.Default.Raw))
+	}
+
 	if len(schema.Enum) > 0 {
 		fmt.Fprintf(sb, "%s\tEnum:        []any{", indentStr)
 		for i, val := range schema.Enum {
@@ -451,13 +692,98 @@ func appendBasicSchemaFields(sb *strings.Builder, schema *apiextensionsv1.JSONSc
 	}
 }
 
+// describeWithConstraints returns the description of schema followed by a summary of its type,
+// format and constraints, e.g. "Number of replicas (integer, min 1, max 10)", so LLMs see the
+// valid values without reading the validation keywords. Schemas without a format or constraint
+// keep their description as is.
+func describeWithConstraints(schema *apiextensionsv1.JSONSchemaProps) string {
+	summary := constraintSummary(schema)
+	switch {
+	case summary == "":
+		return schema.Description
+	case schema.Description == "":
+		return summary
+	default:
+		return strings.TrimRight(schema.Description, " \n") + " " + summary
+	}
+}
+
+// constraintSummary summarizes the type, format and constraints of schema in parentheses, e.g.
+// "(integer, min 1, max 10)", or returns an empty string if it has neither a format nor constraints
+func constraintSummary(schema *apiextensionsv1.JSONSchemaProps) string {
+	formatNumber := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	var constraints []string
+	if schema.Format != "" {
+		constraints = append(constraints, schema.Format)
+	}
+	if schema.Minimum != nil {
+		if schema.ExclusiveMinimum {
+			constraints = append(constraints, "greater than "+formatNumber(*schema.Minimum))
+		} else {
+			constraints = append(constraints, "min "+formatNumber(*schema.Minimum))
+		}
+	}
+	if schema.Maximum != nil {
+		if schema.ExclusiveMaximum {
+			constraints = append(constraints, "less than "+formatNumber(*schema.Maximum))
+		} else {
+			constraints = append(constraints, "max "+formatNumber(*schema.Maximum))
+		}
+	}
+	if schema.MultipleOf != nil {
+		constraints = append(constraints, "multiple of "+formatNumber(*schema.MultipleOf))
+	}
+	if schema.MinLength != nil {
+		constraints = append(constraints, fmt.Sprintf("min length %d", *schema.MinLength))
+	}
+	if schema.MaxLength != nil {
+		constraints = append(constraints, fmt.Sprintf("max length %d", *schema.MaxLength))
+	}
+	if schema.Pattern != "" {
+		constraints = append(constraints, "pattern "+schema.Pattern)
+	}
+	if schema.MinItems != nil {
+		constraints = append(constraints, fmt.Sprintf("min %d items", *schema.MinItems))
+	}
+	if schema.MaxItems != nil {
+		constraints = append(constraints, fmt.Sprintf("max %d items", *schema.MaxItems))
+	}
+	if schema.UniqueItems {
+		constraints = append(constraints, "unique items")
+	}
+
+	if len(constraints) == 0 {
+		return ""
+	}
+	if schema.Type != "" {
+		constraints = append([]string{schema.Type}, constraints...)
+	}
+	return "(" + strings.Join(constraints, ", ") + ")"
+}
+
 // appendSchemaValidation appends validation constraints to schema code
 func appendSchemaValidation(sb *strings.Builder, schema *apiextensionsv1.JSONSchemaProps, indentStr string) {
+	// CRDs use the boolean exclusiveMinimum/exclusiveMaximum of OpenAPI 3.0, while the JSON Schema
+	// drafts of the go-sdk take the exclusive bound itself as the keyword value
 	if schema.Minimum != nil {
-		fmt.Fprintf(sb, "%s\tMinimum:     ptr.To(float64(%v)),\n", indentStr, *schema.Minimum)
+		keyword := "Minimum"
+		if schema.ExclusiveMinimum {
+			keyword = "ExclusiveMinimum"
+		}
+		fmt.Fprintf(sb, "%s\t%s: ptr.To(float64(%v)),\n", indentStr, keyword, *schema.Minimum)
 	}
 	if schema.Maximum != nil {
-		fmt.Fprintf(sb, "%s\tMaximum:     ptr.To(float64(%v)),\n", indentStr, *schema.Maximum)
+		keyword := "Maximum"
+		if schema.ExclusiveMaximum {
+			keyword = "ExclusiveMaximum"
+		}
+		fmt.Fprintf(sb, "%s\t%s: ptr.To(float64(%v)),\n", indentStr, keyword, *schema.Maximum)
+	}
+	if schema.MultipleOf != nil {
+		fmt.Fprintf(sb, "%s\tMultipleOf:  ptr.To(float64(%v)),\n", indentStr, *schema.MultipleOf)
 	}
 	if schema.MinLength != nil {
 		fmt.Fprintf(sb, "%s\tMinLength:   ptr.To(%d),\n", indentStr, *schema.MinLength)
@@ -468,16 +794,74 @@ func appendSchemaValidation(sb *strings.Builder, schema *apiextensionsv1.JSONSch
 	if schema.Pattern != "" {
 		fmt.Fprintf(sb, "%s\tPattern:     %q,\n", indentStr, schema.Pattern)
 	}
+	if schema.MinItems != nil {
+		fmt.Fprintf(sb, "%s\tMinItems:    ptr.To(%d),\n", indentStr, *schema.MinItems)
+	}
+	if schema.MaxItems != nil {
+		fmt.Fprintf(sb, "%s\tMaxItems:    ptr.To(%d),\n", indentStr, *schema.MaxItems)
+	}
+	if schema.UniqueItems {
+		fmt.Fprintf(sb, "%s\tUniqueItems: true,\n", indentStr)
+	}
+}
+
+// appendSchemaComposition appends allOf, anyOf, and oneOf subschemas to schema code
+func appendSchemaComposition(sb *strings.Builder, schema *apiextensionsv1.JSONSchemaProps, indentStr string, indent int) {
+	appendSubschemas(sb, "AllOf", schema.AllOf, indentStr, indent)
+
+	// Int-or-string fields already emit their integer/string alternatives
+	if schema.XIntOrString {
+		return
+	}
+	appendSubschemas(sb, "AnyOf", schema.AnyOf, indentStr, indent)
+	appendSubschemas(sb, "OneOf", schema.OneOf, indentStr, indent)
+}
+
+// appendSubschemas appends a list of subschemas under the given jsonschema.Schema field
+func appendSubschemas(sb *strings.Builder, field string, schemas []apiextensionsv1.JSONSchemaProps, indentStr string, indent int) {
+	if len(schemas) == 0 {
+		return
+	}
+
+	fmt.Fprintf(sb, "%s\t%s: []*jsonschema.Schema{\n", indentStr, field)
+	for i := range schemas {
+		fmt.Fprintf(sb, "%s\t\t", indentStr)
+		writeSchemaGoCode(sb, &schemas[i], indent+2)
+		sb.WriteString(",\n")
+	}
+	fmt.Fprintf(sb, "%s\t},\n", indentStr)
 }
 
 // appendSchemaStructure appends properties, required fields, items, and additional properties
 func appendSchemaStructure(sb *strings.Builder, schema *apiextensionsv1.JSONSchemaProps, indentStr string, indent int) {
+	// Size limits apply to maps and preserved subtrees alike
+	if schema.MinProperties != nil {
+		fmt.Fprintf(sb, "%s\tMinProperties: ptr.To(%d),\n", indentStr, *schema.MinProperties)
+	}
+	if schema.MaxProperties != nil {
+		fmt.Fprintf(sb, "%s\tMaxProperties: ptr.To(%d),\n", indentStr, *schema.MaxProperties)
+	}
+
+	// Subtrees that preserve unknown fields accept any content, so their properties are not emitted.
+	// An empty schema accepts any value, i.e. additionalProperties: true.
+	if schema.XPreserveUnknownFields != nil && *schema.XPreserveUnknownFields {
+		fmt.Fprintf(sb, "%s\tAdditionalProperties: &jsonschema.Schema{},\n", indentStr)
+		return
+	}
+
 	if len(schema.Properties) > 0 {
 		fmt.Fprintf(sb, "%s\tProperties: map[string]*jsonschema.Schema{\n", indentStr)
+		// Sort property names for consistent output
+		propNames := make([]string, 0, len(schema.Properties))
 		for propName := range schema.Properties {
+			propNames = append(propNames, propName)
+		}
+		sort.Strings(propNames)
+
+		for _, propName := range propNames {
 			propSchema := schema.Properties[propName]
 			fmt.Fprintf(sb, "%s\t\t%q: ", indentStr, propName)
-			sb.WriteString(convertSchemaToGoCode(&propSchema, indent+2))
+			writeSchemaGoCode(sb, &propSchema, indent+2)
 			sb.WriteString(",\n")
 		}
 		fmt.Fprintf(sb, "%s\t},\n", indentStr)
@@ -496,13 +880,13 @@ func appendSchemaStructure(sb *strings.Builder, schema *apiextensionsv1.JSONSche
 
 	if schema.Items != nil && schema.Items.Schema != nil {
 		fmt.Fprintf(sb, "%s\tItems: ", indentStr)
-		sb.WriteString(convertSchemaToGoCode(schema.Items.Schema, indent+1))
+		writeSchemaGoCode(sb, schema.Items.Schema, indent+1)
 		sb.WriteString(",\n")
 	}
 
 	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
 		fmt.Fprintf(sb, "%s\tAdditionalProperties: ", indentStr)
-		sb.WriteString(convertSchemaToGoCode(schema.AdditionalProperties.Schema, indent+1))
+		writeSchemaGoCode(sb, schema.AdditionalProperties.Schema, indent+1)
 		sb.WriteString(",\n")
 	}
 }
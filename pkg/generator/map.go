@@ -0,0 +1,33 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/friedrichwilken/mcp-toolgen/pkg/analyzer"
+)
+
+// GenerateToolsetToMap renders every toolset file and returns their content by filename, for
+// callers that embed the generator and handle the files themselves. Nothing is written to disk.
+func (g *Generator) GenerateToolsetToMap(toolsetInfo *analyzer.ToolsetInfo) (map[string]string, error) {
+	if toolsetInfo == nil {
+		return nil, fmt.Errorf("toolset info is required")
+	}
+
+	files, err := g.renderToolset(toolsetInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	writer := NewFileWriter(g.config.OutputDir, true, true, g.logger)
+	contents := make(map[string]string, len(files))
+
+	for _, file := range files {
+		content, err := g.renderOutput(writer, file)
+		if err != nil {
+			return nil, err
+		}
+		contents[file.filename] = content
+	}
+
+	return contents, nil
+}
@@ -0,0 +1,40 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateToolsetToMap(t *testing.T) {
+	outputDir := t.TempDir()
+
+	gen, err := NewGenerator(&GeneratorConfig{
+		OutputDir:       outputDir,
+		PackageName:     "widgets",
+		ModulePath:      "github.com/test/module",
+		IncludeComments: true,
+	})
+	require.NoError(t, err)
+
+	files, err := gen.GenerateToolsetToMap(loadDiffTestToolset(t, "../../test/fixtures/simple-crd.yaml"))
+	require.NoError(t, err)
+
+	require.Len(t, files, len(toolsetFiles))
+	for _, file := range toolsetFiles {
+		content, ok := files[file.filename]
+		require.True(t, ok, "missing %s", file.filename)
+
+		parsed, err := parser.ParseFile(token.NewFileSet(), file.filename, content, parser.AllErrors)
+		require.NoError(t, err, "%s should be valid Go", file.filename)
+		assert.Equal(t, "widgets", parsed.Name.Name, file.filename)
+	}
+
+	entries, err := os.ReadDir(outputDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "generating to a map must not write files")
+}
@@ -0,0 +1,110 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Markers delimiting a custom region of a generated file. The text after a marker names the
+// region. Content inside custom regions is kept when a file is regenerated in merge mode.
+const (
+	customRegionBegin = "// mcp-toolgen:begin-custom"
+	customRegionEnd   = "// mcp-toolgen:end-custom"
+)
+
+// mergeCustomRegions returns generated with the body of every custom region replaced by the body
+// of the region with the same name in existing. Regions that only exist in the generated content
+// keep their generated body. A non-empty region of existing that the generated content no longer
+// has is an error, since its content would otherwise be lost.
+func mergeCustomRegions(existing, generated string) (string, error) {
+	custom, err := extractCustomRegions(existing)
+	if err != nil {
+		return "", fmt.Errorf("existing file: %w", err)
+	}
+	if _, err := extractCustomRegions(generated); err != nil {
+		return "", fmt.Errorf("generated file: %w", err)
+	}
+
+	var merged []string
+	preserved := make(map[string]bool)
+	region := ""
+	inRegion := false
+	for _, line := range strings.Split(generated, "\n") {
+		if name, ok := customRegionName(line, customRegionBegin); ok {
+			merged = append(merged, line)
+			region, inRegion = name, true
+			if body, exists := custom[region]; exists {
+				merged = append(merged, body...)
+			}
+			continue
+		}
+
+		if _, ok := customRegionName(line, customRegionEnd); ok {
+			merged = append(merged, line)
+			preserved[region] = true
+			inRegion = false
+			continue
+		}
+
+		// The generated body of a region is dropped when the existing file provides one
+		if _, exists := custom[region]; inRegion && exists {
+			continue
+		}
+		merged = append(merged, line)
+	}
+
+	for name, body := range custom {
+		if !preserved[name] && strings.TrimSpace(strings.Join(body, "")) != "" {
+			return "", fmt.Errorf("custom region %q does not exist in the generated file", name)
+		}
+	}
+
+	return strings.Join(merged, "\n"), nil
+}
+
+// extractCustomRegions returns the lines inside each custom region of content, keyed by region name
+func extractCustomRegions(content string) (map[string][]string, error) {
+	regions := make(map[string][]string)
+	region := ""
+	inRegion := false
+
+	for i, line := range strings.Split(content, "\n") {
+		if name, ok := customRegionName(line, customRegionBegin); ok {
+			if inRegion {
+				return nil, fmt.Errorf("line %d: custom region %q starts inside region %q", i+1, name, region)
+			}
+			if _, exists := regions[name]; exists {
+				return nil, fmt.Errorf("line %d: duplicate custom region %q", i+1, name)
+			}
+			region, inRegion = name, true
+			regions[region] = []string{}
+			continue
+		}
+
+		if name, ok := customRegionName(line, customRegionEnd); ok {
+			if !inRegion || name != region {
+				return nil, fmt.Errorf("line %d: end of custom region %q without matching begin", i+1, name)
+			}
+			inRegion = false
+			continue
+		}
+
+		if inRegion {
+			regions[region] = append(regions[region], line)
+		}
+	}
+
+	if inRegion {
+		return nil, fmt.Errorf("custom region %q is not terminated", region)
+	}
+	return regions, nil
+}
+
+// customRegionName reports whether line is the given custom region marker and returns the region name
+func customRegionName(line, marker string) (string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(line), marker)
+	if !ok || (rest != "" && rest[0] != ' ') {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
@@ -0,0 +1,146 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/friedrichwilken/mcp-toolgen/pkg/analyzer"
+)
+
+func TestMergeCustomRegions(t *testing.T) {
+	generated := `package widgets
+
+func handle() {
+	// mcp-toolgen:begin-custom arguments
+	// generated placeholder
+	// mcp-toolgen:end-custom arguments
+	call()
+}
+
+// mcp-toolgen:begin-custom helpers
+// mcp-toolgen:end-custom helpers
+`
+
+	tests := []struct {
+		name     string
+		existing string
+		expected string
+		errorMsg string
+	}{
+		{
+			name: "custom content is preserved",
+			existing: `package widgets
+
+func handle() {
+	// mcp-toolgen:begin-custom arguments
+	validate()
+	// mcp-toolgen:end-custom arguments
+	oldCall()
+}
+
+// mcp-toolgen:begin-custom helpers
+func validate() {}
+// mcp-toolgen:end-custom helpers
+`,
+			expected: `package widgets
+
+func handle() {
+	// mcp-toolgen:begin-custom arguments
+	validate()
+	// mcp-toolgen:end-custom arguments
+	call()
+}
+
+// mcp-toolgen:begin-custom helpers
+func validate() {}
+// mcp-toolgen:end-custom helpers
+`,
+		},
+		{
+			name:     "file without regions takes the generated content",
+			existing: "package widgets\n",
+			expected: generated,
+		},
+		{
+			name: "removed region with content",
+			existing: `// mcp-toolgen:begin-custom imports
+import "fmt"
+// mcp-toolgen:end-custom imports
+`,
+			errorMsg: `custom region "imports" does not exist in the generated file`,
+		},
+		{
+			name: "unterminated region",
+			existing: `// mcp-toolgen:begin-custom helpers
+func validate() {}
+`,
+			errorMsg: `existing file: custom region "helpers" is not terminated`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := mergeCustomRegions(tt.existing, generated)
+			if tt.errorMsg != "" {
+				assert.ErrorContains(t, err, tt.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, merged)
+		})
+	}
+}
+
+func TestRegenerateKeepsCustomRegions(t *testing.T) {
+	outputDir := t.TempDir()
+	handlersPath := filepath.Join(outputDir, "handlers.go")
+
+	generate := func(merge bool) string {
+		t.Helper()
+
+		crdInfo, err := analyzer.NewCRDAnalyzer().ParseCRDFromFile("../../test/fixtures/simple-crd.yaml")
+		require.NoError(t, err)
+		config := analyzer.DefaultGenerationConfig()
+		config.PackageName = "widgets"
+		config.OutputDir = outputDir
+		toolsetInfo, err := analyzer.NewToolsetInfo(crdInfo, config)
+		require.NoError(t, err)
+
+		gen, err := NewGenerator(&GeneratorConfig{
+			OutputDir:       outputDir,
+			PackageName:     "widgets",
+			ModulePath:      "github.com/test/module",
+			OverwriteFiles:  true,
+			IncludeComments: true,
+			MergeCustom:     merge,
+		})
+		require.NoError(t, err)
+		require.NoError(t, gen.GenerateToolset(toolsetInfo))
+
+		content, err := os.ReadFile(handlersPath)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	handlers := generate(true)
+
+	// Simulate hand edits inside two custom regions
+	customArgs := "\tif args[\"name\"] == \"forbidden\" {\n\t\treturn nil, errForbidden\n\t}\n"
+	customHelpers := "var errForbidden = errors.New(\"forbidden\")\n"
+	handlers = strings.Replace(handlers, "\t// mcp-toolgen:end-custom get-arguments\n", customArgs+"\t// mcp-toolgen:end-custom get-arguments\n", 1)
+	handlers = strings.Replace(handlers, "// mcp-toolgen:end-custom helpers\n", customHelpers+"// mcp-toolgen:end-custom helpers\n", 1)
+	require.NoError(t, os.WriteFile(handlersPath, []byte(handlers), 0o644))
+
+	merged := generate(true)
+	assert.Contains(t, merged, "\t// mcp-toolgen:begin-custom get-arguments\n"+customArgs+"\t// mcp-toolgen:end-custom get-arguments\n")
+	assert.Contains(t, merged, "// mcp-toolgen:begin-custom helpers\n"+customHelpers)
+	assert.Equal(t, merged, generate(true), "merging should be stable across regenerations")
+
+	replaced := generate(false)
+	assert.NotContains(t, replaced, "errForbidden", "replace mode should drop custom content")
+}
@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/friedrichwilken/mcp-toolgen/pkg/analyzer"
+)
+
+// ToolsetPlan describes what generating a toolset would write, for tooling that consumes a dry
+// run as JSON instead of reading the generated code
+type ToolsetPlan struct {
+	Kind       string   `json:"kind"`
+	Group      string   `json:"group"`
+	Version    string   `json:"version"`
+	Plural     string   `json:"plural"`
+	Scope      string   `json:"scope"`
+	Package    string   `json:"package"`
+	ImportPath string   `json:"importPath"`
+	OutputDir  string   `json:"outputDir"`
+	Files      []string `json:"files"`      // Paths of the files the toolset is written to
+	Operations []string `json:"operations"` // CRUD operations followed by the custom operations
+	Tools      []string `json:"tools"`      // Names of the tools, including the short name aliases
+}
+
+// PlanToolset returns the plan of generating the toolset, without rendering or writing any file
+func (g *Generator) PlanToolset(toolsetInfo *analyzer.ToolsetInfo) (*ToolsetPlan, error) {
+	if toolsetInfo == nil {
+		return nil, fmt.Errorf("toolset info is required")
+	}
+
+	crd := toolsetInfo.CRD
+	plan := &ToolsetPlan{
+		Kind:       crd.Kind,
+		Group:      crd.Group,
+		Version:    crd.Version,
+		Plural:     crd.Plural,
+		Scope:      string(crd.Scope),
+		Package:    toolsetInfo.PackageName,
+		ImportPath: toolsetInfo.ImportPath,
+		OutputDir:  g.config.OutputDir,
+		Files:      []string{},
+		Operations: toolsetInfo.GetResourceOperations(),
+	}
+	for _, filename := range g.OutputFilenames(toolsetInfo) {
+		plan.Files = append(plan.Files, filepath.Join(g.config.OutputDir, filename))
+	}
+	for _, operation := range toolsetInfo.Config.CustomOperations {
+		plan.Operations = append(plan.Operations, operation.Name)
+	}
+
+	prefix := toolsetInfo.Config.ToolPrefix
+	for _, name := range append([]string{crd.Plural}, toolsetInfo.GetShortNameAliases()...) {
+		for _, operation := range plan.Operations {
+			plan.Tools = append(plan.Tools, generateToolName(prefix, operation, name))
+		}
+	}
+	return plan, nil
+}
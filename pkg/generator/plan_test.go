@@ -0,0 +1,44 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanToolset(t *testing.T) {
+	outputDir := t.TempDir()
+
+	gen, err := NewGenerator(&GeneratorConfig{
+		OutputDir:   outputDir,
+		PackageName: "widgets",
+		ModulePath:  "github.com/test/module",
+	})
+	require.NoError(t, err)
+
+	toolsetInfo := loadDiffTestToolset(t, "../../test/fixtures/simple-crd.yaml")
+	toolsetInfo.Config.ShortNameTools = true
+	toolsetInfo.CRD.ShortNames = []string{"wgt"}
+	plan, err := gen.PlanToolset(toolsetInfo)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Widget", plan.Kind)
+	assert.Equal(t, "example.com", plan.Group)
+	assert.Equal(t, "Namespaced", plan.Scope)
+	assert.Equal(t, "github.com/test/module/pkg/widgets", plan.ImportPath)
+	assert.Equal(t, []string{"create", "get", "list", "update", "delete"}, plan.Operations)
+	assert.Equal(t, []string{
+		"widgets_create", "widgets_get", "widgets_list", "widgets_update", "widgets_delete",
+		"wgt_create", "wgt_get", "wgt_list", "wgt_update", "wgt_delete",
+	}, plan.Tools)
+	for _, file := range toolsetFiles {
+		assert.Contains(t, plan.Files, filepath.Join(outputDir, file.filename))
+	}
+
+	entries, err := os.ReadDir(outputDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "planning must not write files")
+}
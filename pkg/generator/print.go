@@ -0,0 +1,37 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/friedrichwilken/mcp-toolgen/pkg/analyzer"
+)
+
+// PrintToolset renders every toolset file and writes its content to out, each file preceded by a
+// header line with the path it would be written to. Nothing is written to disk.
+func (g *Generator) PrintToolset(toolsetInfo *analyzer.ToolsetInfo, out io.Writer) error {
+	if toolsetInfo == nil {
+		return fmt.Errorf("toolset info is required")
+	}
+
+	files, err := g.renderToolset(toolsetInfo)
+	if err != nil {
+		return err
+	}
+
+	writer := NewFileWriter(g.config.OutputDir, true, true, g.logger)
+
+	for _, file := range files {
+		content, err := g.renderOutput(writer, file)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(out, "// ===== %s =====\n%s\n", filepath.Join(g.config.OutputDir, file.filename), content); err != nil {
+			return fmt.Errorf("failed to print %s: %w", file.filename, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package generator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintToolset(t *testing.T) {
+	outputDir := t.TempDir()
+
+	gen, err := NewGenerator(&GeneratorConfig{
+		OutputDir:       outputDir,
+		PackageName:     "widgets",
+		ModulePath:      "github.com/test/module",
+		IncludeComments: true,
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, gen.PrintToolset(loadDiffTestToolset(t, "../../test/fixtures/simple-crd.yaml"), &buf))
+
+	for _, file := range toolsetFiles {
+		assert.Contains(t, buf.String(), "// ===== "+filepath.Join(outputDir, file.filename)+" =====\n", file.filename)
+	}
+	assert.Equal(t, len(toolsetFiles), bytes.Count(buf.Bytes(), []byte("\npackage widgets\n")))
+
+	entries, err := os.ReadDir(outputDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "printing must not write files")
+}
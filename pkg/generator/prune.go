@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// generatedMarker is a line of the header of every generated toolset file, so pruning can tell
+// them from files users added to the output directory. It leaves out the conventional
+// "DO NOT EDIT" since the custom regions of generated files are meant to be edited.
+const generatedMarker = "// Code generated by mcp-toolgen."
+
+// isGeneratedFile reports whether Go source carries the generated marker before its package clause
+func isGeneratedFile(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == generatedMarker {
+			return true
+		}
+		if strings.HasPrefix(line, "package ") {
+			return false
+		}
+	}
+	return false
+}
+
+// pruneStaleFiles removes the generated .go files of the output directory that are not among
+// the files of the current generation. Files without the generated marker are never removed.
+func (g *Generator) pruneStaleFiles(files []renderedFile) error {
+	keep := make(map[string]bool, len(files))
+	for _, file := range files {
+		keep[file.filename] = true
+	}
+
+	entries, err := os.ReadDir(g.config.OutputDir)
+	if err != nil {
+		return fmt.Errorf("failed to read output directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".go" || keep[entry.Name()] {
+			continue
+		}
+
+		path := filepath.Join(g.config.OutputDir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if !isGeneratedFile(string(content)) {
+			continue
+		}
+
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove stale file %s: %w", path, err)
+		}
+		g.logger.Info("removed stale file", "filename", entry.Name(), "dir", g.config.OutputDir)
+	}
+	return nil
+}
+
+// isToolgenFile reports whether the file name of the output directory with content was written by
+// mcp-toolgen: a file with the generated marker, or the go:generate file that reruns it
+func isToolgenFile(name, content string) bool {
+	if filepath.Ext(name) != ".go" {
+		return false
+	}
+	if name == GoGenerateFilename && strings.Contains(content, "//go:generate mcp-toolgen ") {
+		return true
+	}
+	return isGeneratedFile(content)
+}
+
+// removeOutputDir removes the output directory so the toolset is generated into a clean one.
+// It refuses to if the directory holds files mcp-toolgen did not write, unless ForceClean is set.
+func (g *Generator) removeOutputDir() error {
+	dir := g.config.OutputDir
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read output directory: %w", err)
+	}
+
+	var foreign []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			foreign = append(foreign, entry.Name()+"/")
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", filepath.Join(dir, entry.Name()), err)
+		}
+		if !isToolgenFile(entry.Name(), string(content)) {
+			foreign = append(foreign, entry.Name())
+		}
+	}
+	if len(foreign) > 0 && !g.config.ForceClean {
+		return fmt.Errorf("refusing to remove output directory %s, it contains files not generated by mcp-toolgen: %s",
+			dir, strings.Join(foreign, ", "))
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove output directory %s: %w", dir, err)
+	}
+	g.logger.Info("removed output directory", "dir", dir, "files", len(entries))
+	return nil
+}
@@ -0,0 +1,127 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsGeneratedFile(t *testing.T) {
+	assert.True(t, isGeneratedFile(generatedMarker+"\n\npackage widgets\n"))
+	assert.True(t, isGeneratedFile("//go:build mytools\n\n// Copyright 2025\n\n"+generatedMarker+"\n\npackage widgets\n"))
+	assert.False(t, isGeneratedFile("package widgets\n"))
+	assert.False(t, isGeneratedFile("package widgets\n\n"+generatedMarker+"\n"), "the marker must come before the package clause")
+}
+
+func TestGeneratePruneStaleFiles(t *testing.T) {
+	outputDir := t.TempDir()
+	generate := func(singleFile, prune bool, operations []string) {
+		t.Helper()
+
+		toolsetInfo := loadDiffTestToolset(t, "../../test/fixtures/simple-crd.yaml")
+		toolsetInfo.Config.SelectedOperations = operations
+		toolsetInfo.Config.GeneratePrompts = operations == nil
+		toolsetInfo.Config.GenerateTests = operations == nil
+
+		gen, err := NewGenerator(&GeneratorConfig{
+			OutputDir:      outputDir,
+			PackageName:    "widgets",
+			ModulePath:     "github.com/test/module",
+			OverwriteFiles: true,
+			SingleFile:     singleFile,
+			Prune:          prune,
+		})
+		require.NoError(t, err)
+		require.NoError(t, gen.GenerateToolset(toolsetInfo))
+	}
+
+	// All operations with prompts and tests, next to files users added
+	generate(false, false, nil)
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "custom.go"), []byte("package widgets\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "notes.txt"), []byte(generatedMarker+"\n"), 0o644))
+	assert.FileExists(t, filepath.Join(outputDir, "prompts.go"))
+	assert.FileExists(t, filepath.Join(outputDir, "handlers_test.go"))
+
+	// Without pruning, files that are no longer generated linger
+	generate(false, false, []string{"create"})
+	assert.FileExists(t, filepath.Join(outputDir, "prompts.go"))
+
+	generate(false, true, []string{"create"})
+	assert.Equal(t, []string{
+		"client.go", "custom.go", "doc.go", "errors.go", "handlers.go", "notes.txt", "options.go", "register.go", "schema.go", "toolset.go", "types.go",
+	}, listDir(t, outputDir))
+
+	// Switching to single-file mode leaves only the combined file and the files users added
+	generate(true, true, []string{"create"})
+	assert.Equal(t, []string{"custom.go", "notes.txt", "widgets.go"}, listDir(t, outputDir))
+}
+
+func TestGenerateForceRemovesOutputDir(t *testing.T) {
+	outputDir := t.TempDir()
+	generate := func(force, forceClean bool, operations []string) error {
+		t.Helper()
+
+		toolsetInfo := loadDiffTestToolset(t, "../../test/fixtures/simple-crd.yaml")
+		toolsetInfo.Config.SelectedOperations = operations
+		toolsetInfo.Config.GeneratePrompts = operations == nil
+
+		gen, err := NewGenerator(&GeneratorConfig{
+			OutputDir:      outputDir,
+			PackageName:    "widgets",
+			ModulePath:     "github.com/test/module",
+			OverwriteFiles: true,
+			Force:          force,
+			ForceClean:     forceClean,
+		})
+		require.NoError(t, err)
+		return gen.GenerateToolset(toolsetInfo)
+	}
+
+	require.NoError(t, generate(false, false, nil))
+	require.NoError(t, GenerateGoGenerateFile(outputDir, "widgets", []string{"--crd", "crd.yaml"}, false, "", nil))
+	assert.FileExists(t, filepath.Join(outputDir, "prompts.go"))
+
+	// Stale generated files and the go:generate file go with the directory
+	require.NoError(t, generate(true, false, []string{"create"}))
+	assert.Equal(t, []string{
+		"client.go", "doc.go", "errors.go", "handlers.go", "options.go", "register.go", "schema.go", "toolset.go", "types.go",
+	}, listDir(t, outputDir))
+
+	// Files users added, even a non-Go file with the marker, and subdirectories block the removal
+	blockers := map[string]func(path string) error{
+		"custom.go": func(path string) error { return os.WriteFile(path, []byte("package widgets\n"), 0o644) },
+		"notes.txt": func(path string) error { return os.WriteFile(path, []byte(generatedMarker+"\n"), 0o644) },
+		"testdata":  func(path string) error { return os.Mkdir(path, 0o755) },
+	}
+	for name, create := range blockers {
+		require.NoError(t, create(filepath.Join(outputDir, name)))
+
+		err := generate(true, false, nil)
+		assert.ErrorContains(t, err, "contains files not generated by mcp-toolgen: "+name)
+		assert.NoFileExists(t, filepath.Join(outputDir, "prompts.go"), "nothing should be generated when the removal is refused")
+
+		require.NoError(t, generate(true, true, []string{"create"}))
+		assert.NotContains(t, listDir(t, outputDir), name)
+	}
+
+	// A missing output directory is created
+	require.NoError(t, os.RemoveAll(outputDir))
+	require.NoError(t, generate(true, false, []string{"create"}))
+	assert.FileExists(t, filepath.Join(outputDir, "handlers.go"))
+}
+
+// listDir returns the sorted names of the entries of dir
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
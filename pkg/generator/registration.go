@@ -1,15 +1,24 @@
 package generator
 
 import (
+	"bytes"
 	"fmt"
-	"go/format"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+
+	"github.com/friedrichwilken/mcp-toolgen/pkg/analyzer"
 )
 
-// RegisterInModulesFile adds an import statement to the modules.go file
-// to automatically register the generated toolset.
+// RegisterInModulesFile adds a blank import of importPath to the modules.go file
+// to automatically register the generated toolset. The import is added to the existing
+// import declaration, or to a new one when the file has no imports yet.
 func RegisterInModulesFile(modulesFilePath, importPath string) error {
 	// Read existing modules.go file
 	content, err := os.ReadFile(modulesFilePath)
@@ -17,67 +26,28 @@ func RegisterInModulesFile(modulesFilePath, importPath string) error {
 		return fmt.Errorf("failed to read modules.go: %w", err)
 	}
 
-	// Check if import already exists
-	importLine := fmt.Sprintf("import _ %q", importPath)
-	blockImportLine := fmt.Sprintf("_ %q", importPath)
-	if strings.Contains(string(content), importLine) || strings.Contains(string(content), blockImportLine) {
-		// Already registered, nothing to do
-		return nil
-	}
-
-	// Find the last import statement (handle both single-line and block imports)
-	lines := strings.Split(string(content), "\n")
-	lastImportIdx := -1
-	inImportBlock := false
-	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		// Check for import block start
-		if strings.HasPrefix(trimmed, "import (") {
-			inImportBlock = true
-			continue
-		}
-		// Check for import block end
-		if inImportBlock && trimmed == ")" {
-			// Found the end of the import block - insert before the closing paren
-			lastImportIdx = i - 1
-			break
-		}
-		// Check for single-line import
-		if strings.HasPrefix(trimmed, "import _") {
-			lastImportIdx = i
-		}
-		// Check for import inside block
-		if inImportBlock && strings.HasPrefix(trimmed, "_") {
-			lastImportIdx = i
-		}
-	}
-
-	if lastImportIdx == -1 {
-		return fmt.Errorf("no import statements found in modules.go")
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, modulesFilePath, content, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("failed to parse modules.go: %w", err)
 	}
 
-	// Format import line for block style (just the import without "import" keyword)
-	newImportLine := fmt.Sprintf("\t_ %q", importPath)
-	if inImportBlock {
-		importLine = newImportLine
+	if !astutil.AddNamedImport(fset, file, "_", importPath) {
+		// Already registered, nothing to do
+		return nil
 	}
 
-	// Insert new import after the last import
-	result := make([]string, 0, len(lines)+1)
-	result = append(result, lines[:lastImportIdx+1]...)
-	result = append(result, importLine)
-	result = append(result, lines[lastImportIdx+1:]...)
-	newContent := strings.Join(result, "\n")
+	// Keep the import block sorted the way gofmt would
+	ast.SortImports(fset, file)
 
-	// Format the Go code
-	formatted, err := format.Source([]byte(newContent))
-	if err != nil {
-		// If formatting fails, use unformatted content
-		formatted = []byte(newContent)
+	var buf bytes.Buffer
+	printerConfig := &printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	if err := printerConfig.Fprint(&buf, fset, file); err != nil {
+		return fmt.Errorf("failed to print modules.go: %w", err)
 	}
 
 	// Write back to file
-	if err := os.WriteFile(modulesFilePath, formatted, 0o644); err != nil {
+	if err := os.WriteFile(modulesFilePath, buf.Bytes(), 0o644); err != nil {
 		return fmt.Errorf("failed to write modules.go: %w", err)
 	}
 
@@ -86,8 +56,9 @@ func RegisterInModulesFile(modulesFilePath, importPath string) error {
 
 // DetermineModulesFilePath determines the path to modules.go based on the output directory
 // and module path. If modulesPath is provided, it uses that. Otherwise, it tries to
-// infer the location from the output directory.
-func DetermineModulesFilePath(outputDir, modulePath, modulesPath string) (string, error) {
+// infer the location from the output directory and the layout of the module, one of
+// analyzer.OutputLayoutPkg and analyzer.OutputLayoutFlat.
+func DetermineModulesFilePath(outputDir, modulePath, modulesPath, layout string) (string, error) {
 	if modulesPath != "" {
 		// Use provided path
 		if !filepath.IsAbs(modulesPath) {
@@ -96,35 +67,40 @@ func DetermineModulesFilePath(outputDir, modulePath, modulesPath string) (string
 		return modulesPath, nil
 	}
 
-	// Try to infer from output directory
-	// Expected pattern: <repo-root>/pkg/<package-name>
-	// modules.go should be at: <repo-root>/pkg/mcp/modules.go
-
 	absOutputDir, err := filepath.Abs(outputDir)
 	if err != nil {
 		return "", fmt.Errorf("failed to get absolute path for output: %w", err)
 	}
 
-	// Look for pkg/ directory in the path
-	parts := strings.Split(absOutputDir, string(filepath.Separator))
-	pkgIdx := -1
-	for i := len(parts) - 1; i >= 0; i-- {
-		if parts[i] == "pkg" {
-			pkgIdx = i
-			break
+	if layout == analyzer.OutputLayoutFlat {
+		// Expected pattern: <repo-root>/<package-name>
+		// modules.go should be at: <repo-root>/mcp/modules.go
+		modulesPath = filepath.Join(filepath.Dir(absOutputDir), "mcp", "modules.go")
+	} else {
+		// Expected pattern: <repo-root>/pkg/<package-name>
+		// modules.go should be at: <repo-root>/pkg/mcp/modules.go
+
+		// Look for pkg/ directory in the path
+		parts := strings.Split(absOutputDir, string(filepath.Separator))
+		pkgIdx := -1
+		for i := len(parts) - 1; i >= 0; i-- {
+			if parts[i] == "pkg" {
+				pkgIdx = i
+				break
+			}
 		}
-	}
 
-	if pkgIdx == -1 {
-		return "", fmt.Errorf("cannot infer modules.go location: output directory does not contain 'pkg' directory")
-	}
+		if pkgIdx == -1 {
+			return "", fmt.Errorf("cannot infer modules.go location: output directory does not contain 'pkg' directory")
+		}
 
-	// Construct path to modules.go
-	repoRoot := filepath.Join(parts[:pkgIdx]...)
-	if repoRoot == "" {
-		repoRoot = "/"
+		// Construct path to modules.go
+		repoRoot := filepath.Join(parts[:pkgIdx]...)
+		if repoRoot == "" {
+			repoRoot = "/"
+		}
+		modulesPath = filepath.Join(string(filepath.Separator)+repoRoot, "pkg", "mcp", "modules.go")
 	}
-	modulesPath = filepath.Join(string(filepath.Separator)+repoRoot, "pkg", "mcp", "modules.go")
 
 	// Check if file exists
 	if _, err := os.Stat(modulesPath); err != nil {
@@ -0,0 +1,124 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterInModulesFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "grouped imports",
+			input: `package mcp
+
+import (
+	_ "github.com/example/project/pkg/apples"
+	_ "github.com/example/project/pkg/pears"
+)
+`,
+			expected: `package mcp
+
+import (
+	_ "github.com/example/project/pkg/apples"
+	_ "github.com/example/project/pkg/pears"
+	_ "github.com/example/project/pkg/widgets"
+)
+`,
+		},
+		{
+			name: "single-line import",
+			input: `package mcp
+
+import _ "github.com/example/project/pkg/apples"
+`,
+			expected: `package mcp
+
+import (
+	_ "github.com/example/project/pkg/apples"
+	_ "github.com/example/project/pkg/widgets"
+)
+`,
+		},
+		{
+			name: "grouped imports with comments and code",
+			input: `package mcp
+
+// Toolsets are registered through their init functions
+import (
+	"fmt"
+
+	// Built-in toolsets
+	_ "github.com/example/project/pkg/apples"
+)
+
+var _ = fmt.Sprint
+`,
+			expected: `package mcp
+
+// Toolsets are registered through their init functions
+import (
+	"fmt"
+
+	// Built-in toolsets
+	_ "github.com/example/project/pkg/apples"
+	_ "github.com/example/project/pkg/widgets"
+)
+
+var _ = fmt.Sprint
+`,
+		},
+		{
+			name: "no imports",
+			input: `package mcp
+`,
+			expected: `package mcp
+
+import _ "github.com/example/project/pkg/widgets"
+`,
+		},
+		{
+			name: "already registered",
+			input: `package mcp
+
+import (
+	_ "github.com/example/project/pkg/widgets"
+)
+`,
+			expected: `package mcp
+
+import (
+	_ "github.com/example/project/pkg/widgets"
+)
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modulesPath := filepath.Join(t.TempDir(), "modules.go")
+			require.NoError(t, os.WriteFile(modulesPath, []byte(tt.input), 0o644))
+
+			require.NoError(t, RegisterInModulesFile(modulesPath, "github.com/example/project/pkg/widgets"))
+
+			content, err := os.ReadFile(modulesPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(content))
+		})
+	}
+}
+
+func TestRegisterInModulesFileInvalidSource(t *testing.T) {
+	modulesPath := filepath.Join(t.TempDir(), "modules.go")
+	require.NoError(t, os.WriteFile(modulesPath, []byte("package mcp\n\nimport (\n"), 0o644))
+
+	err := RegisterInModulesFile(modulesPath, "github.com/example/project/pkg/widgets")
+	assert.ErrorContains(t, err, "failed to parse modules.go")
+}
@@ -0,0 +1,226 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/friedrichwilken/mcp-toolgen/pkg/analyzer"
+)
+
+// singleFilename is the name of the file a toolset is generated into in single-file mode
+func (g *Generator) singleFilename() string {
+	return g.config.PackageName + ".go"
+}
+
+// renderSingleFile renders every toolset template except doc.go and combines the results into
+// one file with a single package clause and import declaration
+func (g *Generator) renderSingleFile(toolsetInfo *analyzer.ToolsetInfo) (string, error) {
+	var sources []string
+	for _, file := range codeFiles(toolsetInfo) {
+		if file.filename == "doc.go" {
+			continue
+		}
+		content, err := g.executeTemplate(toolsetInfo, file.template)
+		if err != nil {
+			return "", err
+		}
+		sources = append(sources, content)
+	}
+
+	combined, err := combineGoFiles(g.config.PackageName, sources)
+	if err != nil {
+		return "", err
+	}
+	return g.header + combined, nil
+}
+
+// combineGoFiles merges the Go sources of one package into a single file. Imports are
+// deduplicated; when sources import different packages under the same name, a standard library
+// package keeps the name and the others are imported under an alias with their references renamed.
+func combineGoFiles(packageName string, sources []string) (string, error) {
+	fset := token.NewFileSet()
+	files := make([]*ast.File, len(sources))
+	for i, src := range sources {
+		file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse source %d: %w", i+1, err)
+		}
+		files[i] = file
+	}
+
+	aliases, err := importAliases(files)
+	if err != nil {
+		return "", err
+	}
+
+	var importSpecs, bodies []string
+	seenSpecs := map[string]bool{}
+	for i, file := range files {
+		src := sources[i]
+		fileSet := fset
+
+		// Print the file again so the import declarations and references carry the aliases
+		if renameImports(file, aliases) {
+			var buf bytes.Buffer
+			if err := printer.Fprint(&buf, fset, file); err != nil {
+				return "", fmt.Errorf("failed to print source %d: %w", i+1, err)
+			}
+			src = buf.String()
+			fileSet = token.NewFileSet()
+			if file, err = parser.ParseFile(fileSet, "", src, parser.ParseComments); err != nil {
+				return "", fmt.Errorf("failed to parse source %d: %w", i+1, err)
+			}
+		}
+
+		for _, spec := range file.Imports {
+			importSpec := src[fileSet.Position(spec.Pos()).Offset:fileSet.Position(spec.End()).Offset]
+			if !seenSpecs[importSpec] {
+				seenSpecs[importSpec] = true
+				importSpecs = append(importSpecs, importSpec)
+			}
+		}
+
+		// The body is everything after the package clause and the import declarations
+		bodyStart := file.Name.End()
+		for _, decl := range file.Decls {
+			if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
+				bodyStart = genDecl.End()
+			}
+		}
+		bodies = append(bodies, strings.TrimSpace(src[fileSet.Position(bodyStart).Offset:]))
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "package %s\n", packageName)
+	if len(importSpecs) > 0 {
+		fmt.Fprintf(&out, "\nimport (\n\t%s\n)\n", strings.Join(importSpecs, "\n\t"))
+	}
+	for _, body := range bodies {
+		if body != "" {
+			fmt.Fprintf(&out, "\n%s\n", body)
+		}
+	}
+	return out.String(), nil
+}
+
+// importAliases returns the aliases by import path for the packages that are imported under a
+// name another package of files is imported under as well
+func importAliases(files []*ast.File) (map[string]string, error) {
+	pathsByName := map[string][]string{}
+	var names []string
+	for _, file := range files {
+		for _, spec := range file.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid import path %s: %w", spec.Path.Value, err)
+			}
+			name := importName(spec, importPath)
+			if name == "_" || name == "." {
+				continue
+			}
+			if _, ok := pathsByName[name]; !ok {
+				names = append(names, name)
+			}
+			if !slices.Contains(pathsByName[name], importPath) {
+				pathsByName[name] = append(pathsByName[name], importPath)
+			}
+		}
+	}
+
+	taken := map[string]bool{}
+	for _, name := range names {
+		taken[name] = true
+	}
+
+	aliases := map[string]string{}
+	for _, name := range names {
+		paths := pathsByName[name]
+		if len(paths) < 2 {
+			continue
+		}
+		// The first standard library package keeps the name, or else the first package imported
+		keep := slices.IndexFunc(paths, isStandardLibrary)
+		if keep < 0 {
+			keep = 0
+		}
+		for i, importPath := range paths {
+			if i == keep {
+				continue
+			}
+			alias := importAlias(name, importPath, taken)
+			taken[alias] = true
+			aliases[importPath] = alias
+		}
+	}
+	return aliases, nil
+}
+
+// isStandardLibrary reports whether importPath is a standard library package, whose first path
+// element has no dot unlike those of module paths
+func isStandardLibrary(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+// importName returns the name an import is referred to by: its alias, or else the last element
+// of the import path without a version suffix or "go-" prefix and "-go" suffix
+func importName(spec *ast.ImportSpec, importPath string) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	name := path.Base(importPath)
+	name, _, _ = strings.Cut(name, ".")
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "go-"), "-go")
+	return strings.ReplaceAll(name, "-", "")
+}
+
+// importAlias returns an alias for importPath that is not taken yet, preferring the name prefixed
+// with the parent directory of the import path, like apierrors for k8s.io/apimachinery/pkg/api/errors
+func importAlias(name, importPath string, taken map[string]bool) string {
+	alias := strings.ReplaceAll(path.Base(path.Dir(importPath)), ".", "") + name
+	for i := 2; taken[alias]; i++ {
+		alias = fmt.Sprintf("%s%d", name, i)
+	}
+	return alias
+}
+
+// renameImports imports the packages of file that have an alias under it and renames the
+// references to them. It reports whether anything was renamed.
+func renameImports(file *ast.File, aliases map[string]string) bool {
+	renames := map[string]string{}
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if alias, ok := aliases[importPath]; ok {
+			renames[importName(spec, importPath)] = alias
+			spec.Name = ast.NewIdent(alias)
+		}
+	}
+	if len(renames) == 0 {
+		return false
+	}
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		if selector, ok := node.(*ast.SelectorExpr); ok {
+			// Generated code does not shadow its imports, so every qualifier named like a
+			// renamed package refers to it
+			if ident, ok := selector.X.(*ast.Ident); ok {
+				if alias, ok := renames[ident.Name]; ok {
+					ident.Name = alias
+				}
+			}
+		}
+		return true
+	})
+	return true
+}
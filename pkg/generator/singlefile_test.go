@@ -0,0 +1,106 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCombineGoFiles(t *testing.T) {
+	combined, err := combineGoFiles("widgets", []string{
+		`package widgets
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+func notFound(err error) bool { return errors.IsNotFound(err) }
+
+func describe() string { return fmt.Sprint("widget") }
+`,
+		`package widgets
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errMissing is returned for missing arguments
+var errMissing = errors.New(fmt.Sprint("missing"))
+`,
+		`package widgets
+
+const kind = "Widget"
+`,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, strings.Count(combined, "package widgets"))
+	assert.Equal(t, 1, strings.Count(combined, `"fmt"`), "imports should be deduplicated")
+	assert.Contains(t, combined, `apierrors "k8s.io/apimachinery/pkg/api/errors"`)
+	assert.Contains(t, combined, "\t\"errors\"\n", "the standard library package should keep its name")
+	assert.Contains(t, combined, "return apierrors.IsNotFound(err)")
+	assert.Contains(t, combined, "var errMissing = errors.New(")
+	assert.Contains(t, combined, "// errMissing is returned for missing arguments")
+	assert.Contains(t, combined, `const kind = "Widget"`)
+}
+
+func TestGenerateSingleFile(t *testing.T) {
+	outputDir := t.TempDir()
+
+	gen, err := NewGenerator(&GeneratorConfig{
+		OutputDir:       outputDir,
+		PackageName:     "widgets",
+		ModulePath:      "github.com/test/module",
+		IncludeComments: true,
+		BuildTag:        "mytools",
+		SingleFile:      true,
+	})
+	require.NoError(t, err)
+
+	toolsetInfo := loadDiffTestToolset(t, "../../test/fixtures/simple-crd.yaml")
+	assert.Equal(t, []string{"widgets.go"}, gen.OutputFilenames(toolsetInfo))
+	require.NoError(t, gen.GenerateToolset(toolsetInfo))
+
+	entries, err := os.ReadDir(outputDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "widgets.go", entries[0].Name())
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "widgets.go"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), "//go:build mytools\n"))
+	assert.Equal(t, 1, strings.Count(string(content), "\npackage widgets\n"))
+	assert.Contains(t, string(content), "type WidgetToolset struct {")
+	assert.Contains(t, string(content), "AddToScheme = SchemeBuilder.AddToScheme")
+	assert.NotContains(t, string(content), "Package widgets", "doc.go should be left out")
+}
+
+func TestGenerateSingleFileWithPrompts(t *testing.T) {
+	outputDir := t.TempDir()
+
+	gen, err := NewGenerator(&GeneratorConfig{
+		OutputDir:   outputDir,
+		PackageName: "widgets",
+		ModulePath:  "github.com/test/module",
+		SingleFile:  true,
+	})
+	require.NoError(t, err)
+
+	toolsetInfo := loadDiffTestToolset(t, "../../test/fixtures/simple-crd.yaml")
+	toolsetInfo.Config.GeneratePrompts = true
+	toolsetInfo.Config.GenerateTests = true
+	assert.Equal(t, []string{"widgets.go", "handlers_test.go"}, gen.OutputFilenames(toolsetInfo))
+	require.NoError(t, gen.GenerateToolset(toolsetInfo))
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "widgets.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "func (t *WidgetToolset) RegisterPrompts(", "prompts.go should be combined into the file")
+	assert.FileExists(t, filepath.Join(outputDir, "handlers_test.go"))
+}
@@ -0,0 +1,66 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// verifyCompiles type-checks the written files of the toolset, so template regressions fail the
+// generation instead of the build of the module. Imports are read from the export data of the module
+// the output directory belongs to. Packages it cannot provide are logged, and their uses are left
+// unchecked rather than failing the verification.
+func (g *Generator) verifyCompiles(files []renderedFile) error {
+	fset := token.NewFileSet()
+	var astFiles []*ast.File
+	for _, file := range files {
+		path := filepath.Join(g.config.OutputDir, file.filename)
+		astFile, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return fmt.Errorf("generated code does not compile: %w", err)
+		}
+		astFiles = append(astFiles, astFile)
+	}
+
+	var unresolved []string
+	lookup := func(importPath string) (io.ReadCloser, error) {
+		cmd := exec.Command("go", "list", "-export", "-f", "{{.Export}}", importPath)
+		cmd.Dir = g.config.OutputDir
+		output, err := cmd.Output()
+		if err != nil || strings.TrimSpace(string(output)) == "" {
+			unresolved = append(unresolved, importPath)
+			return nil, fmt.Errorf("go list %s: %w", importPath, err)
+		}
+		return os.Open(strings.TrimSpace(string(output)))
+	}
+
+	var errs []string
+	config := types.Config{
+		Importer: importer.ForCompiler(fset, "gc", lookup),
+		Error: func(err error) {
+			if !strings.Contains(err.Error(), "could not import") {
+				errs = append(errs, err.Error())
+			}
+		},
+	}
+	_, _ = config.Check(g.config.PackageName, fset, astFiles, nil)
+
+	for _, importPath := range slices.Compact(slices.Sorted(slices.Values(unresolved))) {
+		g.logger.Warn("could not import package, so its uses are not verified", "package", importPath, "dir", g.config.OutputDir)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("generated code does not compile:\n%s", strings.Join(errs, "\n"))
+	}
+
+	g.logger.Info("verified generated code", "dir", g.config.OutputDir)
+	return nil
+}
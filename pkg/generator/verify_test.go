@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// moduleTempDir returns a temporary directory inside the mcp-toolgen module, so the imports of
+// generated code resolve against the module's dependencies. The testdata directory keeps it
+// out of ./... patterns.
+func moduleTempDir(t *testing.T) string {
+	t.Helper()
+	require.NoError(t, os.MkdirAll("testdata", 0o755))
+	dir, err := os.MkdirTemp("testdata", "verify-")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+		_ = os.Remove("testdata")
+	})
+	abs, err := filepath.Abs(dir)
+	require.NoError(t, err)
+	return abs
+}
+
+func TestGenerateToolsetVerifyCompiles(t *testing.T) {
+	var logs bytes.Buffer
+	gen, err := NewGenerator(&GeneratorConfig{
+		OutputDir:       moduleTempDir(t),
+		PackageName:     "widgets",
+		ModulePath:      "github.com/test/module",
+		IncludeComments: true,
+		Logger:          slog.New(slog.NewTextHandler(&logs, nil)),
+		VerifyCompiles:  true,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, gen.GenerateToolset(loadDiffTestToolset(t, "../../test/fixtures/simple-crd.yaml")))
+	assert.Contains(t, logs.String(), "verified generated code")
+	assert.Contains(t, logs.String(), "could not import package", "the MCP server is not a dependency of this module")
+}
+
+func TestVerifyCompilesReportsErrors(t *testing.T) {
+	outputDir := moduleTempDir(t)
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "broken.go"),
+		[]byte("package widgets\n\nfunc broken() string {\n\treturn 42\n}\n"), 0o644))
+
+	gen, err := NewGenerator(&GeneratorConfig{OutputDir: outputDir, PackageName: "widgets"})
+	require.NoError(t, err)
+
+	err = gen.verifyCompiles([]renderedFile{{filename: "broken.go"}})
+	assert.ErrorContains(t, err, "generated code does not compile")
+	assert.ErrorContains(t, err, "cannot use 42")
+}
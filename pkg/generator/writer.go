@@ -1,11 +1,15 @@
 package generator
 
 import (
+	"errors"
 	"fmt"
-	"go/format"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"golang.org/x/tools/imports"
 )
 
 // FileWriter handles writing generated code to files
@@ -13,22 +17,35 @@ type FileWriter struct {
 	outputDir      string
 	overwriteFiles bool
 	formatCode     bool
+	alwaysWrite    bool         // Also write files whose content is unchanged, updating their modification time
+	logger         *slog.Logger // Receives a warning per file that fails to format
 }
 
-// NewFileWriter creates a new FileWriter
-func NewFileWriter(outputDir string, overwriteFiles, formatCode bool) *FileWriter {
+// NewFileWriter creates a new FileWriter; a nil logger discards its warnings
+func NewFileWriter(outputDir string, overwriteFiles, formatCode bool, logger *slog.Logger) *FileWriter {
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
 	return &FileWriter{
 		outputDir:      outputDir,
 		overwriteFiles: overwriteFiles,
 		formatCode:     formatCode,
+		logger:         logger,
 	}
 }
 
-// WriteFile writes content to a file with optional Go formatting
+// WriteFile writes content to a file with optional Go formatting. An existing file with the same
+// content is left untouched, so regenerating does not churn unchanged files, unless always-write is set.
 func (w *FileWriter) WriteFile(filename, content string) error {
+	_, err := w.writeFile(filename, content)
+	return err
+}
+
+// writeFile implements WriteFile and reports whether the file was written
+func (w *FileWriter) writeFile(filename, content string) (bool, error) {
 	// Ensure output directory exists
 	if err := os.MkdirAll(w.outputDir, 0o755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+		return false, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
 	filePath := filepath.Join(w.outputDir, filename)
@@ -36,28 +53,73 @@ func (w *FileWriter) WriteFile(filename, content string) error {
 	// Check if file exists and we're not overwriting
 	if !w.overwriteFiles {
 		if _, err := os.Stat(filePath); err == nil {
-			return fmt.Errorf("file %s already exists and overwrite is disabled", filePath)
+			return false, fmt.Errorf("file %s already exists and overwrite is disabled", filePath)
 		}
 	}
 
-	// Format Go code if requested and filename ends with .go
-	finalContent := content
-	if w.formatCode && strings.HasSuffix(filename, ".go") {
-		formatted, err := format.Source([]byte(content))
-		if err != nil {
-			// If formatting fails, write the original content and log a warning
-			fmt.Printf("Warning: failed to format %s: %v\n", filename, err)
-		} else {
-			finalContent = string(formatted)
+	formatted := w.formatContent(filename, content)
+	if !w.alwaysWrite {
+		if existing, err := os.ReadFile(filePath); err == nil && string(existing) == formatted {
+			return false, nil
 		}
 	}
 
 	// Write file
-	if err := os.WriteFile(filePath, []byte(finalContent), 0o644); err != nil {
-		return fmt.Errorf("failed to write file %s: %w", filePath, err)
+	if err := os.WriteFile(filePath, []byte(formatted), 0o644); err != nil {
+		return false, fmt.Errorf("failed to write file %s: %w", filePath, err)
 	}
 
-	return nil
+	return true, nil
+}
+
+// WriteFileMerging works like WriteFile, but keeps the content of the custom regions of an
+// existing file (see mergeCustomRegions) instead of replacing it with the generated content.
+func (w *FileWriter) WriteFileMerging(filename, content string) error {
+	_, err := w.writeFileMerging(filename, content)
+	return err
+}
+
+// writeFileMerging implements WriteFileMerging and reports whether the file was written
+func (w *FileWriter) writeFileMerging(filename, content string) (bool, error) {
+	merged, err := w.mergeExisting(filename, content)
+	if err != nil {
+		return false, err
+	}
+	return w.writeFile(filename, merged)
+}
+
+// mergeExisting merges the custom regions of the existing file into content. Content is returned
+// unchanged if the file does not exist yet.
+func (w *FileWriter) mergeExisting(filename, content string) (string, error) {
+	filePath := filepath.Join(w.outputDir, filename)
+	existing, err := os.ReadFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return content, nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+
+	merged, err := mergeCustomRegions(string(existing), content)
+	if err != nil {
+		return "", fmt.Errorf("failed to merge custom regions of %s: %w", filePath, err)
+	}
+	return merged, nil
+}
+
+// formatContent formats Go code if requested and filename ends with .go.
+// Formatting also manages imports: unused imports are removed and missing ones are added.
+func (w *FileWriter) formatContent(filename, content string) string {
+	if !w.formatCode || !strings.HasSuffix(filename, ".go") {
+		return content
+	}
+
+	formatted, err := imports.Process(filepath.Join(w.outputDir, filename), []byte(content), nil)
+	if err != nil {
+		// If formatting fails, keep the original content and log a warning
+		w.logger.Warn("failed to format file", "filename", filename, "error", err)
+		return content
+	}
+	return string(formatted)
 }
 
 // WriteFiles writes multiple files
@@ -141,3 +203,8 @@ func (w *FileWriter) SetOverwriteFiles(overwrite bool) {
 func (w *FileWriter) SetFormatCode(formatCode bool) {
 	w.formatCode = formatCode
 }
+
+// SetAlwaysWrite sets whether to write files whose content is unchanged
+func (w *FileWriter) SetAlwaysWrite(alwaysWrite bool) {
+	w.alwaysWrite = alwaysWrite
+}
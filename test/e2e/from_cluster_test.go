@@ -0,0 +1,65 @@
+package e2e
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/friedrichwilken/mcp-toolgen/test/utils"
+)
+
+// TestGenerateFromCluster verifies that --from-cluster generates toolsets for the CRDs of the
+// selected group installed in a real API server and leaves out those of other groups.
+func TestGenerateFromCluster(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	env := utils.NewEnvtestEnvironment(t)
+	env.ApplyCRDFile(t, getTestCRDPath(t))
+	env.ApplyCRDFile(t, filepath.Join("..", "fixtures", "simple-crd.yaml"))
+	kubeconfigPath := writeKubeconfig(t, env)
+
+	binaryPath := buildToolgen(t)
+	outputBase := utils.TempDir(t)
+
+	cmd := exec.Command(binaryPath,
+		"--from-cluster",
+		"--kubeconfig", kubeconfigPath,
+		"--group", "testing.mcp-toolgen.io",
+		"--output-base", outputBase,
+		"--module-path", "github.com/test/module",
+		"--log-level", "info",
+	)
+	output, err := cmd.CombinedOutput()
+	t.Logf("mcp-toolgen output:\n%s", output)
+	require.NoError(t, err, "from-cluster generation failed")
+	assert.Contains(t, string(output), "generated=1 skipped=0 failed=0")
+
+	toolsetDir := filepath.Join(outputBase, testToolsetName)
+	for _, file := range []string{"doc.go", "types.go", "register.go", "client.go", "schema.go", "handlers.go", "options.go", "errors.go", "toolset.go"} {
+		assert.FileExists(t, filepath.Join(toolsetDir, file))
+	}
+	assert.NoDirExists(t, filepath.Join(outputBase, "widgets"), "CRDs of other groups should be left out")
+}
+
+// buildToolgen builds the mcp-toolgen binary and returns its path.
+func buildToolgen(t *testing.T) string {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), buildTimeout)
+	defer cancel()
+
+	binaryPath := filepath.Join(utils.TempDir(t), "mcp-toolgen")
+	cmd := exec.CommandContext(ctx, "go", "build", "-o", binaryPath, "./cmd/mcp-toolgen")
+	cmd.Dir = filepath.Join("..", "..")
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Logf("Build output:\n%s", string(output))
+		require.NoError(t, err, "Failed to build mcp-toolgen")
+	}
+	return binaryPath
+}
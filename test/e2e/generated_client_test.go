@@ -0,0 +1,280 @@
+package e2e
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/friedrichwilken/mcp-toolgen/pkg/analyzer"
+	"github.com/friedrichwilken/mcp-toolgen/test/utils"
+)
+
+// TestGeneratedClientWithEnvtest verifies that the generated client registers its types with a
+// scheme and can create and get a resource against a real API server.
+func TestGeneratedClientWithEnvtest(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	env := utils.NewEnvtestEnvironment(t)
+	testCRDPath := getTestCRDPath(t)
+	env.ApplyCRDFile(t, testCRDPath)
+
+	toolsetDir := utils.TempDir(t)
+	generateToolset(t, testCRDPath, toolsetDir)
+
+	kubeconfigPath := writeKubeconfig(t, env)
+
+	utils.RunGeneratedPackageTestsWithEnv(t, toolsetDir, []string{"types.go", "register.go", "client.go"}, `package testwidgets
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestCreateAndGet(t *testing.T) {
+	cfg, err := clientcmd.BuildConfigFromFlags("", os.Getenv("KUBECONFIG"))
+	if err != nil {
+		t.Fatalf("failed to load kubeconfig: %v", err)
+	}
+
+	widgets, err := NewTestWidgetClientForConfig(cfg, "default")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ctx := context.Background()
+	widget := &TestWidget{ObjectMeta: metav1.ObjectMeta{Name: "envtest-widget"}}
+
+	// The CRD may not be served immediately after it was applied
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		err = widgets.Create(ctx, widget)
+		if err == nil || !meta.IsNoMatchError(err) || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	got, err := widgets.Get(ctx, "envtest-widget")
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if got.Name != "envtest-widget" || got.Namespace != "default" {
+		t.Fatalf("unexpected object %s/%s", got.Namespace, got.Name)
+	}
+}
+`, []string{"KUBECONFIG=" + kubeconfigPath})
+}
+
+// TestGeneratedToolsetFromKubeconfigWithEnvtest verifies that NewToolsetFromKubeconfig builds a
+// toolset whose client reaches the cluster of the kubeconfig it was given, and whose tools call
+// that cluster through the client instead of the MCP server.
+func TestGeneratedToolsetFromKubeconfigWithEnvtest(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	env := utils.NewEnvtestEnvironment(t)
+	testCRDPath := getTestCRDPath(t)
+	env.ApplyCRDFile(t, testCRDPath)
+
+	toolsetDir := utils.TempDir(t)
+	generateToolset(t, testCRDPath, toolsetDir)
+
+	// The rest of toolset.go and handlers.go needs the MCP server packages, which are no
+	// dependency of this module
+	handlerDir, files := utils.WriteHandlerPackage(t, toolsetDir,
+		"handleTestWidgetGet", "setTypeMeta", "projectFields")
+	files = append(files, utils.WriteGeneratedDecls(t, toolsetDir, handlerDir, "toolset.go",
+		"TestWidgetToolset", "NewToolsetFromKubeconfig", "Client"))
+
+	kubeconfigPath := writeKubeconfig(t, env)
+
+	utils.RunGeneratedPackageTestsWithEnv(t, handlerDir, files, `package testwidgets
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestToolsetFromKubeconfig(t *testing.T) {
+	toolset, err := NewToolsetFromKubeconfig(os.Getenv("KUBECONFIG"))
+	if err != nil {
+		t.Fatalf("failed to create toolset: %v", err)
+	}
+
+	widgets := toolset.Client()
+	if widgets == nil || widgets.GetNamespace() != "default" {
+		t.Fatalf("expected a client for the default namespace, got %+v", widgets)
+	}
+
+	ctx := context.Background()
+	widget := &TestWidget{ObjectMeta: metav1.ObjectMeta{Name: "kubeconfig-widget"}}
+
+	// The CRD may not be served immediately after it was applied
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		err = widgets.Create(ctx, widget)
+		if err == nil || !meta.IsNoMatchError(err) || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	// GetTools passes the client of the toolset to the handlers the same way. The stand-in of
+	// the MCP server has no hooks set, so a call to the server would fail.
+	result, err := withClient(handleTestWidgetGet, widgets)(ToolHandlerParams{
+		Context:   ctx,
+		Arguments: map[string]any{"name": "kubeconfig-widget"},
+	})
+	if err != nil || result.Error != nil {
+		t.Fatalf("get tool failed: %v %v", err, result.Error)
+	}
+	if !strings.Contains(result.Content, "name: kubeconfig-widget") || !strings.Contains(result.Content, "namespace: default") {
+		t.Fatalf("expected the widget of the kubeconfig cluster, got:\n%s", result.Content)
+	}
+}
+
+func TestToolsetFromMissingKubeconfig(t *testing.T) {
+	if _, err := NewToolsetFromKubeconfig(os.Getenv("KUBECONFIG") + ".missing"); err == nil {
+		t.Fatal("expected an error for a missing kubeconfig")
+	}
+}
+`, []string{"KUBECONFIG=" + kubeconfigPath})
+}
+
+// TestGeneratedCachedClientWithEnvtest verifies that the client generated with --with-cache reads
+// objects created through the API server from its informer cache once the cache has synced.
+func TestGeneratedCachedClientWithEnvtest(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	env := utils.NewEnvtestEnvironment(t)
+	testCRDPath := getTestCRDPath(t)
+	env.ApplyCRDFile(t, testCRDPath)
+
+	toolsetDir := utils.TempDir(t)
+	generateToolsetWithConfig(t, testCRDPath, toolsetDir, func(config *analyzer.GenerationConfig) {
+		config.WithCache = true
+	})
+
+	kubeconfigPath := writeKubeconfig(t, env)
+
+	utils.RunGeneratedPackageTestsWithEnv(t, toolsetDir, []string{"types.go", "register.go", "client.go"}, `package testwidgets
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestCachedReads(t *testing.T) {
+	cfg, err := clientcmd.BuildConfigFromFlags("", os.Getenv("KUBECONFIG"))
+	if err != nil {
+		t.Fatalf("failed to load kubeconfig: %v", err)
+	}
+
+	widgets, err := NewTestWidgetCachedClientForConfig(cfg, "default")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The CRD may not be served immediately after it was applied
+	deadline := time.Now().Add(30 * time.Second)
+	widget := &TestWidget{ObjectMeta: metav1.ObjectMeta{Name: "cached-widget"}}
+	for {
+		err = widgets.Create(ctx, widget)
+		if err == nil || !meta.IsNoMatchError(err) || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	syncCtx, syncCancel := context.WithTimeout(ctx, 30*time.Second)
+	defer syncCancel()
+	if err := widgets.Start(syncCtx); err != nil {
+		t.Fatalf("failed to start cache: %v", err)
+	}
+
+	got, err := widgets.Get(ctx, "cached-widget")
+	if err != nil {
+		t.Fatalf("cached get failed: %v", err)
+	}
+	if got.Name != "cached-widget" || got.Namespace != "default" {
+		t.Fatalf("unexpected object %s/%s", got.Namespace, got.Name)
+	}
+
+	// Objects created after the sync reach the cache through its watch
+	if err := widgets.Create(ctx, &TestWidget{ObjectMeta: metav1.ObjectMeta{Name: "later-widget"}}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	deadline = time.Now().Add(10 * time.Second)
+	for {
+		list, err := widgets.List(ctx)
+		if err != nil {
+			t.Fatalf("cached list failed: %v", err)
+		}
+		if len(list.Items) == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 2 cached widgets, got %d", len(list.Items))
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+`, []string{"KUBECONFIG=" + kubeconfigPath})
+}
+
+// writeKubeconfig writes a kubeconfig for the envtest cluster and returns its path.
+func writeKubeconfig(t *testing.T, env *utils.EnvtestEnvironment) string {
+	t.Helper()
+
+	cfg := env.GetConfig()
+	kubeconfig := clientcmdapi.NewConfig()
+	kubeconfig.Clusters["envtest"] = &clientcmdapi.Cluster{
+		Server:                   cfg.Host,
+		CertificateAuthorityData: cfg.CAData,
+	}
+	kubeconfig.AuthInfos["envtest"] = &clientcmdapi.AuthInfo{
+		ClientCertificateData: cfg.CertData,
+		ClientKeyData:         cfg.KeyData,
+	}
+	kubeconfig.Contexts["envtest"] = &clientcmdapi.Context{
+		Cluster:  "envtest",
+		AuthInfo: "envtest",
+	}
+	kubeconfig.CurrentContext = "envtest"
+
+	kubeconfigPath := filepath.Join(utils.TempDir(t), "kubeconfig")
+	require.NoError(t, clientcmd.WriteToFile(*kubeconfig, kubeconfigPath), "Failed to write kubeconfig")
+	return kubeconfigPath
+}
@@ -0,0 +1,128 @@
+package e2e
+
+import (
+	"testing"
+
+	"github.com/friedrichwilken/mcp-toolgen/test/utils"
+)
+
+// TestGeneratedHandlersWithEnvtest verifies that the create and update handlers send resources
+// with their apiVersion and kind, so a real API server accepts them from a client whose scheme
+// lacks the TestWidget types, as the one of the MCP server does. Arguments without apiVersion
+// and kind used to fail there with "Object 'Kind' is missing".
+func TestGeneratedHandlersWithEnvtest(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	env := utils.NewEnvtestEnvironment(t)
+	testCRDPath := getTestCRDPath(t)
+	env.ApplyCRDFile(t, testCRDPath)
+
+	toolsetDir := utils.TempDir(t)
+	generateToolset(t, testCRDPath, toolsetDir)
+
+	handlerDir, files := utils.WriteHandlerPackage(t, toolsetDir,
+		"handleTestWidgetCreate", "handleTestWidgetUpdate", "TestWidgetUpdateRetries", "resourceFromArguments",
+		"missingRequiredFields", "testWidgetRequiredFields")
+
+	kubeconfigPath := writeKubeconfig(t, env)
+
+	utils.RunGeneratedPackageTestsWithEnv(t, handlerDir, files, `package testwidgets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// params serves the calls of the handlers from c as the MCP server does, which applies the YAML
+// of a create or update as an unstructured object
+func params(ctx context.Context, c client.Client, args map[string]any) ToolHandlerParams {
+	return ToolHandlerParams{
+		Context:   ctx,
+		Arguments: args,
+		Get: func(ctx context.Context, gvk *schema.GroupVersionKind, namespace, name string) (*unstructured.Unstructured, error) {
+			obj := &unstructured.Unstructured{}
+			obj.SetGroupVersionKind(*gvk)
+			err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, obj)
+			return obj, err
+		},
+		CreateOrUpdate: func(ctx context.Context, resource string) ([]*unstructured.Unstructured, error) {
+			obj := &unstructured.Unstructured{}
+			if err := yaml.Unmarshal([]byte(resource), &obj.Object); err != nil {
+				return nil, err
+			}
+			if err := c.Patch(ctx, obj, client.Apply, client.FieldOwner("mcp-toolgen"), client.ForceOwnership); err != nil {
+				return nil, fmt.Errorf("failed to apply %q: %w", resource, err)
+			}
+			return []*unstructured.Unstructured{obj}, nil
+		},
+	}
+}
+
+func TestCreateAndUpdateWithoutRegisteredKind(t *testing.T) {
+	cfg, err := clientcmd.BuildConfigFromFlags("", os.Getenv("KUBECONFIG"))
+	if err != nil {
+		t.Fatalf("failed to load kubeconfig: %v", err)
+	}
+
+	// The scheme knows no TestWidget types, so only the YAML tells the client what it applies
+	c, err := client.New(cfg, client.Options{Scheme: runtime.NewScheme()})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ctx := context.Background()
+	widgets := &unstructured.UnstructuredList{}
+	widgets.SetAPIVersion("testing.mcp-toolgen.io/v1")
+	widgets.SetKind("TestWidgetList")
+
+	// The CRD may not be served immediately after it was applied
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		err = c.List(ctx, widgets)
+		if err == nil || !meta.IsNoMatchError(err) || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+
+	metadata := map[string]any{"name": "handler-widget", "namespace": "default"}
+	result, err := handleTestWidgetCreate(params(ctx, c, map[string]any{
+		"args": map[string]any{"metadata": metadata, "spec": map[string]any{"name": "first"}},
+	}))
+	if err != nil || result.Error != nil {
+		t.Fatalf("create failed: %v, %v", err, result.Error)
+	}
+
+	result, err = handleTestWidgetUpdate(params(ctx, c, map[string]any{
+		"manifest": "metadata:\n  name: handler-widget\n  namespace: default\nspec:\n  name: second\n",
+	}))
+	if err != nil || result.Error != nil {
+		t.Fatalf("update failed: %v, %v", err, result.Error)
+	}
+
+	got := &unstructured.Unstructured{}
+	got.SetAPIVersion("testing.mcp-toolgen.io/v1")
+	got.SetKind("TestWidget")
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "handler-widget"}, got); err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if name, _, _ := unstructured.NestedString(got.Object, "spec", "name"); name != "second" {
+		t.Fatalf("expected the updated spec.name second, got %q", name)
+	}
+}
+`, []string{"KUBECONFIG=" + kubeconfigPath})
+}
@@ -26,6 +26,9 @@ const (
 	// Timeout for various operations
 	buildTimeout       = 2 * time.Minute
 	serverStartTimeout = 10 * time.Second
+
+	// testToolsetName is the name of the toolset generated from the TestWidget CRD
+	testToolsetName = "testwidgets"
 )
 
 // TestToolgenWithEK8SMS is the main E2E test that verifies mcp-toolgen
@@ -127,6 +130,13 @@ func findEK8SMSDirectory(t *testing.T) string {
 // generateToolset generates the toolset code from the CRD
 func generateToolset(t *testing.T, crdPath, outputDir string) {
 	t.Helper()
+	generateToolsetWithConfig(t, crdPath, outputDir, nil)
+}
+
+// generateToolsetWithConfig works like generateToolset and lets configure adjust the generation
+// config, unless it is nil
+func generateToolsetWithConfig(t *testing.T, crdPath, outputDir string, configure func(*analyzer.GenerationConfig)) {
+	t.Helper()
 
 	// Parse CRD
 	crdAnalyzer := analyzer.NewCRDAnalyzer()
@@ -139,6 +149,9 @@ func generateToolset(t *testing.T, crdPath, outputDir string) {
 	config.ModulePath = "github.com/friedrichwilken/extendable-kubernetes-mcp-server"
 	config.OutputDir = outputDir
 	config.SelectedOperations = []string{"create", "get", "list", "update", "delete"}
+	if configure != nil {
+		configure(config)
+	}
 
 	// Create toolset info
 	toolsetInfo, err := analyzer.NewToolsetInfo(crdInfo, config)
@@ -169,8 +182,11 @@ func verifyGeneratedFiles(t *testing.T, toolsetDir string) {
 	expectedFiles := []string{
 		"toolset.go",
 		"types.go",
+		"register.go",
 		"client.go",
 		"handlers.go",
+		"options.go",
+		"errors.go",
 		"schema.go",
 		"doc.go",
 	}
@@ -249,9 +265,10 @@ func verifyToolsetRegistered(t *testing.T, binaryPath string) {
 	helpText := string(output)
 	t.Logf("Help output:\n%s", helpText)
 
-	// Note: testwidgets toolset might not show up in help if it doesn't
-	// implement the toolset interface correctly, but at least it should compile
+	// The --toolsets flag help lists every toolset in the registry, so the generated toolset
+	// shows up once its init function has registered it
 	assert.Contains(t, helpText, "toolsets", "Help should mention toolsets")
+	assert.Contains(t, helpText, testToolsetName, "Help should list the generated toolset")
 }
 
 // serverWithPipes holds the server command and its pipes
@@ -262,11 +279,12 @@ type serverWithPipes struct {
 	stderr io.ReadCloser
 }
 
-// startEK8SMSServer starts the ek8sms server process with stdio pipes
+// startEK8SMSServer starts the ek8sms server process with stdio pipes. Registered toolsets are
+// only served when enabled, so the generated toolset is enabled next to the default ones.
 func startEK8SMSServer(t *testing.T, binaryPath, kubeconfigPath string) *serverWithPipes {
 	t.Helper()
 
-	cmd := exec.Command(binaryPath)
+	cmd := exec.Command(binaryPath, "--toolsets", "core,config,"+testToolsetName)
 	cmd.Env = append(os.Environ(), fmt.Sprintf("KUBECONFIG=%s", kubeconfigPath))
 
 	// Capture stdin, stdout, stderr for MCP protocol communication
@@ -467,47 +485,24 @@ func testMCPCRUDOperations(t *testing.T, server *serverWithPipes) {
 		ID:      2,
 		Method:  "tools/list",
 	})
-	if err != nil {
-		t.Logf("List tools failed (expected - server may not implement tools/list): %v", err)
-	} else {
-		t.Logf("Tools list response: %s", string(listResp.Result))
+	require.NoError(t, err, "tools/list request failed")
+	t.Logf("Tools list response: %s", string(listResp.Result))
 
-		// Verify testwidgets tools are present
-		var toolsList struct {
-			Tools []struct {
-				Name string `json:"name"`
-			} `json:"tools"`
-		}
-		if err := json.Unmarshal(listResp.Result, &toolsList); err == nil {
-			toolNames := make([]string, len(toolsList.Tools))
-			for i, tool := range toolsList.Tools {
-				toolNames[i] = tool.Name
-			}
-			t.Logf("Available tools: %v", toolNames)
-
-			// Check for testwidgets tools
-			expectedTools := []string{
-				"testwidgets_create",
-				"testwidgets_get",
-				"testwidgets_list",
-				"testwidgets_update",
-				"testwidgets_delete",
-			}
-			for _, expectedTool := range expectedTools {
-				found := false
-				for _, toolName := range toolNames {
-					if toolName == expectedTool {
-						found = true
-						break
-					}
-				}
-				if found {
-					t.Logf("✓ Found tool: %s", expectedTool)
-				} else {
-					t.Logf("✗ Missing tool: %s", expectedTool)
-				}
-			}
-		}
+	var toolsList struct {
+		Tools []struct {
+			Name string `json:"name"`
+		} `json:"tools"`
+	}
+	require.NoError(t, json.Unmarshal(listResp.Result, &toolsList), "Failed to parse tools/list result")
+
+	toolNames := make([]string, len(toolsList.Tools))
+	for i, tool := range toolsList.Tools {
+		toolNames[i] = tool.Name
+	}
+	t.Logf("Available tools: %v", toolNames)
+
+	for _, operation := range []string{"create", "get", "list", "update", "delete"} {
+		assert.Contains(t, toolNames, testToolsetName+"_"+operation, "tools/list should include the generated tool")
 	}
 
 	// Test 3: Create a TestWidget
@@ -1,5 +0,0 @@
-// Package clusterwidgets provides MCP tools for managing GlobalConfig custom resources.
-//
-// Generated by: mcp-toolgen
-// Source CRD: globalconfigs.config.example.com
-package clusterwidgets
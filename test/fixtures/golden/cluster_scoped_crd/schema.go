@@ -1,4 +0,0 @@
-package clusterwidgets
-
-// Schema definitions for GlobalConfig
-// TODO: Implement proper JSON schemas
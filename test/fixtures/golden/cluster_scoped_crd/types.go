@@ -1,56 +0,0 @@
-package clusterwidgets
-
-import (
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
-)
-
-// GlobalConfig represents the GlobalConfig custom resource
-type GlobalConfig struct {
-	metav1.TypeMeta   `json:",inline"`
-	metav1.ObjectMeta `json:"metadata,omitempty"`
-	Spec   GlobalConfigSpec   `json:"spec,omitempty"`
-	Status GlobalConfigStatus `json:"status,omitempty"`
-}
-
-// GlobalConfigSpec defines the desired state of GlobalConfig
-type GlobalConfigSpec struct {
-	// Add spec fields here based on CRD schema
-}
-
-// GlobalConfigStatus defines the observed state of GlobalConfig
-type GlobalConfigStatus struct {
-	// Add status fields here based on CRD schema
-}
-
-// GlobalConfigList contains a list of GlobalConfig
-type GlobalConfigList struct {
-	metav1.TypeMeta `json:",inline"`
-	metav1.ListMeta `json:"metadata,omitempty"`
-	Items           []GlobalConfig `json:"items"`
-}
-
-// DeepCopyInto is an autogenerated deepcopy function
-func (in *GlobalConfig) DeepCopyInto(out *GlobalConfig) {
-	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-}
-
-// DeepCopy is an autogenerated deepcopy function
-func (in *GlobalConfig) DeepCopy() *GlobalConfig {
-	if in == nil {
-		return nil
-	}
-	out := new(GlobalConfig)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyObject is an autogenerated deepcopy function
-func (in *GlobalConfig) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
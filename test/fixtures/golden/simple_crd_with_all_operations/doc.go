@@ -1,5 +0,0 @@
-// Package widgets provides MCP tools for managing Widget custom resources.
-//
-// Generated by: mcp-toolgen
-// Source CRD: widgets.example.com
-package widgets
@@ -1,10 +0,0 @@
-package widgets
-
-import (
-	"fmt"
-)
-
-// Basic handler implementation
-func HandleWidgetOperations(operation string, params map[string]interface{}) (interface{}, error) {
-	return fmt.Sprintf("Operation %s not implemented for Widget", operation), nil
-}
@@ -1,4 +0,0 @@
-package widgets
-
-// Schema definitions for Widget
-// TODO: Implement proper JSON schemas
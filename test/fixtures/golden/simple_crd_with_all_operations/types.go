@@ -1,56 +0,0 @@
-package widgets
-
-import (
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
-)
-
-// Widget represents the Widget custom resource
-type Widget struct {
-	metav1.TypeMeta   `json:",inline"`
-	metav1.ObjectMeta `json:"metadata,omitempty"`
-	Spec   WidgetSpec   `json:"spec,omitempty"`
-	Status WidgetStatus `json:"status,omitempty"`
-}
-
-// WidgetSpec defines the desired state of Widget
-type WidgetSpec struct {
-	// Add spec fields here based on CRD schema
-}
-
-// WidgetStatus defines the observed state of Widget
-type WidgetStatus struct {
-	// Add status fields here based on CRD schema
-}
-
-// WidgetList contains a list of Widget
-type WidgetList struct {
-	metav1.TypeMeta `json:",inline"`
-	metav1.ListMeta `json:"metadata,omitempty"`
-	Items           []Widget `json:"items"`
-}
-
-// DeepCopyInto is an autogenerated deepcopy function
-func (in *Widget) DeepCopyInto(out *Widget) {
-	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-}
-
-// DeepCopy is an autogenerated deepcopy function
-func (in *Widget) DeepCopy() *Widget {
-	if in == nil {
-		return nil
-	}
-	out := new(Widget)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyObject is an autogenerated deepcopy function
-func (in *Widget) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
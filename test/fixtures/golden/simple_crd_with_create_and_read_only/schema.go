@@ -1,4 +0,0 @@
-package widgets_readonly
-
-// Schema definitions for Widget
-// TODO: Implement proper JSON schemas
@@ -0,0 +1,751 @@
+package integration
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/friedrichwilken/mcp-toolgen/pkg/analyzer"
+	"github.com/friedrichwilken/mcp-toolgen/pkg/generator"
+	"github.com/friedrichwilken/mcp-toolgen/test/utils"
+)
+
+// clientTestPreamble is shared by the generated client tests. It registers the generated Widget
+// types with a scheme through the generated AddToScheme so they can be served by
+// controller-runtime's fake client.
+const clientTestPreamble = `package widgets
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var (
+	_ = context.Background
+	_ client.Object = &Widget{}
+)
+
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to register Widget types: %v", err)
+	}
+	return scheme
+}
+
+func newWidget(name, namespace string, labels map[string]string) *Widget {
+	return &Widget{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels}}
+}
+
+func widgetNames(list *WidgetList) []string {
+	names := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		names = append(names, item.Name)
+	}
+	return names
+}
+
+var (
+	_ = fake.NewClientBuilder
+	_ = interceptor.Funcs{}
+)
+`
+
+// clientTestFiles are the generated files that compile against the module's own dependencies.
+var clientTestFiles = []string{"types.go", "register.go", "client.go"}
+
+// TestGeneratedClientListSelectors compiles the generated client and verifies that label and
+// field selectors filter the listed objects.
+func TestGeneratedClientListSelectors(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateClientTestToolset(t, nil)
+
+	utils.RunGeneratedPackageTests(t, generatedDir, clientTestFiles, clientTestPreamble+`
+func TestListWithSelectors(t *testing.T) {
+	c := fake.NewClientBuilder().
+		WithScheme(newTestScheme(t)).
+		WithObjects(
+			newWidget("web", "default", map[string]string{"tier": "web"}),
+			newWidget("db", "default", map[string]string{"tier": "db"}),
+			newWidget("other", "other", map[string]string{"tier": "web"}),
+		).
+		WithIndex(&Widget{}, "metadata.name", func(obj client.Object) []string {
+			return []string{obj.GetName()}
+		}).
+		Build()
+	widgets := NewWidgetClient(c, "default")
+	ctx := context.Background()
+
+	list, err := widgets.ListWithSelectors(ctx, "tier=web", "")
+	if err != nil {
+		t.Fatalf("label selector list failed: %v", err)
+	}
+	if got := widgetNames(list); len(got) != 1 || got[0] != "web" {
+		t.Fatalf("expected [web] for tier=web, got %v", got)
+	}
+
+	list, err = widgets.ListWithSelectors(ctx, "", "metadata.name=db")
+	if err != nil {
+		t.Fatalf("field selector list failed: %v", err)
+	}
+	if got := widgetNames(list); len(got) != 1 || got[0] != "db" {
+		t.Fatalf("expected [db] for metadata.name=db, got %v", got)
+	}
+
+	list, err = widgets.ListWithSelectors(ctx, "", "")
+	if err != nil {
+		t.Fatalf("unfiltered list failed: %v", err)
+	}
+	if got := widgetNames(list); len(got) != 2 {
+		t.Fatalf("expected both widgets in default namespace, got %v", got)
+	}
+
+	if _, err := widgets.ListWithSelectors(ctx, "tier in (", ""); err == nil {
+		t.Fatal("expected an error for an invalid label selector")
+	}
+}
+`)
+}
+
+// TestGeneratedClientListPagination compiles the generated client and verifies that the limit and
+// continue token are passed to the API server and that the token round-trips between pages.
+// The fake client does not paginate, so an interceptor emulates the API server's behaviour.
+func TestGeneratedClientListPagination(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateClientTestToolset(t, nil)
+
+	utils.RunGeneratedPackageTests(t, generatedDir, clientTestFiles, clientTestPreamble+`
+func paginate(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+	listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+	if err := c.List(ctx, list, opts...); err != nil {
+		return err
+	}
+
+	widgets, ok := list.(*WidgetList)
+	if !ok || listOpts.Limit == 0 {
+		return nil
+	}
+
+	start := 0
+	if listOpts.Continue != "" {
+		for i, item := range widgets.Items {
+			if item.Name == listOpts.Continue {
+				start = i
+			}
+		}
+	}
+	end := start + int(listOpts.Limit)
+	widgets.Continue = ""
+	if end < len(widgets.Items) {
+		widgets.Continue = widgets.Items[end].Name
+	} else {
+		end = len(widgets.Items)
+	}
+	widgets.Items = widgets.Items[start:end]
+	return nil
+}
+
+func TestListPage(t *testing.T) {
+	c := fake.NewClientBuilder().
+		WithScheme(newTestScheme(t)).
+		WithObjects(
+			newWidget("a", "default", nil),
+			newWidget("b", "default", nil),
+			newWidget("c", "default", nil),
+		).
+		WithInterceptorFuncs(interceptor.Funcs{List: paginate}).
+		Build()
+	widgets := NewWidgetClient(c, "default")
+	ctx := context.Background()
+
+	first, err := widgets.ListPage(ctx, 2, "")
+	if err != nil {
+		t.Fatalf("first page failed: %v", err)
+	}
+	if got := widgetNames(first); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Fatalf("expected [a b] on the first page, got %v", got)
+	}
+	if first.Continue == "" {
+		t.Fatal("expected a continue token when more widgets exist than the limit")
+	}
+
+	second, err := widgets.ListPage(ctx, 2, first.Continue)
+	if err != nil {
+		t.Fatalf("second page failed: %v", err)
+	}
+	if got := widgetNames(second); len(got) != 1 || got[0] != "c" {
+		t.Fatalf("expected [c] on the second page, got %v", got)
+	}
+	if second.Continue != "" {
+		t.Fatalf("expected no continue token on the last page, got %q", second.Continue)
+	}
+}
+`)
+}
+
+// TestGeneratedCreateOnlyCompiles verifies that a toolset generated with a single operation
+// compiles, i.e. imports needed only by other operations are pruned from the output.
+func TestGeneratedCreateOnlyCompiles(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateClientTestToolset(t, []string{"create"})
+
+	utils.RunGeneratedPackageTests(t, generatedDir, clientTestFiles, clientTestPreamble+`
+func TestCreate(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(newTestScheme(t)).Build()
+	widgets := NewWidgetClient(c, "default")
+
+	if err := widgets.Create(context.Background(), newWidget("a", "default", nil)); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+}
+`)
+}
+
+// TestGeneratedBuildTagCompiles verifies that files guarded by a build tag still compile and pass
+// vet when the tag is set.
+func TestGeneratedBuildTagCompiles(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateClientTestToolsetWithBuildTag(t, []string{"create"}, "mytools")
+
+	utils.RunGeneratedPackageTestsWithEnv(t, generatedDir, clientTestFiles, clientTestPreamble+`
+func TestCreate(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(newTestScheme(t)).Build()
+	widgets := NewWidgetClient(c, "default")
+
+	if err := widgets.Create(context.Background(), newWidget("a", "default", nil)); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+}
+`, []string{"GOFLAGS=-tags=mytools"})
+}
+
+// TestGeneratedTypesDeepCopy compiles the generated types of a CRD with nested objects, slices
+// and free-form maps and verifies that they implement the controller-runtime object interfaces
+// and that DeepCopy does not share memory with the original.
+func TestGeneratedTypesDeepCopy(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCode(t, "cluster-scoped-crd.yaml", "clusterwidgets", nil)
+
+	utils.RunGeneratedPackageTests(t, generatedDir, []string{"types.go", "register.go"}, `package clusterwidgets
+
+import (
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestObjectInterfaces(t *testing.T) {
+	var obj any = &GlobalConfig{}
+	if _, ok := obj.(client.Object); !ok {
+		t.Fatal("GlobalConfig does not implement client.Object")
+	}
+
+	var list any = &GlobalConfigList{}
+	if _, ok := list.(client.ObjectList); !ok {
+		t.Fatal("GlobalConfigList does not implement client.ObjectList")
+	}
+}
+
+func TestDeepCopy(t *testing.T) {
+	original := &GlobalConfig{
+		Spec: GlobalConfigSpec{
+			GlobalConfigSpecEndpoints:      []string{"a"},
+			GlobalConfigSpecGlobalsettings: map[string]string{"key": "value"},
+		},
+		Status: GlobalConfigStatus{
+			GlobalConfigStatusConditions: []GlobalConfigStatusConditionItem{{GlobalConfigStatusConditionItemType: "Ready"}},
+		},
+	}
+	original.Labels = map[string]string{"app": "test"}
+
+	copied, ok := original.DeepCopyObject().(*GlobalConfig)
+	if !ok {
+		t.Fatal("DeepCopyObject did not return a *GlobalConfig")
+	}
+	copied.Labels["app"] = "changed"
+	copied.Spec.GlobalConfigSpecEndpoints[0] = "changed"
+	copied.Spec.GlobalConfigSpecGlobalsettings["key"] = "changed"
+	copied.Status.GlobalConfigStatusConditions[0].GlobalConfigStatusConditionItemType = "changed"
+
+	if original.Labels["app"] != "test" {
+		t.Error("labels are shared between copies")
+	}
+	if original.Spec.GlobalConfigSpecEndpoints[0] != "a" {
+		t.Error("slice fields are shared between copies")
+	}
+	if original.Spec.GlobalConfigSpecGlobalsettings["key"] != "value" {
+		t.Error("map fields are shared between copies")
+	}
+	if original.Status.GlobalConfigStatusConditions[0].GlobalConfigStatusConditionItemType != "Ready" {
+		t.Error("slices of nested objects are shared between copies")
+	}
+
+	list := &GlobalConfigList{Items: []GlobalConfig{*original}}
+	copiedList := list.DeepCopyObject().(*GlobalConfigList)
+	copiedList.Items[0].Spec.GlobalConfigSpecEndpoints[0] = "changed"
+	if list.Items[0].Spec.GlobalConfigSpecEndpoints[0] != "a" {
+		t.Error("list items are shared between copies")
+	}
+}
+`)
+}
+
+// TestGeneratedComplexTypesCompile compiles the generated types of a CRD that uses int-or-string
+// and preserve-unknown-fields and verifies that unknown data survives a JSON round trip.
+func TestGeneratedComplexTypesCompile(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCode(t, "complex-crd.yaml", "applications", nil)
+
+	utils.RunGeneratedPackageTests(t, generatedDir, []string{"types.go", "register.go"}, `package applications
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPreservedFieldsRoundTrip(t *testing.T) {
+	input := []byte(`+"`"+`{"spec":{"extensions":{"version":"v2","custom":{"nested":[1,2]}}}}`+"`"+`)
+
+	var app Application
+	if err := json.Unmarshal(input, &app); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	copied := app.DeepCopy()
+	out, err := json.Marshal(copied.Spec.ApplicationSpecExtensions)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	if string(out) != `+"`"+`{"version":"v2","custom":{"nested":[1,2]}}`+"`"+` {
+		t.Fatalf("unknown fields were not preserved: %s", out)
+	}
+}
+
+func TestMapFieldsDeepCopy(t *testing.T) {
+	app := &Application{
+		Spec: ApplicationSpec{
+			ApplicationSpecWeights: map[string]int32{"canary": 10},
+			ApplicationSpecSidecars: map[string]ApplicationSpecSidecarsValue{
+				"proxy": {ApplicationSpecSidecarsValueArgs: []string{"--verbose"}},
+			},
+		},
+	}
+
+	copied := app.DeepCopy()
+	copied.Spec.ApplicationSpecWeights["canary"] = 50
+	copied.Spec.ApplicationSpecSidecars["proxy"].ApplicationSpecSidecarsValueArgs[0] = "--quiet"
+
+	if app.Spec.ApplicationSpecWeights["canary"] != 10 {
+		t.Fatalf("weights map was shared with the copy")
+	}
+	if app.Spec.ApplicationSpecSidecars["proxy"].ApplicationSpecSidecarsValueArgs[0] != "--verbose" {
+		t.Fatalf("sidecar args were shared with the copy")
+	}
+}
+`)
+}
+
+func TestGeneratedRefTypesCompile(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCode(t, "ref-crd.yaml", "topologies", nil)
+
+	utils.RunGeneratedPackageTests(t, generatedDir, []string{"types.go", "register.go"}, `package topologies
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCyclicRefRoundTrip(t *testing.T) {
+	input := []byte(`+"`"+`{"spec":{"primary":{"host":"a","port":80},"root":{"name":"r","children":[{"name":"c","children":[]}]}}}`+"`"+`)
+
+	var topology Topology
+	if err := json.Unmarshal(input, &topology); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if topology.Spec.TopologySpecPrimary.TopologySpecPrimaryPort != 80 {
+		t.Fatalf("referenced definition was not inlined")
+	}
+
+	copied := topology.DeepCopy()
+	out, err := json.Marshal(copied.Spec.TopologySpecRoot.TopologySpecRootChildren)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	if string(out) != `+"`"+`[{"children":[],"name":"c"}]`+"`"+` {
+		t.Fatalf("cyclic subtree was not kept: %s", out)
+	}
+}
+`)
+}
+
+func TestGeneratedNestedTypesCompile(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCode(t, "nested-crd.yaml", "pipelines", nil)
+
+	utils.RunGeneratedPackageTests(t, generatedDir, []string{"types.go", "register.go"}, `package pipelines
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNestedRoundTrip(t *testing.T) {
+	input := []byte(`+"`"+`{"spec":{"template":{"metadata":{"owner":{"team":"ci"}},"stages":[{"name":"build","retry":{"limit":2,"backoff":{"seconds":5}}}]},"matrix":[[{"key":"os"}]],"groups":{"admins":[{"member":"ann"}]}}}`+"`"+`)
+
+	var pipeline Pipeline
+	if err := json.Unmarshal(input, &pipeline); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	spec := pipeline.DeepCopy().Spec
+	if spec.PipelineSpecTemplate.PipelineSpecTemplateMetadata.PipelineSpecTemplateMetadataOwner.PipelineSpecTemplateMetadataOwnerTeam != "ci" {
+		t.Fatalf("three levels of nested objects were not decoded")
+	}
+	if spec.PipelineSpecTemplate.PipelineSpecTemplateStages[0].PipelineSpecTemplateStageItemRetry.PipelineSpecTemplateStageItemRetryBackoff.PipelineSpecTemplateStageItemRetryBackoffSeconds != 5 {
+		t.Fatalf("objects nested in array items were not decoded")
+	}
+	if spec.PipelineSpecMatrix[0][0].PipelineSpecMatrixItemItemKey != "os" {
+		t.Fatalf("objects in nested arrays were not decoded")
+	}
+	if spec.PipelineSpecGroups["admins"][0].PipelineSpecGroupsValueItemMember != "ann" {
+		t.Fatalf("objects in map values were not decoded")
+	}
+}
+`)
+}
+
+func TestGeneratedMaxDepthTypesCompile(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCodeWithConfig(t, "nested-crd.yaml", "pipelines", func(config *analyzer.GenerationConfig) {
+		config.MaxDepth = 2
+	})
+
+	utils.RunGeneratedPackageTests(t, generatedDir, []string{"types.go", "register.go"}, `package pipelines
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCollapsedRoundTrip(t *testing.T) {
+	input := []byte(`+"`"+`{"spec":{"template":{"metadata":{"owner":{"team":"ci"}},"stages":[{"name":"build","retry":{"limit":2}}]}}}`+"`"+`)
+
+	var pipeline Pipeline
+	if err := json.Unmarshal(input, &pipeline); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	// Objects below the second level are generic maps, deep copied like any other field
+	copied := pipeline.DeepCopy()
+	metadata := copied.Spec.PipelineSpecTemplate.PipelineSpecTemplateMetadata
+	if metadata["owner"].(map[string]interface{})["team"] != "ci" {
+		t.Fatalf("collapsed object was not decoded: %v", metadata)
+	}
+	metadata["owner"] = nil
+	if pipeline.Spec.PipelineSpecTemplate.PipelineSpecTemplateMetadata["owner"] == nil {
+		t.Fatalf("collapsed object was not deep copied")
+	}
+	if copied.Spec.PipelineSpecTemplate.PipelineSpecTemplateStages[0]["retry"] == nil {
+		t.Fatalf("collapsed array items were not decoded")
+	}
+}
+`)
+}
+
+// TestGeneratedGenericSpecCompile generates the toolset of a CRD whose spec declares no properties
+// and verifies that the package type-checks and that the map[string]interface{} spec is decoded and
+// deep copied.
+func TestGeneratedGenericSpecCompile(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCode(t, "empty-spec-crd.yaml", "markers", nil)
+
+	var files []string
+	for _, filename := range []string{"types.go", "register.go", "client.go", "handlers.go", "options.go", "schema.go", "errors.go", "toolset.go"} {
+		files = append(files, filepath.Join(generatedDir, filename))
+	}
+	utils.TypeCheckGeneratedFiles(t, "markers", files...)
+
+	utils.RunGeneratedPackageTests(t, generatedDir, []string{"types.go", "register.go"}, `package markers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestGenericSpecRoundTrip(t *testing.T) {
+	input := []byte(`+"`"+`{"spec":{"color":"red","labels":{"tier":"gold"}},"status":{"phase":"Ready"}}`+"`"+`)
+
+	var marker Marker
+	if err := json.Unmarshal(input, &marker); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if marker.Spec["color"] != "red" || marker.Status.MarkerStatusPhase != "Ready" {
+		t.Fatalf("marker was not decoded: %+v", marker)
+	}
+
+	// The generic spec is deep copied, nested maps included
+	copied := marker.DeepCopy()
+	copied.Spec["labels"].(map[string]interface{})["tier"] = "silver"
+	if marker.Spec["labels"].(map[string]interface{})["tier"] != "gold" {
+		t.Fatalf("spec was not deep copied")
+	}
+
+	output, err := json.Marshal(&marker)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	var roundTripped Marker
+	if err := json.Unmarshal(output, &roundTripped); err != nil || roundTripped.Spec["color"] != "red" {
+		t.Fatalf("spec did not survive the round trip: %s", output)
+	}
+}
+`)
+}
+
+func TestGeneratedArrayItemTypesCompile(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCode(t, "containers-crd.yaml", "widgets", nil)
+
+	utils.RunGeneratedPackageTests(t, generatedDir, []string{"types.go", "register.go"}, `package widgets
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestContainersDeepCopy(t *testing.T) {
+	input := []byte(`+"`"+`{"spec":{"containers":[{"name":"app","image":"nginx","ports":[{"containerPort":80}]}]}}`+"`"+`)
+
+	var widget Widget
+	if err := json.Unmarshal(input, &widget); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	copied := widget.DeepCopy()
+	copied.Spec.WidgetSpecContainers[0].WidgetSpecContainerItemPorts[0].WidgetSpecContainerItemPortItemContainerport = 8080
+	if widget.Spec.WidgetSpecContainers[0].WidgetSpecContainerItemPorts[0].WidgetSpecContainerItemPortItemContainerport != 80 {
+		t.Fatalf("container ports were shared with the copy")
+	}
+}
+`)
+}
+
+func TestGeneratedFormatTypesCompile(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCode(t, "formats-crd.yaml", "schedules", nil)
+
+	utils.RunGeneratedPackageTests(t, generatedDir, []string{"types.go", "register.go"}, `package schedules
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestFormatRoundTrip(t *testing.T) {
+	input := []byte(`+"`"+`{"spec":{"startTime":"2024-05-01T10:00:00Z","interval":"1h30m","payload":"aGVsbG8=","labels":{"a":"Yg=="}},"status":{"lastRunTime":"2024-05-01T11:30:00Z"}}`+"`"+`)
+
+	var schedule Schedule
+	if err := json.Unmarshal(input, &schedule); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if !schedule.Spec.ScheduleSpecStarttime.Time.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("date-time was not decoded: %v", schedule.Spec.ScheduleSpecStarttime)
+	}
+	if schedule.Spec.ScheduleSpecInterval.Duration != 90*time.Minute {
+		t.Fatalf("duration was not decoded: %v", schedule.Spec.ScheduleSpecInterval)
+	}
+	if string(schedule.Spec.ScheduleSpecPayload) != "hello" {
+		t.Fatalf("byte was not decoded: %q", schedule.Spec.ScheduleSpecPayload)
+	}
+
+	copied := schedule.DeepCopy()
+	copied.Spec.ScheduleSpecPayload[0] = 'j'
+	copied.Spec.ScheduleSpecLabels["a"][0] = 'c'
+	if string(schedule.Spec.ScheduleSpecPayload) != "hello" || string(schedule.Spec.ScheduleSpecLabels["a"]) != "b" {
+		t.Fatalf("byte slices were shared with the copy")
+	}
+
+	out, err := json.Marshal(schedule.Status)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	if string(out) != `+"`"+`{"lastRunTime":"2024-05-01T11:30:00Z"}`+"`"+` {
+		t.Fatalf("unexpected status JSON: %s", out)
+	}
+}
+`)
+}
+
+// TestGeneratedUnstructuredClient generates the toolset of a CRD without a structural schema with
+// --allow-unstructured and verifies that the whole package type-checks and that the generated
+// client gets and lists the unstructured objects, keeping fields no schema describes.
+func TestGeneratedUnstructuredClient(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCodeWithConfig(t, "schemaless-crd.yaml", "blobs", func(config *analyzer.GenerationConfig) {
+		config.SelectedOperations = []string{"get", "list"}
+		config.AllowUnstructured = true
+	})
+
+	var files []string
+	for _, filename := range []string{"types.go", "register.go", "client.go", "handlers.go", "options.go", "schema.go", "errors.go", "toolset.go"} {
+		files = append(files, filepath.Join(generatedDir, filename))
+	}
+	utils.TypeCheckGeneratedFiles(t, "blobs", files...)
+
+	utils.RunGeneratedPackageTests(t, generatedDir, clientTestFiles, `package blobs
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newBlob(name string, data map[string]any) *Blob {
+	blob := NewUnstructuredBlob()
+	blob.SetName(name)
+	blob.SetNamespace("default")
+	blob.Object["data"] = data
+	return blob
+}
+
+func TestUnstructuredGetList(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to register Blob types: %v", err)
+	}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(GroupVersion.WithKind("Blob"), meta.RESTScopeNamespace)
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithRESTMapper(mapper).
+		WithObjects(newBlob("a", map[string]any{"size": int64(3)}), newBlob("b", nil)).
+		Build()
+	c := NewBlobClient(fakeClient, "default")
+
+	blob, err := c.Get(context.Background(), "a")
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if size, _, _ := unstructured.NestedInt64(blob.Object, "data", "size"); size != 3 {
+		t.Fatalf("the data of the Blob was not kept: %v", blob.Object)
+	}
+
+	list, err := c.List(context.Background())
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(list.Items) != 2 {
+		t.Fatalf("listed %d blobs, want 2", len(list.Items))
+	}
+}
+`)
+}
+
+// TestGeneratedCachedClient generates the client with --with-cache for a typed and an unstructured
+// toolset, verifies that both type-check, and that clients without a cache start right away.
+// Cached reads against an API server are covered by the e2e tests.
+func TestGeneratedCachedClient(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	blobsDir := generateTestCodeWithConfig(t, "schemaless-crd.yaml", "blobs", func(config *analyzer.GenerationConfig) {
+		config.AllowUnstructured = true
+		config.WithCache = true
+	})
+	var blobFiles []string
+	for _, filename := range clientTestFiles {
+		blobFiles = append(blobFiles, filepath.Join(blobsDir, filename))
+	}
+	utils.TypeCheckGeneratedFiles(t, "blobs", blobFiles...)
+
+	widgetsDir := generateTestCodeWithConfig(t, "simple-crd.yaml", "widgets", func(config *analyzer.GenerationConfig) {
+		config.WithCache = true
+	})
+	client := utils.ReadFileContent(t, filepath.Join(widgetsDir, "client.go"))
+	require.Contains(t, client, "func NewWidgetCachedClientForConfig(cfg *rest.Config, namespace string) (*WidgetClient, error) {")
+	require.Contains(t, client, "func (c *WidgetClient) Start(ctx context.Context) error {")
+
+	utils.RunGeneratedPackageTests(t, widgetsDir, clientTestFiles, `package widgets
+
+import (
+	"context"
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestStartWithoutCache(t *testing.T) {
+	c := NewWidgetClient(fake.NewClientBuilder().Build(), "default")
+	if err := c.WithNamespace("other").Start(context.Background()); err != nil {
+		t.Fatalf("a client without a cache should start right away, got %v", err)
+	}
+}
+`)
+}
+
+// generateClientTestToolset generates the simple Widget fixture with the given operations (all
+// operations when nil) and returns the output directory.
+func generateClientTestToolset(t *testing.T, operations []string) string {
+	t.Helper()
+	return generateClientTestToolsetWithBuildTag(t, operations, "")
+}
+
+// generateClientTestToolsetWithBuildTag works like generateClientTestToolset and guards the
+// generated files with buildTag.
+func generateClientTestToolsetWithBuildTag(t *testing.T, operations []string, buildTag string) string {
+	t.Helper()
+
+	crdPath := utils.GetFixturePath(t, "simple-crd.yaml")
+	outputDir := utils.TempDir(t)
+
+	crdInfo, err := analyzer.NewCRDAnalyzer().ParseCRDFromFile(crdPath)
+	require.NoError(t, err, "Failed to parse CRD")
+
+	config := analyzer.DefaultGenerationConfig()
+	config.PackageName = "widgets"
+	config.ModulePath = "github.com/test/module"
+	config.OutputDir = outputDir
+	config.SelectedOperations = operations
+
+	toolsetInfo, err := analyzer.NewToolsetInfo(crdInfo, config)
+	require.NoError(t, err, "Failed to create toolset info")
+
+	gen, err := generator.NewGenerator(&generator.GeneratorConfig{
+		OutputDir:       outputDir,
+		PackageName:     "widgets",
+		ModulePath:      "github.com/test/module",
+		OverwriteFiles:  true,
+		IncludeComments: true,
+		BuildTag:        buildTag,
+	})
+	require.NoError(t, err, "Failed to create generator")
+	require.NoError(t, gen.GenerateToolset(toolsetInfo), "Failed to generate toolset")
+
+	return outputDir
+}
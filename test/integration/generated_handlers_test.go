@@ -1,8 +1,10 @@
 package integration
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -176,11 +178,1542 @@ func TestGeneratedHandlersErrorHandling(t *testing.T) {
 			assert.Contains(t, content, `if err != nil {`, "handlers should check for errors")
 
 			// Verify marshaling exists
-			assert.Contains(t, content, "json.", "handlers should use JSON")
+			assert.Contains(t, content, "yaml.Marshal(", "handlers should marshal resource data")
 		}
 	})
 }
 
+// TestGeneratedPrinterColumnsOutput runs the generated compact list printer against an unstructured
+// list. The handlers depend on kubernetes-mcp-server, so only the printer declarations are extracted.
+func TestGeneratedPrinterColumnsOutput(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCode(t, "complex-crd.yaml", "applications", []string{"list"})
+
+	columnsDir := utils.TempDir(t)
+	columns := utils.WriteGeneratedDecls(t, generatedDir, columnsDir, "handlers.go",
+		"applicationPrinterColumns", "printApplicationColumns")
+
+	utils.RunGeneratedPackageTests(t, columnsDir, []string{columns}, `package applications
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPrintColumns(t *testing.T) {
+	list := &unstructured.UnstructuredList{}
+	list.Items = []unstructured.Unstructured{{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "web", "namespace": "prod"},
+		"spec":     map[string]interface{}{"replicas": int64(3)},
+	}}}
+
+	out, err := printApplicationColumns(list)
+	if err != nil {
+		t.Fatalf("printing failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected header and one row, got %q", out)
+	}
+	if got := strings.Join(strings.Fields(lines[0]), " "); got != "NAMESPACE NAME REPLICAS READY AGE" {
+		t.Fatalf("unexpected header %q", lines[0])
+	}
+	if got := strings.Join(strings.Fields(lines[1]), " "); got != "prod web 3 <none> <none>" {
+		t.Fatalf("unexpected row %q", lines[1])
+	}
+}
+`)
+}
+
+// TestGeneratedHandlerDefaultNamespace runs the generated get handler with stand-ins for the
+// kubernetes-mcp-server API and checks that an omitted namespace falls back to the configured default.
+func TestGeneratedHandlerDefaultNamespace(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCodeWithConfig(t, "simple-crd.yaml", "widgets", func(config *analyzer.GenerationConfig) {
+		config.SelectedOperations = []string{"get"}
+		config.DefaultNamespace = "team-a"
+	})
+
+	handlerDir, files := utils.WriteHandlerPackage(t, generatedDir, "handleWidgetGet", "projectFields", "setTypeMeta")
+
+	utils.RunGeneratedPackageTests(t, handlerDir, files, `package widgets
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestDefaultNamespace(t *testing.T) {
+	var namespace string
+	params := ToolHandlerParams{
+		Context: context.Background(),
+		Get: func(_ context.Context, _ *schema.GroupVersionKind, ns, name string) (*unstructured.Unstructured, error) {
+			namespace = ns
+			obj := &unstructured.Unstructured{}
+			obj.SetNamespace(ns)
+			obj.SetName(name)
+			return obj, nil
+		},
+	}
+
+	params.Arguments = map[string]any{"name": "web"}
+	if _, err := handleWidgetGet(params); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	if namespace != "team-a" {
+		t.Fatalf("expected the default namespace team-a, got %q", namespace)
+	}
+
+	params.Arguments = map[string]any{"name": "web", "namespace": "team-b"}
+	if _, err := handleWidgetGet(params); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	if namespace != "team-b" {
+		t.Fatalf("expected the explicit namespace team-b, got %q", namespace)
+	}
+}
+`)
+}
+
+// TestGeneratedHandlerNotFound runs the generated get handler against a stand-in for the
+// kubernetes-mcp-server API whose client reports the Widget as missing, and checks that the tool
+// error carries the not found code instead of only the raw API error.
+func TestGeneratedHandlerNotFound(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCode(t, "simple-crd.yaml", "widgets", []string{"get"})
+
+	handlerDir, files := utils.WriteHandlerPackage(t, generatedDir, "handleWidgetGet", "projectFields", "setTypeMeta")
+
+	utils.RunGeneratedPackageTests(t, handlerDir, files, `package widgets
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestNotFound(t *testing.T) {
+	result, err := handleWidgetGet(ToolHandlerParams{
+		Context:   context.Background(),
+		Arguments: map[string]any{"name": "web"},
+		Get: func(_ context.Context, gvk *schema.GroupVersionKind, _, name string) (*unstructured.Unstructured, error) {
+			return nil, apierrors.NewNotFound(schema.GroupResource{Group: gvk.Group, Resource: "widgets"}, name)
+		},
+	})
+	if err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+
+	var toolErr *ToolError
+	if !errors.As(result.Error, &toolErr) {
+		t.Fatalf("expected a ToolError, got %v", result.Error)
+	}
+	if toolErr.Code != ToolErrorNotFound {
+		t.Fatalf("expected code %s, got %s", ToolErrorNotFound, toolErr.Code)
+	}
+	for _, want := range []string{"failed to get widget web", "does not exist", "(not_found)", "\"web\" not found"} {
+		if !strings.Contains(result.Error.Error(), want) {
+			t.Errorf("error %q does not contain %q", result.Error, want)
+		}
+	}
+}
+`)
+}
+
+// TestGeneratedHandlerFieldProjection runs the generated get handler against a stand-in for the
+// kubernetes-mcp-server API and checks that the fields argument prunes the returned Widget to the
+// requested paths and its identity.
+func TestGeneratedHandlerFieldProjection(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCode(t, "simple-crd.yaml", "widgets", []string{"get"})
+
+	handlerDir, files := utils.WriteHandlerPackage(t, generatedDir, "handleWidgetGet", "projectFields", "setTypeMeta")
+
+	utils.RunGeneratedPackageTests(t, handlerDir, files, `package widgets
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+func getWidget(args map[string]any) (*ToolCallResult, error) {
+	return handleWidgetGet(ToolHandlerParams{
+		Context:   context.Background(),
+		Arguments: args,
+		Get: func(_ context.Context, _ *schema.GroupVersionKind, namespace, name string) (*unstructured.Unstructured, error) {
+			return &unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": "example.com/v1",
+				"kind":       "Widget",
+				"metadata": map[string]any{
+					"name":            name,
+					"namespace":       namespace,
+					"labels":          map[string]any{"app": "web"},
+					"resourceVersion": "42",
+				},
+				"spec":   map[string]any{"replicas": int64(3), "image": "nginx:1.27"},
+				"status": map[string]any{"phase": "Running"},
+			}}, nil
+		},
+	})
+}
+
+func TestFieldProjection(t *testing.T) {
+	result, err := getWidget(map[string]any{"name": "web", "namespace": "team-a", "fields": []any{"spec.replicas", "spec.missing"}})
+	if err != nil || result.Error != nil {
+		t.Fatalf("handler failed: %v, %v", err, result.Error)
+	}
+
+	var got map[string]any
+	if err := yaml.Unmarshal([]byte(result.Content), &got); err != nil {
+		t.Fatalf("failed to parse result: %v", err)
+	}
+	want := map[string]any{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]any{"name": "web", "namespace": "team-a"},
+		"spec":       map[string]any{"replicas": float64(3)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestFullObjectWithoutFields(t *testing.T) {
+	result, err := getWidget(map[string]any{"name": "web"})
+	if err != nil || result.Error != nil {
+		t.Fatalf("handler failed: %v, %v", err, result.Error)
+	}
+
+	var got map[string]any
+	if err := yaml.Unmarshal([]byte(result.Content), &got); err != nil {
+		t.Fatalf("failed to parse result: %v", err)
+	}
+	if _, ok := got["status"]; !ok {
+		t.Fatalf("expected the full object, got %v", got)
+	}
+}
+
+func TestInvalidFields(t *testing.T) {
+	for _, fields := range []any{"spec.replicas", []any{1}, []any{""}} {
+		result, err := getWidget(map[string]any{"name": "web", "fields": fields})
+		if err != nil {
+			t.Fatalf("handler failed: %v", err)
+		}
+		if result.Error == nil {
+			t.Errorf("expected an error for fields %v", fields)
+		}
+	}
+}
+`)
+}
+
+// TestGeneratedHandlerListSummary runs the generated list handler against a stand-in for the
+// kubernetes-mcp-server API serving a page of Widgets, and checks that the output is a JSON envelope
+// of the items reporting their number, and on a truncated list the remaining items and the
+// continue token.
+func TestGeneratedHandlerListSummary(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCode(t, "simple-crd.yaml", "widgets", []string{"list"})
+
+	handlerDir, files := utils.WriteHandlerPackage(t, generatedDir,
+		"handleWidgetList", "widgetSortableFields", "sortListItems", "compareFieldValues", "fieldNumber", "setTypeMeta")
+
+	utils.RunGeneratedPackageTests(t, handlerDir, files, `package widgets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// listSummary lists a page of items Widgets, followed by remaining more behind the continue token
+func listSummary(t *testing.T, items int, remaining *int64, continued string) map[string]any {
+	t.Helper()
+
+	result, err := handleWidgetList(ToolHandlerParams{
+		Context:   context.Background(),
+		Arguments: map[string]any{"namespace": "team-a"},
+		List: func(_ context.Context, _ *schema.GroupVersionKind, namespace string, _ ResourceListOptions) (runtime.Unstructured, error) {
+			list := &unstructured.UnstructuredList{Object: map[string]any{"apiVersion": "example.com/v1", "kind": "WidgetList"}}
+			for i := range items {
+				item := unstructured.Unstructured{}
+				item.SetAPIVersion("example.com/v1")
+				item.SetKind("Widget")
+				item.SetNamespace(namespace)
+				item.SetName(fmt.Sprintf("widget-%d", i))
+				list.Items = append(list.Items, item)
+			}
+			list.SetRemainingItemCount(remaining)
+			list.SetContinue(continued)
+			return list, nil
+		},
+	})
+	if err != nil || result.Error != nil {
+		t.Fatalf("handler failed: %v, %v", err, result.Error)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal([]byte(result.Content), &got); err != nil {
+		t.Fatalf("failed to parse result %q: %v", result.Content, err)
+	}
+	return got
+}
+
+func TestListItemCount(t *testing.T) {
+	for _, n := range []int{0, 1, 5} {
+		got := listSummary(t, n, nil, "")
+		if got["itemCount"] != float64(n) {
+			t.Errorf("expected itemCount %d, got %v", n, got["itemCount"])
+		}
+		if items, _ := got["items"].([]any); len(items) != n {
+			t.Errorf("expected the %d items to be kept, got %v", n, got["items"])
+		}
+		if _, ok := got["remainingItemCount"]; ok {
+			t.Errorf("remainingItemCount should be left out of a complete list, got %v", got)
+		}
+		if _, ok := got["continue"]; ok {
+			t.Errorf("continue should be left out of a complete list, got %v", got)
+		}
+	}
+}
+
+func TestListTruncated(t *testing.T) {
+	remaining := int64(7)
+	got := listSummary(t, 3, &remaining, "next-page")
+	if got["itemCount"] != float64(3) || got["remainingItemCount"] != float64(7) || got["continue"] != "next-page" {
+		t.Fatalf("expected itemCount 3, remainingItemCount 7 and continue next-page, got %v", got)
+	}
+}
+`)
+}
+
+// TestGeneratedHandlerListSort runs the generated list handler against a stand-in for the
+// kubernetes-mcp-server API serving Widgets created at different times, and checks that sortBy
+// and order sort the returned items and that a field the schema lacks is rejected.
+func TestGeneratedHandlerListSort(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCode(t, "simple-crd.yaml", "widgets", []string{"list"})
+
+	handlerDir, files := utils.WriteHandlerPackage(t, generatedDir,
+		"handleWidgetList", "widgetSortableFields", "sortListItems", "compareFieldValues", "fieldNumber", "setTypeMeta")
+
+	utils.RunGeneratedPackageTests(t, handlerDir, files, `package widgets
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// listWidgets serves three Widgets, neither in name nor in creation order
+func listWidgets(_ context.Context, _ *schema.GroupVersionKind, namespace string, _ ResourceListOptions) (runtime.Unstructured, error) {
+	created := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	list := &unstructured.UnstructuredList{Object: map[string]any{"apiVersion": "example.com/v1", "kind": "WidgetList"}}
+	for _, widget := range []struct {
+		name string
+		age  time.Duration
+	}{{"widget-b", time.Hour}, {"widget-c", 3 * time.Hour}, {"widget-a", 2 * time.Hour}} {
+		item := unstructured.Unstructured{}
+		item.SetAPIVersion("example.com/v1")
+		item.SetKind("Widget")
+		item.SetNamespace(namespace)
+		item.SetName(widget.name)
+		item.SetCreationTimestamp(metav1.NewTime(created.Add(-widget.age)))
+		list.Items = append(list.Items, item)
+	}
+	return list, nil
+}
+
+func listNames(t *testing.T, args map[string]any) []string {
+	t.Helper()
+
+	result, err := handleWidgetList(ToolHandlerParams{Context: context.Background(), Arguments: args, List: listWidgets})
+	if err != nil || result.Error != nil {
+		t.Fatalf("handler failed: %v, %v", err, result.Error)
+	}
+
+	var got map[string]any
+	if err := yaml.Unmarshal([]byte(result.Content), &got); err != nil {
+		t.Fatalf("failed to parse result %q: %v", result.Content, err)
+	}
+	var names []string
+	items, _ := got["items"].([]any)
+	for _, item := range items {
+		metadata, _ := item.(map[string]any)["metadata"].(map[string]any)
+		names = append(names, metadata["name"].(string))
+	}
+	return names
+}
+
+func TestListSortByCreationTimestamp(t *testing.T) {
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{"", []string{"widget-c", "widget-a", "widget-b"}},
+		{"asc", []string{"widget-c", "widget-a", "widget-b"}},
+		{"desc", []string{"widget-b", "widget-a", "widget-c"}},
+	}
+	for _, tt := range tests {
+		args := map[string]any{"namespace": "team-a", "sortBy": "metadata.creationTimestamp"}
+		if tt.order != "" {
+			args["order"] = tt.order
+		}
+		if got := listNames(t, args); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("order %q: expected %v, got %v", tt.order, tt.want, got)
+		}
+	}
+}
+
+func TestListSortByName(t *testing.T) {
+	got := listNames(t, map[string]any{"namespace": "team-a", "sortBy": "metadata.name"})
+	if want := []string{"widget-a", "widget-b", "widget-c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestListUnsorted(t *testing.T) {
+	got := listNames(t, map[string]any{"namespace": "team-a"})
+	if want := []string{"widget-b", "widget-c", "widget-a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the API server order %v, got %v", want, got)
+	}
+}
+
+func TestListSortInvalid(t *testing.T) {
+	tests := []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"sortBy": "spec.missing"}, "sortBy \"spec.missing\" is not a sortable field of Widget"},
+		{map[string]any{"sortBy": "metadata.name", "order": "up"}, "order must be asc or desc"},
+		{map[string]any{"order": "desc"}, "order requires sortBy"},
+	}
+	for _, tt := range tests {
+		result, err := handleWidgetList(ToolHandlerParams{Context: context.Background(), Arguments: tt.args, List: listWidgets})
+		if err != nil {
+			t.Fatalf("handler failed: %v", err)
+		}
+		if result.Error == nil || !strings.Contains(result.Error.Error(), tt.want) {
+			t.Errorf("%v: expected an error containing %q, got %v", tt.args, tt.want, result.Error)
+		}
+	}
+}
+`)
+}
+
+// TestGeneratedHandlerTypeMeta runs the generated get and list handlers against a stand-in for the
+// kubernetes-mcp-server API that serves Widgets without apiVersion and kind, as objects decoded
+// into typed structs are, and checks that the output tells the kind of every object.
+func TestGeneratedHandlerTypeMeta(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCode(t, "simple-crd.yaml", "widgets", []string{"get", "list"})
+
+	handlerDir, files := utils.WriteHandlerPackage(t, generatedDir,
+		"handleWidgetGet", "projectFields", "handleWidgetList", "widgetSortableFields", "sortListItems",
+		"compareFieldValues", "fieldNumber", "setTypeMeta")
+
+	utils.RunGeneratedPackageTests(t, handlerDir, files, `package widgets
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+func widget(namespace, name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{"name": name, "namespace": namespace},
+		"spec":     map[string]any{"replicas": int64(3)},
+	}}
+}
+
+// params serves Widgets and lists of them without apiVersion and kind
+func params(args map[string]any) ToolHandlerParams {
+	return ToolHandlerParams{
+		Context:   context.Background(),
+		Arguments: args,
+		Get: func(_ context.Context, _ *schema.GroupVersionKind, namespace, name string) (*unstructured.Unstructured, error) {
+			return widget(namespace, name), nil
+		},
+		List: func(_ context.Context, _ *schema.GroupVersionKind, namespace string, _ ResourceListOptions) (runtime.Unstructured, error) {
+			list := &unstructured.UnstructuredList{Object: map[string]any{}}
+			for _, name := range []string{"web", "api"} {
+				list.Items = append(list.Items, *widget(namespace, name))
+			}
+			return list, nil
+		},
+	}
+}
+
+func parseResult(t *testing.T, result *ToolCallResult, err error) map[string]any {
+	t.Helper()
+
+	if err != nil || result.Error != nil {
+		t.Fatalf("handler failed: %v, %v", err, result.Error)
+	}
+	var got map[string]any
+	if err := yaml.Unmarshal([]byte(result.Content), &got); err != nil {
+		t.Fatalf("failed to parse result %q: %v", result.Content, err)
+	}
+	return got
+}
+
+func TestGetTypeMeta(t *testing.T) {
+	for _, args := range []map[string]any{
+		{"name": "web"},
+		{"name": "web", "fields": []any{"spec.replicas"}},
+	} {
+		result, err := handleWidgetGet(params(args))
+		got := parseResult(t, result, err)
+		if got["apiVersion"] != "example.com/v1" || got["kind"] != "Widget" {
+			t.Errorf("expected apiVersion example.com/v1 and kind Widget for %v, got %v", args, got)
+		}
+	}
+}
+
+func TestListTypeMeta(t *testing.T) {
+	result, err := handleWidgetList(params(map[string]any{}))
+	got := parseResult(t, result, err)
+	items, _ := got["items"].([]any)
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %v", got["items"])
+	}
+	for _, item := range items {
+		item, _ := item.(map[string]any)
+		if item["apiVersion"] != "example.com/v1" || item["kind"] != "Widget" {
+			t.Errorf("expected apiVersion example.com/v1 and kind Widget, got %v", item)
+		}
+	}
+}
+`)
+}
+
+// TestGeneratedHandlerListAllNamespaces runs the generated list handler against a stand-in for
+// the kubernetes-mcp-server API holding Widgets in two namespaces, and checks that allNamespaces
+// lists the Widgets of both instead of those of the requested namespace.
+func TestGeneratedHandlerListAllNamespaces(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCodeWithConfig(t, "simple-crd.yaml", "widgets", func(config *analyzer.GenerationConfig) {
+		config.SelectedOperations = []string{"list"}
+		config.AllNamespacesList = true
+	})
+
+	handlerDir, files := utils.WriteHandlerPackage(t, generatedDir,
+		"handleWidgetList", "widgetSortableFields", "sortListItems", "compareFieldValues", "fieldNumber", "setTypeMeta")
+
+	utils.RunGeneratedPackageTests(t, handlerDir, files, `package widgets
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// listWidgets serves a Widget in each of the namespaces team-a and team-b, where an empty
+// namespace lists those of all namespaces
+func listWidgets(_ context.Context, _ *schema.GroupVersionKind, namespace string, _ ResourceListOptions) (runtime.Unstructured, error) {
+	list := &unstructured.UnstructuredList{Object: map[string]any{"apiVersion": "example.com/v1", "kind": "WidgetList"}}
+	for _, ns := range []string{"team-a", "team-b"} {
+		if namespace != "" && namespace != ns {
+			continue
+		}
+		item := unstructured.Unstructured{}
+		item.SetAPIVersion("example.com/v1")
+		item.SetKind("Widget")
+		item.SetNamespace(ns)
+		item.SetName(fmt.Sprintf("widget-%s", ns))
+		list.Items = append(list.Items, item)
+	}
+	return list, nil
+}
+
+func listNamespaces(t *testing.T, args map[string]any) []string {
+	t.Helper()
+
+	result, err := handleWidgetList(ToolHandlerParams{Context: context.Background(), Arguments: args, List: listWidgets})
+	if err != nil || result.Error != nil {
+		t.Fatalf("handler failed: %v, %v", err, result.Error)
+	}
+
+	var got struct {
+		Items []struct {
+			Metadata struct {
+				Namespace string
+			}
+		}
+	}
+	if err := yaml.Unmarshal([]byte(result.Content), &got); err != nil {
+		t.Fatalf("failed to parse result %q: %v", result.Content, err)
+	}
+	var namespaces []string
+	for _, item := range got.Items {
+		namespaces = append(namespaces, item.Metadata.Namespace)
+	}
+	return namespaces
+}
+
+func TestListAllNamespaces(t *testing.T) {
+	got := listNamespaces(t, map[string]any{"namespace": "team-a", "allNamespaces": true})
+	if len(got) != 2 || got[0] != "team-a" || got[1] != "team-b" {
+		t.Fatalf("expected the Widgets of team-a and team-b, got %v", got)
+	}
+
+	got = listNamespaces(t, map[string]any{"namespace": "team-a", "allNamespaces": false})
+	if len(got) != 1 || got[0] != "team-a" {
+		t.Fatalf("expected only the Widget of team-a, got %v", got)
+	}
+}
+
+func TestListAllNamespacesInvalid(t *testing.T) {
+	result, err := handleWidgetList(ToolHandlerParams{Context: context.Background(), Arguments: map[string]any{"allNamespaces": "yes"}, List: listWidgets})
+	if err != nil || result.Error == nil || result.Error.Error() != "allNamespaces is not a boolean" {
+		t.Fatalf("expected allNamespaces to be rejected, got %v, %v", err, result.Error)
+	}
+}
+`)
+}
+
+// TestGeneratedHandlerUpdateRetriesOnConflict runs the generated update handler against a
+// stand-in for the kubernetes-mcp-server API that rejects stale resource versions, and checks that
+// a conflict is retried with the latest resourceVersion and reported once the retries run out.
+func TestGeneratedHandlerUpdateRetriesOnConflict(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCode(t, "simple-crd.yaml", "widgets", []string{"update"})
+
+	handlerDir, files := utils.WriteHandlerPackage(t, generatedDir, "handleWidgetUpdate", "WidgetUpdateRetries",
+		"resourceFromArguments", "missingRequiredFields", "widgetRequiredFields")
+
+	utils.RunGeneratedPackageTests(t, handlerDir, files, `package widgets
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// update runs the update handler against a cluster whose Widget has resourceVersion current, where
+// applying any other resourceVersion conflicts. With busy set, the Widget changes before every
+// apply. It returns the applied resources.
+func update(t *testing.T, resourceVersion string, current int, busy bool) (*ToolCallResult, []string) {
+	t.Helper()
+
+	var applied []string
+	result, err := handleWidgetUpdate(ToolHandlerParams{
+		Context: context.Background(),
+		Arguments: map[string]any{"args": map[string]any{
+			"metadata": map[string]any{"name": "web", "namespace": "default", "resourceVersion": resourceVersion},
+			"spec":     map[string]any{"name": "web"},
+		}},
+		Get: func(_ context.Context, _ *schema.GroupVersionKind, namespace, name string) (*unstructured.Unstructured, error) {
+			latest := &unstructured.Unstructured{}
+			latest.SetNamespace(namespace)
+			latest.SetName(name)
+			latest.SetResourceVersion(strconv.Itoa(current))
+			return latest, nil
+		},
+		CreateOrUpdate: func(_ context.Context, resource string) ([]*unstructured.Unstructured, error) {
+			applied = append(applied, resource)
+			if busy {
+				current++
+			}
+			obj := &unstructured.Unstructured{}
+			if err := yaml.Unmarshal([]byte(resource), &obj.Object); err != nil {
+				return nil, err
+			}
+			if obj.GetResourceVersion() != strconv.Itoa(current) {
+				return nil, apierrors.NewConflict(schema.GroupResource{Group: "example.com", Resource: "widgets"}, obj.GetName(),
+					errors.New("the object has been modified"))
+			}
+			return []*unstructured.Unstructured{obj}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	return result, applied
+}
+
+func TestUpdateRetriesOnConflict(t *testing.T) {
+	result, applied := update(t, "1", 2, false)
+	if result.Error != nil {
+		t.Fatalf("update failed: %v", result.Error)
+	}
+	if len(applied) != 2 {
+		t.Fatalf("expected a conflict and one retry, got %d attempts", len(applied))
+	}
+	if !strings.Contains(applied[1], "resourceVersion: \"2\"") {
+		t.Fatalf("the retry should use the latest resourceVersion:\n%s", applied[1])
+	}
+}
+
+func TestUpdateReportsPersistentConflict(t *testing.T) {
+	result, applied := update(t, "1", 1, true)
+	if len(applied) != WidgetUpdateRetries+1 {
+		t.Fatalf("expected %d attempts, got %d", WidgetUpdateRetries+1, len(applied))
+	}
+
+	var toolErr *ToolError
+	if !errors.As(result.Error, &toolErr) || toolErr.Code != ToolErrorConflict {
+		t.Fatalf("expected a conflict tool error, got %v", result.Error)
+	}
+}
+`)
+}
+
+// TestGeneratedHandlerCreateManifest runs the generated create handler with a stand-in for the
+// kubernetes-mcp-server API and checks that a pasted YAML or JSON manifest is created like the
+// equivalent args object, in the namespace argument unless it names one, and that exactly one of
+// args and manifest is accepted.
+func TestGeneratedHandlerCreateManifest(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCode(t, "simple-crd.yaml", "widgets", []string{"create"})
+
+	handlerDir, files := utils.WriteHandlerPackage(t, generatedDir, "handleWidgetCreate", "resourceFromArguments",
+		"missingRequiredFields", "widgetRequiredFields")
+
+	utils.RunGeneratedPackageTests(t, handlerDir, files, `package widgets
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// createWidget runs the create handler and returns its result and the resources it created
+func createWidget(t *testing.T, arguments map[string]any) (*ToolCallResult, []string) {
+	t.Helper()
+
+	var created []string
+	result, err := handleWidgetCreate(ToolHandlerParams{
+		Context:   context.Background(),
+		Arguments: arguments,
+		CreateOrUpdate: func(_ context.Context, resource string) ([]*unstructured.Unstructured, error) {
+			created = append(created, resource)
+			obj := &unstructured.Unstructured{}
+			return []*unstructured.Unstructured{obj}, yaml.Unmarshal([]byte(resource), &obj.Object)
+		},
+	})
+	if err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	return result, created
+}
+
+func create(t *testing.T, arguments map[string]any) (map[string]any, *ToolCallResult) {
+	t.Helper()
+
+	result, created := createWidget(t, arguments)
+	if result.Error != nil {
+		return nil, result
+	}
+	if len(created) != 1 {
+		t.Fatalf("expected one created resource, got %d", len(created))
+	}
+	var resource map[string]any
+	if err := yaml.Unmarshal([]byte(created[0]), &resource); err != nil {
+		t.Fatalf("created resource is not YAML: %v\n%s", err, created[0])
+	}
+	return resource, result
+}
+
+func TestCreateFromManifest(t *testing.T) {
+	want := map[string]any{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]any{"name": "web", "namespace": "team-a", "labels": map[string]any{"app": "web"}},
+		"spec":       map[string]any{"name": "web", "size": float64(3)},
+	}
+
+	manifests := map[string]string{
+		"yaml": "apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: web\n  namespace: team-a\n  labels:\n    app: web\nspec:\n  name: web\n  size: 3\n",
+		"json without apiVersion and kind": `+"`"+`{"metadata": {"name": "web", "namespace": "team-a", "labels": {"app": "web"}}, "spec": {"name": "web", "size": 3}}`+"`"+`,
+	}
+	for name, manifest := range manifests {
+		t.Run(name, func(t *testing.T) {
+			created, result := create(t, map[string]any{"manifest": manifest})
+			if result.Error != nil {
+				t.Fatalf("create failed: %v", result.Error)
+			}
+			if !reflect.DeepEqual(created, want) {
+				t.Fatalf("expected %v, got %v", want, created)
+			}
+		})
+	}
+
+	created, result := create(t, map[string]any{"args": map[string]any{
+		"metadata": map[string]any{"name": "web", "namespace": "team-a", "labels": map[string]any{"app": "web"}},
+		"spec":     map[string]any{"name": "web", "size": 3},
+	}})
+	if result.Error != nil {
+		t.Fatalf("create failed: %v", result.Error)
+	}
+	if !reflect.DeepEqual(created, want) {
+		t.Fatalf("expected args to create %v like the manifest, got %v", want, created)
+	}
+}
+
+func TestCreateNamespaceArgument(t *testing.T) {
+	for name, tc := range map[string]struct {
+		manifest string
+		want     string
+	}{
+		"metadata without namespace": {"metadata:\n  name: web\n", "team-b"},
+		"metadata with namespace":    {"metadata:\n  name: web\n  namespace: team-a\n", "team-a"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			created, result := create(t, map[string]any{"manifest": tc.manifest, "namespace": "team-b"})
+			if result.Error != nil {
+				t.Fatalf("create failed: %v", result.Error)
+			}
+			metadata, _ := created["metadata"].(map[string]any)
+			if metadata["namespace"] != tc.want {
+				t.Fatalf("expected the namespace %s, got %v", tc.want, metadata["namespace"])
+			}
+		})
+	}
+}
+
+func TestCreateManifestErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		arguments map[string]any
+		want      string
+	}{
+		"neither":      {map[string]any{}, "exactly one of the arguments args and manifest is required"},
+		"both":         {map[string]any{"args": map[string]any{}, "manifest": "metadata: {}"}, "exactly one of the arguments args and manifest is required"},
+		"not a string": {map[string]any{"manifest": map[string]any{}}, "manifest is not a string"},
+		"invalid":      {map[string]any{"manifest": "metadata: ["}, "invalid manifest"},
+		"empty":        {map[string]any{"manifest": ""}, "manifest is empty"},
+		"other kind":   {map[string]any{"manifest": "apiVersion: example.com/v1\nkind: Gadget\n"}, "manifest has kind Gadget instead of Widget"},
+		"other group":  {map[string]any{"manifest": "apiVersion: other.com/v1\nkind: Widget\n"}, "manifest has apiVersion other.com/v1 instead of example.com/v1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, result := create(t, tc.arguments)
+			if result.Error == nil || !strings.Contains(result.Error.Error(), tc.want) {
+				t.Fatalf("expected an error containing %q, got %v", tc.want, result.Error)
+			}
+		})
+	}
+}
+
+func TestCreateRequiredFields(t *testing.T) {
+	for name, arguments := range map[string]map[string]any{
+		"args": {"args": map[string]any{
+			"metadata": map[string]any{"name": "web"},
+			"spec":     map[string]any{"size": 3},
+		}},
+		"manifest": {"manifest": "metadata:\n  name: web\nspec:\n  name: null\n  size: 3\n"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, created := createWidget(t, arguments)
+			if result.Error == nil || result.Error.Error() != "failed to create widget, missing required fields spec.name" {
+				t.Fatalf("expected the missing spec.name to be named, got %v", result.Error)
+			}
+			if len(created) != 0 {
+				t.Fatalf("expected no API call for a resource missing required fields, got %v", created)
+			}
+		})
+	}
+
+	// The fields of the spec are only checked when there is a spec, which the API server may require
+	_, result := create(t, map[string]any{"args": map[string]any{"metadata": map[string]any{"name": "web"}}})
+	if result.Error != nil {
+		t.Fatalf("expected a resource without spec to reach the API server, got %v", result.Error)
+	}
+}
+`)
+}
+
+// TestGeneratedHandlerDeleteOptions runs the generated delete handler with stand-ins for the
+// kubernetes-mcp-server API and checks that a grace period and propagation policy reach the delete
+// call of the dynamic client of the MCP server, while deletes without options use its API.
+func TestGeneratedHandlerDeleteOptions(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCode(t, "simple-crd.yaml", "widgets", []string{"delete"})
+
+	handlerDir, files := utils.WriteHandlerPackage(t, generatedDir, "handleWidgetDelete")
+
+	utils.RunGeneratedPackageTests(t, handlerDir, files, `package widgets
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestDeleteOptions(t *testing.T) {
+	widget := &unstructured.Unstructured{}
+	widget.SetGroupVersionKind(WidgetGroupVersionKind)
+	widget.SetNamespace("prod")
+	widget.SetName("web")
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), widget)
+
+	var dynamicDeleted string
+	var options metav1.DeleteOptions
+	dynamicClient.PrependReactor("delete", "widgets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		deleteAction := action.(k8stesting.DeleteAction)
+		dynamicDeleted = deleteAction.GetNamespace() + "/" + deleteAction.GetName()
+		options = deleteAction.GetDeleteOptions()
+		return false, nil, nil
+	})
+
+	var deleted string
+	params := ToolHandlerParams{
+		Context: context.Background(),
+		Dynamic: dynamicClient,
+		Delete: func(_ context.Context, _ *schema.GroupVersionKind, namespace, name string) error {
+			deleted = namespace + "/" + name
+			return nil
+		},
+	}
+
+	params.Arguments = map[string]any{"name": "web", "namespace": "prod", "gracePeriodSeconds": float64(30), "propagationPolicy": "Foreground"}
+	result, err := handleWidgetDelete(params)
+	if err != nil || result.Error != nil {
+		t.Fatalf("delete failed: %v %v", err, result.Error)
+	}
+	if deleted != "" || dynamicDeleted != "prod/web" {
+		t.Fatalf("deletes with options should go through the dynamic client of the MCP server, got %q and %q", deleted, dynamicDeleted)
+	}
+	if options.GracePeriodSeconds == nil || *options.GracePeriodSeconds != 30 {
+		t.Errorf("expected grace period 30, got %v", options.GracePeriodSeconds)
+	}
+	if options.PropagationPolicy == nil || *options.PropagationPolicy != metav1.DeletePropagationForeground {
+		t.Errorf("expected propagation policy Foreground, got %v", options.PropagationPolicy)
+	}
+
+	dynamicDeleted = ""
+	params.Arguments = map[string]any{"name": "other", "namespace": "prod"}
+	if result, _ := handleWidgetDelete(params); result.Error != nil {
+		t.Fatalf("delete failed: %v", result.Error)
+	}
+	if deleted != "prod/other" || dynamicDeleted != "" {
+		t.Errorf("deletes without options should go through the MCP server API, got %q and %q", deleted, dynamicDeleted)
+	}
+
+	params.Arguments = map[string]any{"name": "web", "namespace": "prod", "propagationPolicy": "Sideways"}
+	if result, _ := handleWidgetDelete(params); result.Error == nil {
+		t.Error("an unknown propagation policy was accepted")
+	}
+}
+`)
+}
+
+// TestGeneratedHandlerScale runs the generated scale handler for a CRD with a scale subresource
+// against a fake dynamic client of the MCP server, and checks that the replicas are patched through
+// the scale subresource of the named WorkerPool.
+func TestGeneratedHandlerScale(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCode(t, "scalable-crd.yaml", "workerpools", []string{"update"})
+
+	handlerDir, files := utils.WriteHandlerPackage(t, generatedDir, "handleWorkerPoolScale")
+
+	utils.RunGeneratedPackageTests(t, handlerDir, files, `package workerpools
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestScale(t *testing.T) {
+	// The fake dynamic client tracks no subresources, so the patch is answered by the reactor
+	var scaled, patch string
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	dynamicClient.PrependReactor("patch", "workerpools", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patchAction := action.(k8stesting.PatchAction)
+		scaled = patchAction.GetSubresource() + " " + patchAction.GetNamespace() + "/" + patchAction.GetName()
+		patch = string(patchAction.GetPatch())
+		return true, nil, nil
+	})
+
+	params := ToolHandlerParams{Context: context.Background(), Dynamic: dynamicClient}
+	params.Arguments = map[string]any{"name": "batch", "namespace": "prod", "replicas": float64(5)}
+	result, err := handleWorkerPoolScale(params)
+	if err != nil || result.Error != nil {
+		t.Fatalf("scale failed: %v %v", err, result.Error)
+	}
+	if scaled != "scale prod/batch" || patch != `+"`"+`{"spec":{"replicas":5}}`+"`"+` {
+		t.Fatalf("expected the scale subresource of prod/batch to be patched to 5 replicas, got %s with %s", scaled, patch)
+	}
+	if result.Content != "WorkerPool batch scaled to 5 replicas" {
+		t.Errorf("unexpected result %q", result.Content)
+	}
+
+	for _, replicas := range []any{float64(-1), float64(1.5), "3", nil} {
+		params.Arguments = map[string]any{"name": "batch", "replicas": replicas}
+		if result, _ := handleWorkerPoolScale(params); result.Error == nil {
+			t.Errorf("replicas %v were accepted", replicas)
+		}
+	}
+}
+`)
+}
+
+// TestGeneratedHandlerCustomOperation runs the handler generated for a custom pause operation
+// against a fake dynamic client of the MCP server, and checks that the declared patch is applied to
+// the named Widget.
+func TestGeneratedHandlerCustomOperation(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	operations, err := analyzer.LoadCustomOperations(utils.GetFixturePath(t, "operations/pause.yaml"))
+	require.NoError(t, err)
+	generatedDir := generateTestCodeWithConfig(t, "simple-crd.yaml", "widgets", func(config *analyzer.GenerationConfig) {
+		config.SelectedOperations = []string{"get"}
+		config.CustomOperations = operations
+	})
+
+	// The tools, schemas and handlers of the operations are valid code next to the CRUD tools
+	var generatedFiles []string
+	for _, filename := range []string{"types.go", "register.go", "client.go", "errors.go", "handlers.go", "options.go", "schema.go", "toolset.go"} {
+		generatedFiles = append(generatedFiles, filepath.Join(generatedDir, filename))
+	}
+	utils.TypeCheckGeneratedFiles(t, "widgets", generatedFiles...)
+
+	handlerDir, files := utils.WriteHandlerPackage(t, generatedDir,
+		"handleWidgetPatch", "widgetPausePatch")
+
+	utils.RunGeneratedPackageTests(t, handlerDir, files, `package widgets
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestPause(t *testing.T) {
+	widget := &unstructured.Unstructured{}
+	widget.SetGroupVersionKind(WidgetGroupVersionKind)
+	widget.SetNamespace("prod")
+	widget.SetName("web")
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), widget)
+
+	var patched, patch string
+	var patchType types.PatchType
+	dynamicClient.PrependReactor("patch", "widgets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patchAction := action.(k8stesting.PatchAction)
+		patched = patchAction.GetNamespace() + "/" + patchAction.GetName()
+		patch, patchType = string(patchAction.GetPatch()), patchAction.GetPatchType()
+		return false, nil, nil
+	})
+
+	params := ToolHandlerParams{Context: context.Background(), Dynamic: dynamicClient}
+	params.Arguments = map[string]any{"name": "web", "namespace": "prod"}
+	result, err := handleWidgetPatch(params, "pause", types.MergePatchType, widgetPausePatch)
+	if err != nil || result.Error != nil {
+		t.Fatalf("pause failed: %v %v", err, result.Error)
+	}
+	if patched != "prod/web" || patch != `+"`"+`{"spec":{"paused":true}}`+"`"+` || patchType != types.MergePatchType {
+		t.Fatalf("expected prod/web to get the pause merge patch, got %s with %s (%s)", patched, patch, patchType)
+	}
+	if !strings.Contains(result.Content, "name: web") {
+		t.Errorf("expected the patched Widget in the result, got %q", result.Content)
+	}
+
+	params.Arguments = map[string]any{"name": "missing", "namespace": "prod"}
+	result, _ = handleWidgetPatch(params, "pause", types.MergePatchType, widgetPausePatch)
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "pause widget missing") {
+		t.Errorf("expected a not found error naming the operation, got %v", result.Error)
+	}
+}
+`)
+}
+
+// TestGeneratedHandlerFind runs the generated find handler with stand-ins for the
+// kubernetes-mcp-server API and checks that a uniquely labeled Widget is found while a selector
+// matching several fails.
+func TestGeneratedHandlerFind(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCodeWithConfig(t, "simple-crd.yaml", "widgets", func(config *analyzer.GenerationConfig) {
+		config.SelectedOperations = []string{"get"}
+		config.FindTool = true
+	})
+
+	handlerDir, files := utils.WriteHandlerPackage(t, generatedDir,
+		"handleWidgetFind", "setTypeMeta")
+
+	utils.RunGeneratedPackageTests(t, handlerDir, files, `package widgets
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestHandlerFind(t *testing.T) {
+	widgets := map[string]string{"web": "frontend", "db-1": "backend", "db-2": "backend"}
+	list := func(_ context.Context, _ *schema.GroupVersionKind, namespace string, options ResourceListOptions) (runtime.Unstructured, error) {
+		if options.AsTable {
+			t.Error("find must list items, not a table")
+		}
+		selector, err := labels.Parse(options.LabelSelector)
+		if err != nil {
+			return nil, err
+		}
+		result := &unstructured.UnstructuredList{}
+		for _, name := range []string{"db-1", "db-2", "web"} {
+			tier := labels.Set{"tier": widgets[name]}
+			if !selector.Matches(tier) {
+				continue
+			}
+			widget := unstructured.Unstructured{}
+			widget.SetNamespace(namespace)
+			widget.SetName(name)
+			widget.SetLabels(tier)
+			result.Items = append(result.Items, widget)
+		}
+		return result, nil
+	}
+	find := func(labelSelector string) *ToolCallResult {
+		t.Helper()
+		result, err := handleWidgetFind(ToolHandlerParams{
+			Context:   context.Background(),
+			List:      list,
+			Arguments: map[string]any{"namespace": "prod", "labelSelector": labelSelector},
+		})
+		if err != nil {
+			t.Fatalf("handler failed: %v", err)
+		}
+		return result
+	}
+
+	result := find("tier=frontend")
+	if result.Error != nil {
+		t.Fatalf("expected the uniquely labeled Widget to be found, got %v", result.Error)
+	}
+	if !strings.Contains(result.Content, "name: web") || !strings.Contains(result.Content, "kind: Widget") {
+		t.Errorf("expected the web Widget with its kind, got:\n%s", result.Content)
+	}
+
+	result = find("tier=backend")
+	if result.Error == nil {
+		t.Fatalf("expected a selector matching two Widgets to fail, got:\n%s", result.Content)
+	}
+	for _, want := range []string{"matches 2 widgets", "db-1", "db-2"} {
+		if !strings.Contains(result.Error.Error(), want) {
+			t.Errorf("expected the error to contain %q, got %v", want, result.Error)
+		}
+	}
+
+	result = find("tier=cache")
+	var toolErr *ToolError
+	if !errors.As(result.Error, &toolErr) || toolErr.Code != ToolErrorNotFound {
+		t.Errorf("expected a not_found tool error for a selector matching nothing, got %v", result.Error)
+	}
+}
+`)
+}
+
+// TestGeneratedHandlerToolsetClient runs generated handlers the way the tools of a toolset created
+// by NewToolsetFromKubeconfig call them, against a fake client, and checks that they reach the
+// cluster through that client instead of the MCP server, which has no hooks set.
+func TestGeneratedHandlerToolsetClient(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	operations, err := analyzer.LoadCustomOperations(utils.GetFixturePath(t, "operations/pause.yaml"))
+	require.NoError(t, err)
+	generatedDir := generateTestCodeWithConfig(t, "simple-crd.yaml", "widgets", func(config *analyzer.GenerationConfig) {
+		config.SelectedOperations = []string{"get", "delete"}
+		config.FindTool = true
+		config.CustomOperations = operations
+	})
+
+	handlerDir, files := utils.WriteHandlerPackage(t, generatedDir,
+		"handleWidgetGet", "handleWidgetFind", "handleWidgetDelete", "handleWidgetPatch", "widgetPausePatch",
+		"setTypeMeta", "projectFields")
+
+	utils.RunGeneratedPackageTests(t, handlerDir, files, `package widgets
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestToolsetClient(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to register Widget types: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&Widget{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "prod", Labels: map[string]string{"tier": "frontend"}}},
+		&Widget{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod", Labels: map[string]string{"tier": "backend"}}},
+		&Widget{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "staging", Labels: map[string]string{"tier": "backend"}}},
+	).Build()
+	widgets := NewWidgetClient(fakeClient, "prod")
+
+	call := func(handler ToolHandlerFunc, args map[string]any) *ToolCallResult {
+		t.Helper()
+		result, err := withClient(handler, widgets)(ToolHandlerParams{Context: context.Background(), Arguments: args})
+		if err != nil {
+			t.Fatalf("handler failed: %v", err)
+		}
+		return result
+	}
+	pause := func(params ToolHandlerParams) (*ToolCallResult, error) {
+		return handleWidgetPatch(params, "pause", types.MergePatchType, widgetPausePatch)
+	}
+
+	// Without a namespace the handlers use the one of the client
+	result := call(handleWidgetGet, map[string]any{"name": "web"})
+	if result.Error != nil || !strings.Contains(result.Content, "name: web") || !strings.Contains(result.Content, "namespace: prod") {
+		t.Fatalf("expected prod/web from the client, got %q (%v)", result.Content, result.Error)
+	}
+
+	result = call(handleWidgetFind, map[string]any{"namespace": "prod", "labelSelector": "tier=backend"})
+	if result.Error != nil || !strings.Contains(result.Content, "name: db") || !strings.Contains(result.Content, "kind: Widget") {
+		t.Fatalf("expected prod/db to be found through the client, got %q (%v)", result.Content, result.Error)
+	}
+
+	result = call(pause, map[string]any{"name": "web"})
+	if result.Error != nil || !strings.Contains(result.Content, "name: web") {
+		t.Fatalf("expected prod/web to be patched through the client, got %q (%v)", result.Content, result.Error)
+	}
+
+	result = call(handleWidgetDelete, map[string]any{"name": "db", "namespace": "staging", "gracePeriodSeconds": 0})
+	if result.Error != nil {
+		t.Fatalf("expected staging/db to be deleted through the client, got %v", result.Error)
+	}
+	result = call(handleWidgetGet, map[string]any{"name": "db", "namespace": "staging"})
+	var toolErr *ToolError
+	if !errors.As(result.Error, &toolErr) || toolErr.Code != ToolErrorNotFound {
+		t.Errorf("expected the deleted Widget to be gone, got %v", result.Error)
+	}
+
+	// Without the client, the same handlers call the MCP server
+	result, _ = handleWidgetGet(ToolHandlerParams{Context: context.Background(), Arguments: map[string]any{"name": "web"}})
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "no hook") {
+		t.Errorf("expected the handler to call the MCP server without a client, got %v", result.Error)
+	}
+}
+`)
+}
+
+// TestGeneratedHandlerTimeout runs the generated patch handler of a custom operation against a fake
+// client that hangs until its context is done and checks that the handler timeout ends the call
+// with a timeout error.
+func TestGeneratedHandlerTimeout(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	operations, err := analyzer.LoadCustomOperations(utils.GetFixturePath(t, "operations/pause.yaml"))
+	require.NoError(t, err)
+	generatedDir := generateTestCodeWithConfig(t, "simple-crd.yaml", "widgets", func(config *analyzer.GenerationConfig) {
+		config.SelectedOperations = []string{"get"}
+		config.CustomOperations = operations
+		config.HandlerTimeout = 200 * time.Millisecond
+	})
+
+	handlerDir, files := utils.WriteHandlerPackage(t, generatedDir,
+		"handleWidgetPatch", "widgetPausePatch", "withHandlerTimeout", "WidgetHandlerTimeout")
+	assert.Contains(t, utils.ReadFileContent(t, filepath.Join(handlerDir, "handlers.go")),
+		"const WidgetHandlerTimeout time.Duration = 200 * time.Millisecond")
+
+	utils.RunGeneratedPackageTests(t, handlerDir, files, `package widgets
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestHandlerTimeout(t *testing.T) {
+	// The API server hangs until the handler gives up. The fake dynamic client ignores the
+	// context, so the reactor waits on the one the timeout was added to.
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	params, cancel := withHandlerTimeout(ToolHandlerParams{Context: context.Background(), Dynamic: dynamicClient, Arguments: map[string]any{"name": "web", "namespace": "prod"}})
+	defer cancel()
+	dynamicClient.PrependReactor("patch", "widgets", func(k8stesting.Action) (bool, runtime.Object, error) {
+		<-params.Done()
+		return true, nil, params.Err()
+	})
+
+	start := time.Now()
+	result, err := handleWidgetPatch(params, "pause", types.MergePatchType, widgetPausePatch)
+	if err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("the handler timeout did not end the call, it took %s", elapsed)
+	}
+
+	var toolErr *ToolError
+	if !errors.As(result.Error, &toolErr) || toolErr.Code != ToolErrorTimeout {
+		t.Fatalf("expected a timeout tool error, got %v", result.Error)
+	}
+	if !errors.Is(result.Error, context.DeadlineExceeded) {
+		t.Errorf("expected the timeout error to wrap context.DeadlineExceeded, got %v", result.Error)
+	}
+}
+`)
+}
+
+// TestGeneratedHandlerOptions compiles the generated options with the input schemas of the tools
+// and checks that the options hold a field per schema property and that decoding arguments of the
+// wrong type fails with the name of the argument.
+func TestGeneratedHandlerOptions(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCodeWithConfig(t, "scalable-crd.yaml", "workerpools", func(config *analyzer.GenerationConfig) {
+		config.SelectedOperations = []string{"get", "list", "update", "delete"}
+		config.FindTool = true
+		config.AllNamespacesList = true
+		config.DefaultNamespace = "team-a"
+	})
+
+	handlerDir := utils.TempDir(t)
+	files := []string{
+		utils.WriteStandIn(t, handlerDir, "workerpools"),
+		utils.WriteGeneratedDecls(t, generatedDir, handlerDir, "schema.go",
+			"getWorkerPoolSchema", "listWorkerPoolSchema", "deleteWorkerPoolSchema", "scaleWorkerPoolSchema", "findWorkerPoolSchema"),
+		"options.go",
+	}
+	utils.WriteTestFile(t, handlerDir, "options.go", utils.ReadFileContent(t, filepath.Join(generatedDir, "options.go")))
+
+	utils.RunGeneratedPackageTests(t, handlerDir, files, `package workerpools
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOptionsMatchSchemas(t *testing.T) {
+	for name, tc := range map[string]struct {
+		schema  *Schema
+		options any
+	}{
+		"get":    {getWorkerPoolSchema(), GetWorkerPoolOptions{}},
+		"list":   {listWorkerPoolSchema(), ListWorkerPoolOptions{}},
+		"delete": {deleteWorkerPoolSchema(), DeleteWorkerPoolOptions{}},
+		"scale":  {scaleWorkerPoolSchema(), ScaleWorkerPoolOptions{}},
+		"find":   {findWorkerPoolSchema(), FindWorkerPoolOptions{}},
+	} {
+		var fields []string
+		options := reflect.TypeOf(tc.options)
+		for i := range options.NumField() {
+			fields = append(fields, strings.Split(options.Field(i).Tag.Get("json"), ",")[0])
+		}
+		var properties []string
+		for property := range tc.schema.Properties {
+			properties = append(properties, property)
+		}
+		slices.Sort(fields)
+		slices.Sort(properties)
+		if !slices.Equal(fields, properties) {
+			t.Errorf("%s: expected option fields %v, got %v", name, properties, fields)
+		}
+	}
+}
+
+func TestDecodeOptions(t *testing.T) {
+	get, err := decodeGetWorkerPoolOptions(map[string]any{"name": "pool", "fields": []any{"spec.replicas"}})
+	if err != nil || get.Name != "pool" || get.Namespace != "team-a" || !slices.Equal(get.Fields, []string{"spec.replicas"}) {
+		t.Fatalf("expected pool in the default namespace team-a with one field, got %+v, %v", get, err)
+	}
+
+	list, err := decodeListWorkerPoolOptions(map[string]any{"namespace": "team-b", "limit": float64(10), "allNamespaces": true})
+	if err != nil || list.Namespace != "team-b" || list.Limit == nil || *list.Limit != 10 || !list.AllNamespaces {
+		t.Fatalf("expected the given namespace, limit and allNamespaces, got %+v, %v", list, err)
+	}
+
+	scale, err := decodeScaleWorkerPoolOptions(map[string]any{"name": "pool", "replicas": float64(3)})
+	if err != nil || scale.Replicas == nil || *scale.Replicas != 3 {
+		t.Fatalf("expected 3 replicas, got %+v, %v", scale, err)
+	}
+}
+
+func TestDecodeOptionsRejectsWrongTypes(t *testing.T) {
+	for name, tc := range map[string]struct {
+		decode func(map[string]any) error
+		args   map[string]any
+		want   string
+	}{
+		"name":          {decodeGet, map[string]any{"name": 3}, "name is not a string"},
+		"fields":        {decodeGet, map[string]any{"name": "pool", "fields": "spec.replicas"}, "fields is not a list"},
+		"limit":         {decodeList, map[string]any{"limit": 2.5}, "limit is not an integer"},
+		"allNamespaces": {decodeList, map[string]any{"allNamespaces": "yes"}, "allNamespaces is not a boolean"},
+		"replicas":      {decodeScale, map[string]any{"name": "pool", "replicas": "3"}, "replicas is not an integer"},
+		"replicas size": {decodeScale, map[string]any{"name": "pool", "replicas": float64(1 << 40)}, "replicas is not an integer"},
+		"labelSelector": {decodeFind, map[string]any{"labelSelector": true}, "labelSelector is not a string"},
+	} {
+		if err := tc.decode(tc.args); err == nil || err.Error() != tc.want {
+			t.Errorf("%s: expected %q, got %v", name, tc.want, err)
+		}
+	}
+}
+
+func decodeGet(args map[string]any) error {
+	_, err := decodeGetWorkerPoolOptions(args)
+	return err
+}
+
+func decodeList(args map[string]any) error {
+	_, err := decodeListWorkerPoolOptions(args)
+	return err
+}
+
+func decodeScale(args map[string]any) error {
+	_, err := decodeScaleWorkerPoolOptions(args)
+	return err
+}
+
+func decodeFind(args map[string]any) error {
+	_, err := decodeFindWorkerPoolOptions(args)
+	return err
+}
+`)
+}
+
+// TestGeneratedHandlerArgumentValidation runs the generated get handler with stand-ins for the
+// kubernetes-mcp-server API and checks that unknown and missing arguments are rejected.
+func TestGeneratedHandlerArgumentValidation(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCode(t, "simple-crd.yaml", "widgets", []string{"get"})
+
+	handlerDir, files := utils.WriteHandlerPackage(t, generatedDir,
+		"HandleGetWidget", "validateArguments", "withHandlerTimeout", "WidgetHandlerTimeout")
+	files = append(files, utils.WriteGeneratedDecls(t, generatedDir, handlerDir, "schema.go", "getWidgetSchema"))
+
+	utils.RunGeneratedPackageTests(t, handlerDir, files, `package widgets
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// handleWidgetGet stands in for the generated one, which HandleGetWidget calls once the arguments are valid
+func handleWidgetGet(params ToolHandlerParams) (*ToolCallResult, error) {
+	if _, ok := params.Deadline(); !ok {
+		return NewToolCallResult("", errors.New("the handler context has no deadline")), nil
+	}
+	return NewToolCallResult("ok", nil), nil
+}
+
+func TestArgumentValidation(t *testing.T) {
+	params := ToolHandlerParams{Context: context.Background()}
+	params.Arguments = map[string]any{"name": "web", "namepsace": "team-a"}
+	result, err := HandleGetWidget(params)
+	if err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	want := "invalid arguments for widgets_get: unexpected arguments namepsace (expected cluster, fields, name, namespace)"
+	if result.Error == nil || result.Error.Error() != want {
+		t.Fatalf("expected error %q, got %v", want, result.Error)
+	}
+
+	params.Arguments = map[string]any{"namespace": "team-a"}
+	result, _ = HandleGetWidget(params)
+	want = "invalid arguments for widgets_get: missing required arguments name"
+	if result.Error == nil || result.Error.Error() != want {
+		t.Fatalf("expected error %q, got %v", want, result.Error)
+	}
+
+	params.Arguments = map[string]any{"name": "web", "namespace": "team-a"}
+	result, _ = HandleGetWidget(params)
+	if result.Error != nil || result.Content != "ok" {
+		t.Fatalf("expected valid arguments to reach the handler with a deadline, got %+v", result)
+	}
+}
+`)
+}
+
 // Helper functions
 
 func getTestCRDPath(t *testing.T) string {
@@ -231,8 +1764,11 @@ func verifyGeneratedFiles(t *testing.T, outputDir string) {
 	expectedFiles := []string{
 		"toolset.go",
 		"types.go",
+		"register.go",
 		"client.go",
 		"handlers.go",
+		"options.go",
+		"errors.go",
 		"schema.go",
 		"doc.go",
 	}
@@ -292,12 +1828,15 @@ func testHandlerParameterParsing(t *testing.T, outputDir string) {
 		// Verify handlers extract arguments
 		assert.Contains(t, content, "args := params.GetArguments()", "Handlers should get arguments from params")
 
-		// Verify handlers use helper functions
-		assert.Contains(t, content, "getClusterName(args)", "Handlers should extract cluster name")
-		assert.Contains(t, content, "getNamespace(args)", "Handlers should extract namespace")
+		// Verify handlers decode the arguments into their options, which hold the target resource
+		assert.Contains(t, content, "opts, err := decodeGetTestWidgetOptions(args)", "Handlers should decode their arguments")
+		options := utils.ReadFileContent(t, outputDir+"/options.go")
+		assert.Regexp(t, "Name\\s+string\\s+`json:\"name\"`", options, "Options should hold the resource name")
+		assert.Regexp(t, "Namespace\\s+string\\s+`json:\"namespace,omitempty\"`", options, "Options should hold the namespace")
 
 		// Verify handlers parse resource data
-		assert.Contains(t, content, "parseResource(", "Handlers should parse resource data")
+		assert.Contains(t, content, "resource, err := resourceFromArguments(opts.Args, opts.Manifest, \"create\")", "Handlers should build resource data")
+		assert.Contains(t, content, "resource[\"kind\"] = \"TestWidget\"", "Resource data should carry its kind")
 	}
 }
 
@@ -0,0 +1,66 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/friedrichwilken/mcp-toolgen/pkg/analyzer"
+	"github.com/friedrichwilken/mcp-toolgen/test/utils"
+)
+
+// TestGeneratedExplicitRegistrationTypeChecks verifies that a server can wire a generated toolset
+// explicitly through NewToolset and Register instead of relying on the blank import.
+func TestGeneratedExplicitRegistrationTypeChecks(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCode(t, "simple-crd.yaml", "widgets", nil)
+	utils.WriteTestFile(t, generatedDir, "server.go", `package widgets
+
+import "github.com/containers/kubernetes-mcp-server/pkg/api"
+
+func explicitToolsets() []api.Toolset {
+	var registered []api.Toolset
+	Register(func(toolset api.Toolset) {
+		registered = append(registered, toolset)
+	})
+
+	var toolset *WidgetToolset = NewToolset()
+	return append(registered, toolset)
+}
+`)
+
+	entries, err := os.ReadDir(generatedDir)
+	require.NoError(t, err)
+	var files []string
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".go") {
+			files = append(files, filepath.Join(generatedDir, entry.Name()))
+		}
+	}
+	utils.TypeCheckGeneratedFiles(t, "widgets", files...)
+}
+
+// TestGeneratedShortNameToolsTypeCheck verifies that the tool aliases generated for the short
+// names of a CRD are valid code next to the canonical tools.
+func TestGeneratedShortNameToolsTypeCheck(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	generatedDir := generateTestCodeWithConfig(t, "simple-crd.yaml", "widgets", func(config *analyzer.GenerationConfig) {
+		config.ShortNameTools = true
+	})
+	require.Contains(t, utils.ReadFileContent(t, filepath.Join(generatedDir, "toolset.go")), `withToolAlias(getwidgetTool(), "wgt_get"),`)
+
+	entries, err := os.ReadDir(generatedDir)
+	require.NoError(t, err)
+	var files []string
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".go") {
+			files = append(files, filepath.Join(generatedDir, entry.Name()))
+		}
+	}
+	utils.TypeCheckGeneratedFiles(t, "widgets", files...)
+}
@@ -0,0 +1,61 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/friedrichwilken/mcp-toolgen/pkg/analyzer"
+	"github.com/friedrichwilken/mcp-toolgen/pkg/generator"
+	"github.com/friedrichwilken/mcp-toolgen/test/utils"
+)
+
+// TestGeneratedSingleFileTypeChecks generates a toolset in single-file mode and verifies that it
+// is one Go file whose merged imports and declarations type-check.
+func TestGeneratedSingleFileTypeChecks(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	outputDir := utils.TempDir(t)
+
+	crdInfo, err := analyzer.NewCRDAnalyzer().ParseCRDFromFile(utils.GetFixturePath(t, "simple-crd.yaml"))
+	require.NoError(t, err, "Failed to parse CRD")
+
+	config := analyzer.DefaultGenerationConfig()
+	config.PackageName = "widgets"
+	config.ModulePath = "github.com/test/module"
+	config.OutputDir = outputDir
+	config.GenerateCRDResource = true
+
+	toolsetInfo, err := analyzer.NewToolsetInfo(crdInfo, config)
+	require.NoError(t, err, "Failed to create toolset info")
+
+	gen, err := generator.NewGenerator(&generator.GeneratorConfig{
+		OutputDir:       outputDir,
+		PackageName:     "widgets",
+		ModulePath:      "github.com/test/module",
+		OverwriteFiles:  true,
+		IncludeComments: true,
+		SingleFile:      true,
+	})
+	require.NoError(t, err, "Failed to create generator")
+	require.NoError(t, gen.GenerateToolset(toolsetInfo), "Failed to generate toolset")
+
+	entries, err := os.ReadDir(outputDir)
+	require.NoError(t, err)
+	var goFiles []string
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) == ".go" {
+			goFiles = append(goFiles, entry.Name())
+		}
+	}
+	assert.Equal(t, []string{"widgets.go"}, goFiles)
+
+	content := utils.ReadFileContent(t, filepath.Join(outputDir, "widgets.go"))
+	assert.Contains(t, content, `apierrors "k8s.io/apimachinery/pkg/api/errors"`,
+		"the API errors package should be aliased next to the standard library errors package")
+
+	utils.TypeCheckGeneratedFiles(t, "widgets", filepath.Join(outputDir, "widgets.go"))
+}
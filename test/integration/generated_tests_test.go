@@ -0,0 +1,68 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/friedrichwilken/mcp-toolgen/pkg/analyzer"
+	"github.com/friedrichwilken/mcp-toolgen/pkg/generator"
+	"github.com/friedrichwilken/mcp-toolgen/test/utils"
+)
+
+// TestGeneratedHandlerTestsTypeCheck generates a toolset with --generate-tests and verifies that
+// the generated handlers_test.go type-checks with the rest of the package and covers every
+// generated operation.
+func TestGeneratedHandlerTestsTypeCheck(t *testing.T) {
+	utils.SkipIfShort(t)
+
+	for _, fixture := range []string{"simple-crd.yaml", "cluster-scoped-crd.yaml", "schemaless-crd.yaml"} {
+		t.Run(fixture, func(t *testing.T) {
+			outputDir := utils.TempDir(t)
+
+			crdInfo, err := analyzer.NewCRDAnalyzer().ParseCRDFromFile(utils.GetFixturePath(t, fixture))
+			require.NoError(t, err, "Failed to parse CRD")
+
+			config := analyzer.DefaultGenerationConfig()
+			config.PackageName = "sample"
+			config.ModulePath = "github.com/test/module"
+			config.OutputDir = outputDir
+			config.GenerateTests = true
+			config.AllowUnstructured = true
+			config.FindTool = true
+
+			toolsetInfo, err := analyzer.NewToolsetInfo(crdInfo, config)
+			require.NoError(t, err, "Failed to create toolset info")
+
+			gen, err := generator.NewGenerator(&generator.GeneratorConfig{
+				OutputDir:       outputDir,
+				PackageName:     "sample",
+				ModulePath:      "github.com/test/module",
+				OverwriteFiles:  true,
+				IncludeComments: true,
+			})
+			require.NoError(t, err, "Failed to create generator")
+			require.NoError(t, gen.GenerateToolset(toolsetInfo), "Failed to generate toolset")
+
+			content := utils.ReadFileContent(t, filepath.Join(outputDir, "handlers_test.go"))
+			for _, operation := range toolsetInfo.GetResourceOperations() {
+				assert.Contains(t, content, `operation: "`+operation+`"`, "operation test for %s", operation)
+				assert.Contains(t, content, operation+crdInfo.Kind+"Schema()", "argument test for %s", operation)
+			}
+
+			entries, err := os.ReadDir(outputDir)
+			require.NoError(t, err)
+			var files []string
+			for _, entry := range entries {
+				if strings.HasSuffix(entry.Name(), ".go") {
+					files = append(files, filepath.Join(outputDir, entry.Name()))
+				}
+			}
+			utils.TypeCheckGeneratedFiles(t, "sample", files...)
+		})
+	}
+}
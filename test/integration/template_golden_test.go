@@ -19,6 +19,9 @@ import (
 // updateGolden is a flag to update golden files when running tests
 var updateGolden = flag.Bool("update-golden", false, "update golden files with current generated output")
 
+// goldenSuffix is appended to generated filenames so golden files are not compiled as part of the module
+const goldenSuffix = ".golden"
+
 // TestTemplateGoldenFiles tests that generated code matches expected golden files
 // Run with -update-golden flag to regenerate golden files
 func TestTemplateGoldenFiles(t *testing.T) {
@@ -40,8 +43,11 @@ func TestTemplateGoldenFiles(t *testing.T) {
 			expectedFiles: []string{
 				"toolset.go",
 				"types.go",
+				"register.go",
 				"client.go",
 				"handlers.go",
+				"options.go",
+				"errors.go",
 				"schema.go",
 				"doc.go",
 			},
@@ -55,8 +61,11 @@ func TestTemplateGoldenFiles(t *testing.T) {
 			expectedFiles: []string{
 				"toolset.go",
 				"types.go",
+				"register.go",
 				"client.go",
 				"handlers.go",
+				"options.go",
+				"errors.go",
 				"schema.go",
 				"doc.go",
 			},
@@ -70,8 +79,11 @@ func TestTemplateGoldenFiles(t *testing.T) {
 			expectedFiles: []string{
 				"toolset.go",
 				"types.go",
+				"register.go",
 				"client.go",
 				"handlers.go",
+				"options.go",
+				"errors.go",
 				"schema.go",
 				"doc.go",
 			},
@@ -96,7 +108,7 @@ func TestTemplateGoldenFiles(t *testing.T) {
 			// Verify all expected files exist in both generated and golden
 			for _, filename := range tc.expectedFiles {
 				generatedPath := filepath.Join(generatedDir, filename)
-				goldenPath := filepath.Join(goldenDir, filename)
+				goldenPath := filepath.Join(goldenDir, filename+goldenSuffix)
 
 				require.FileExists(t, generatedPath, "Generated file should exist: %s", filename)
 
@@ -196,6 +208,15 @@ func TestTemplateEdgeCases(t *testing.T) {
 
 func generateTestCode(t *testing.T, crdFile, packageName string, operations []string) string {
 	t.Helper()
+	return generateTestCodeWithConfig(t, crdFile, packageName, func(config *analyzer.GenerationConfig) {
+		config.SelectedOperations = operations
+	})
+}
+
+// generateTestCodeWithConfig works like generateTestCode and lets configure adjust the generation
+// config before the CRD is analyzed.
+func generateTestCodeWithConfig(t *testing.T, crdFile, packageName string, configure func(*analyzer.GenerationConfig)) string {
+	t.Helper()
 
 	tempDir := utils.TempDir(t)
 	crdPath := utils.GetFixturePath(t, crdFile)
@@ -210,7 +231,7 @@ func generateTestCode(t *testing.T, crdFile, packageName string, operations []st
 	config.PackageName = packageName
 	config.ModulePath = "github.com/test/module"
 	config.OutputDir = tempDir
-	config.SelectedOperations = operations
+	configure(config)
 
 	// Create toolset info
 	toolsetInfo, err := analyzer.NewToolsetInfo(crdInfo, config)
@@ -263,7 +284,7 @@ func updateGoldenFiles(t *testing.T, generatedDir, goldenDir string) {
 		}
 
 		srcPath := filepath.Join(generatedDir, entry.Name())
-		dstPath := filepath.Join(goldenDir, entry.Name())
+		dstPath := filepath.Join(goldenDir, entry.Name()+goldenSuffix)
 
 		content, err := os.ReadFile(srcPath) // #nosec G304 -- test helper
 		require.NoError(t, err, "Failed to read generated file: %s", entry.Name())
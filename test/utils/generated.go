@@ -0,0 +1,320 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// generatedTestTimeout bounds how long compiling and testing a generated package may take.
+const generatedTestTimeout = 5 * time.Minute
+
+// ProjectRoot returns the root directory of the mcp-toolgen module.
+func ProjectRoot(t *testing.T) string {
+	t.Helper()
+
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..")
+}
+
+// RunGeneratedPackageTests copies the given generated files into a scratch package below
+// test/integration/testdata, adds testSource as generated_test.go and runs go test on it.
+// Placing the package inside the module lets generated code that only depends on Kubernetes
+// libraries (types.go, client.go) compile against the module's own dependencies, while the
+// testdata directory keeps it out of ./... patterns.
+func RunGeneratedPackageTests(t *testing.T, generatedDir string, files []string, testSource string) {
+	t.Helper()
+	RunGeneratedPackageTestsWithEnv(t, generatedDir, files, testSource, nil)
+}
+
+// RunGeneratedPackageTestsWithEnv works like RunGeneratedPackageTests and additionally passes
+// env (in KEY=value form) to the go test process, e.g. to point it at a test cluster.
+func RunGeneratedPackageTestsWithEnv(t *testing.T, generatedDir string, files []string, testSource string, env []string) {
+	t.Helper()
+
+	testdataDir := filepath.Join(ProjectRoot(t), "test", "integration", "testdata")
+	if err := os.MkdirAll(testdataDir, 0o755); err != nil {
+		t.Fatalf("Failed to create testdata directory: %v", err)
+	}
+	scratchDir, err := os.MkdirTemp(testdataDir, "generated-")
+	if err != nil {
+		t.Fatalf("Failed to create scratch package: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.RemoveAll(scratchDir)
+	})
+
+	for _, filename := range files {
+		content := ReadFileContent(t, filepath.Join(generatedDir, filename))
+		WriteTestFile(t, scratchDir, filename, content)
+	}
+	WriteTestFile(t, scratchDir, "generated_test.go", testSource)
+
+	ctx, cancel := context.WithTimeout(context.Background(), generatedTestTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "go", "test", "-count=1", ".")
+	cmd.Dir = scratchDir
+	cmd.Env = append(os.Environ(), env...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Generated package tests failed: %v\n%s", err, output)
+	}
+	t.Logf("Generated package tests passed:\n%s", output)
+}
+
+// TypeCheckGeneratedFiles type-checks the given generated files of package packageName and fails
+// the test on any error. Packages that are not dependencies of the mcp-toolgen module, like those
+// of the MCP server, cannot be imported; the type checker stands in fake packages for them that
+// accept every use, so the rest of the code, such as its Kubernetes calls, is still checked.
+func TypeCheckGeneratedFiles(t *testing.T, packageName string, files ...string) {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	var astFiles []*ast.File
+	for _, filename := range files {
+		file, err := parser.ParseFile(fset, filename, nil, 0)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", filename, err)
+		}
+		astFiles = append(astFiles, file)
+	}
+
+	// Read the export data of imported packages from the build cache of the module
+	lookup := func(importPath string) (io.ReadCloser, error) {
+		cmd := exec.Command("go", "list", "-export", "-f", "{{.Export}}", importPath)
+		cmd.Dir = ProjectRoot(t)
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("go list %s: %w", importPath, err)
+		}
+		return os.Open(strings.TrimSpace(string(output)))
+	}
+
+	var errs []string
+	config := types.Config{
+		Importer: importer.ForCompiler(fset, "gc", lookup),
+		Error: func(err error) {
+			if !strings.Contains(err.Error(), "could not import") {
+				errs = append(errs, err.Error())
+			}
+		},
+	}
+	_, _ = config.Check(packageName, fset, astFiles, nil)
+
+	if len(errs) > 0 {
+		t.Fatalf("Generated code does not type-check:\n%s", strings.Join(errs, "\n"))
+	}
+}
+
+// ExtractDecls returns the source of the named top-level declarations of a Go file, and of the
+// methods of the named types, so tests can compile single functions, methods and types of
+// generated code next to stand-ins for the packages the rest of the file depends on.
+func ExtractDecls(t *testing.T, filename string, names ...string) string {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", filename, err)
+	}
+
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	var buf bytes.Buffer
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !wanted[d.Name.Name] && !wanted[receiverTypeName(d)] {
+				continue
+			}
+		case *ast.GenDecl:
+			switch spec := d.Specs[0].(type) {
+			case *ast.ValueSpec:
+				if !wanted[spec.Names[0].Name] {
+					continue
+				}
+			case *ast.TypeSpec:
+				if !wanted[spec.Name.Name] {
+					continue
+				}
+			default:
+				continue
+			}
+		default:
+			continue
+		}
+		if err := format.Node(&buf, fset, decl); err != nil {
+			t.Fatalf("Failed to format declaration of %s: %v", filename, err)
+		}
+		buf.WriteString("\n\n")
+	}
+	if buf.Len() == 0 {
+		t.Fatalf("None of %v found in %s", names, filename)
+	}
+	return buf.String()
+}
+
+// receiverTypeName returns the name of the receiver type of a method, or "" for a function.
+func receiverTypeName(decl *ast.FuncDecl) string {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return ""
+	}
+	recv := decl.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	if ident, ok := recv.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// mcpServerImportPrefixes are the import paths of the packages that generated code uses from the
+// MCP server and its dependencies. The stand-in written by WriteStandIn replaces them.
+var mcpServerImportPrefixes = []string{
+	"github.com/containers/kubernetes-mcp-server/",
+	"github.com/google/jsonschema-go/",
+}
+
+// WriteStandIn writes the stand-in of the MCP server API in test/utils/standin as standin.go of
+// package packageName into dir and returns its file name.
+func WriteStandIn(t *testing.T, dir, packageName string) string {
+	t.Helper()
+
+	source := ReadFileContent(t, filepath.Join(ProjectRoot(t), "test", "utils", "standin", "standin.go"))
+	start := strings.Index(source, "package standin")
+	if start < 0 {
+		t.Fatalf("Stand-in of the MCP server API has no package clause")
+	}
+	WriteTestFile(t, dir, "standin.go", "package "+packageName+strings.TrimPrefix(source[start:], "package standin"))
+	return "standin.go"
+}
+
+// WriteGeneratedDecls writes the named top-level declarations of the generated file filename in
+// generatedDir into dir, or the whole file if no names are given. References to the MCP server
+// packages are rewritten to the stand-in written by WriteStandIn, and imports the declarations do
+// not use are dropped. It returns the file name.
+func WriteGeneratedDecls(t *testing.T, generatedDir, dir, filename string, names ...string) string {
+	t.Helper()
+
+	path := filepath.Join(generatedDir, filename)
+	source := ReadFileContent(t, path)
+	if len(names) > 0 {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, path, source, parser.ImportsOnly)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", path, err)
+		}
+		var header bytes.Buffer
+		fmt.Fprintf(&header, "package %s\n\n", file.Name.Name)
+		for _, spec := range file.Imports {
+			if spec.Name != nil {
+				fmt.Fprintf(&header, "import %s %s\n", spec.Name.Name, spec.Path.Value)
+			} else {
+				fmt.Fprintf(&header, "import %s\n", spec.Path.Value)
+			}
+		}
+		source = header.String() + "\n" + ExtractDecls(t, path, names...)
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, source, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse declarations of %s: %v", path, err)
+	}
+
+	standIns := make(map[string]bool)
+	for _, spec := range file.Imports {
+		importPath := strings.Trim(spec.Path.Value, `"`)
+		for _, prefix := range mcpServerImportPrefixes {
+			if !strings.HasPrefix(importPath, prefix) {
+				continue
+			}
+			name := importPath[strings.LastIndex(importPath, "/")+1:]
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			standIns[name] = true
+		}
+	}
+	astutil.Apply(file, nil, func(c *astutil.Cursor) bool {
+		if sel, ok := c.Node().(*ast.SelectorExpr); ok {
+			if pkg, ok := sel.X.(*ast.Ident); ok && standIns[pkg.Name] {
+				c.Replace(sel.Sel)
+			}
+		}
+		return true
+	})
+
+	var unused []*ast.ImportSpec
+	for _, spec := range file.Imports {
+		if !astutil.UsesImport(file, strings.Trim(spec.Path.Value, `"`)) {
+			unused = append(unused, spec)
+		}
+	}
+	for _, spec := range unused {
+		name := ""
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		astutil.DeleteNamedImport(fset, file, name, strings.Trim(spec.Path.Value, `"`))
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		t.Fatalf("Failed to format declarations of %s: %v", path, err)
+	}
+	WriteTestFile(t, dir, filename, buf.String())
+	return filename
+}
+
+// handlerClusterDecls are the declarations of the generated handlers.go that handlers reach the
+// cluster through.
+var handlerClusterDecls = []string{"cluster", "clientKey", "withClient", "clusterFor", "serverCluster", "clientCluster"}
+
+// WriteHandlerPackage writes the named declarations of the generated handlers.go in generatedDir
+// into a new directory, together with the stand-in of the MCP server API, the declarations the
+// handlers reach the cluster through and the generated files they depend on: errors.go,
+// options.go and the client in types.go, register.go and client.go. It returns the directory and
+// the files to pass to RunGeneratedPackageTests, whose test source sets the hooks of
+// ToolHandlerParams its scenario needs.
+func WriteHandlerPackage(t *testing.T, generatedDir string, names ...string) (string, []string) {
+	t.Helper()
+
+	dir := TempDir(t)
+	if len(names) > 0 {
+		names = append(names, handlerClusterDecls...)
+	}
+	handlers := WriteGeneratedDecls(t, generatedDir, dir, "handlers.go", names...)
+	file, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, handlers), nil, parser.PackageClauseOnly)
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", handlers, err)
+	}
+
+	files := []string{WriteStandIn(t, dir, file.Name.Name), handlers}
+	for _, filename := range []string{"errors.go", "options.go", "types.go", "register.go", "client.go"} {
+		WriteTestFile(t, dir, filename, ReadFileContent(t, filepath.Join(generatedDir, filename)))
+		files = append(files, filename)
+	}
+	return dir, files
+}
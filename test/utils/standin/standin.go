@@ -0,0 +1,149 @@
+// Package standin stands in for the packages of the kubernetes-mcp-server API that generated
+// handlers call, which are no dependency of this module. utils.WriteHandlerPackage copies this file
+// into a package of handlers extracted from a generated toolset, so tests only set the hooks of
+// ToolHandlerParams that answer the Kubernetes calls of their scenario.
+package standin
+
+import (
+	"context"
+	"errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// ToolCallResult stands in for api.ToolCallResult
+type ToolCallResult struct {
+	Content string
+	Error   error
+}
+
+// NewToolCallResult stands in for api.NewToolCallResult
+func NewToolCallResult(content string, err error) *ToolCallResult {
+	return &ToolCallResult{Content: content, Error: err}
+}
+
+// ToolHandlerFunc stands in for api.ToolHandlerFunc
+type ToolHandlerFunc func(params ToolHandlerParams) (*ToolCallResult, error)
+
+// MarshalYaml stands in for output.MarshalYaml
+func MarshalYaml(v any) (string, error) {
+	out, err := yaml.Marshal(v)
+	return string(out), err
+}
+
+// ResourceListOptions stands in for internalk8s.ResourceListOptions, which takes its selectors,
+// limit and continue token from the embedded ListOptions, so generated code cannot set them in a
+// composite literal
+type ResourceListOptions struct {
+	metav1.ListOptions
+	AsTable bool
+}
+
+// Schema stands in for jsonschema.Schema, with the fields the generated tool schemas set
+type Schema struct {
+	Type        string
+	Description string
+	Properties  map[string]*Schema
+	Items       *Schema
+	Required    []string
+	Minimum     *float64
+	Enum        []any
+}
+
+// ListOutput stands in for the output of list results, which it prints as YAML
+type ListOutput struct{}
+
+// AsTable reports that lists are not requested as tables
+func (ListOutput) AsTable() bool {
+	return false
+}
+
+// PrintObj prints obj as YAML
+func (ListOutput) PrintObj(obj runtime.Unstructured) (string, error) {
+	out, err := yaml.Marshal(obj.UnstructuredContent())
+	return string(out), err
+}
+
+// AccessControlClientset stands in for internalk8s.AccessControlClientset
+type AccessControlClientset struct {
+	dynamicClient dynamic.Interface
+}
+
+// DynamicClient returns the Dynamic client of the ToolHandlerParams the clientset belongs to
+func (a *AccessControlClientset) DynamicClient() dynamic.Interface {
+	return a.dynamicClient
+}
+
+// ToolHandlerParams stands in for api.ToolHandlerParams. Each Resources method calls the hook of
+// the same name and fails if the test did not set it. Calls through the dynamic client of the
+// server go to Dynamic, e.g. a fake dynamic client, which tests of such handlers need to set.
+type ToolHandlerParams struct {
+	context.Context
+	ListOutput ListOutput
+	Arguments  map[string]any
+	Dynamic    dynamic.Interface
+
+	Get            func(ctx context.Context, gvk *schema.GroupVersionKind, namespace, name string) (*unstructured.Unstructured, error)
+	List           func(ctx context.Context, gvk *schema.GroupVersionKind, namespace string, options ResourceListOptions) (runtime.Unstructured, error)
+	CreateOrUpdate func(ctx context.Context, resource string) ([]*unstructured.Unstructured, error)
+	Delete         func(ctx context.Context, gvk *schema.GroupVersionKind, namespace, name string) error
+}
+
+// errNoHook is returned by the Resources methods whose hook is not set
+var errNoHook = errors.New("the stand-in of the MCP server API has no hook for this call")
+
+// GetArguments returns the arguments of the tool call
+func (p ToolHandlerParams) GetArguments() map[string]any {
+	return p.Arguments
+}
+
+// AccessControlClientset returns a clientset whose dynamic client is Dynamic
+func (p ToolHandlerParams) AccessControlClientset() *AccessControlClientset {
+	return &AccessControlClientset{dynamicClient: p.Dynamic}
+}
+
+// NamespaceOrDefault returns namespace, or the namespace default of the kubeconfig the server
+// stands in for if it is empty
+func (p ToolHandlerParams) NamespaceOrDefault(namespace string) string {
+	if namespace == "" {
+		return "default"
+	}
+	return namespace
+}
+
+// ResourcesGet calls the Get hook
+func (p ToolHandlerParams) ResourcesGet(ctx context.Context, gvk *schema.GroupVersionKind, namespace, name string) (*unstructured.Unstructured, error) {
+	if p.Get == nil {
+		return nil, errNoHook
+	}
+	return p.Get(ctx, gvk, namespace, name)
+}
+
+// ResourcesList calls the List hook
+func (p ToolHandlerParams) ResourcesList(ctx context.Context, gvk *schema.GroupVersionKind, namespace string, options ResourceListOptions) (runtime.Unstructured, error) {
+	if p.List == nil {
+		return nil, errNoHook
+	}
+	return p.List(ctx, gvk, namespace, options)
+}
+
+// ResourcesCreateOrUpdate calls the CreateOrUpdate hook
+func (p ToolHandlerParams) ResourcesCreateOrUpdate(ctx context.Context, resource string) ([]*unstructured.Unstructured, error) {
+	if p.CreateOrUpdate == nil {
+		return nil, errNoHook
+	}
+	return p.CreateOrUpdate(ctx, resource)
+}
+
+// ResourcesDelete calls the Delete hook
+func (p ToolHandlerParams) ResourcesDelete(ctx context.Context, gvk *schema.GroupVersionKind, namespace, name string) error {
+	if p.Delete == nil {
+		return errNoHook
+	}
+	return p.Delete(ctx, gvk, namespace, name)
+}